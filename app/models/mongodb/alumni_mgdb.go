@@ -20,5 +20,7 @@ type AlumniMongo struct {
 	NoTelp      string `bson:"no_telepon" json:"no_telepon"`
 	Alamat      string `bson:"alamat" json:"alamat"`
 	TempatKerja string `bson:"tempat_kerja" json:"tempat_kerja"`
+	// Version dinaikkan repository ($inc) setiap update; dokumen lama tanpa field ini = 0
+	Version int `bson:"version,omitempty" json:"version"`
 }
 
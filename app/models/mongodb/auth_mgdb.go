@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	pgModel "go_clean/app/models/postgresql"
 )
 
 type LoginMongo struct {
@@ -12,6 +13,10 @@ type LoginMongo struct {
 	Email     		string             `bson:"email" json:"email"`
 	PasswordHash  	string             `bson:"password_hash" json:"-"` // hash
 	Role      		string             `bson:"role" json:"role"`
+	// AlumniID menunjuk ke tabel alumni (Postgres), sama seperti users.alumni_id
+	AlumniID  		*int               `bson:"alumni_id,omitempty" json:"alumni_id,omitempty"`
+	// Disabled = dinonaktifkan admin, login ditolak
+	Disabled  		bool               `bson:"disabled,omitempty" json:"disabled"`
 	CreatedAt 		time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -19,3 +24,18 @@ type LoginRequest struct {
     Username string `json:"username"`
     Password string `json:"password"`
 }
+
+// Identity membungkus user Mongo menjadi Identity yang seragam dengan user Postgres
+func (u LoginMongo) Identity() pgModel.Identity {
+	return pgModel.Identity{
+		Backend:  pgModel.BackendMongo,
+		ID:       u.ID.Hex(),
+		Username: u.Username,
+		Email:    u.Email,
+		Role:     u.Role,
+		AlumniID: u.AlumniID,
+		// akun Mongo hanya dibuat admin, tidak lewat self-register
+		EmailVerified: true,
+		Disabled:      u.Disabled,
+	}
+}
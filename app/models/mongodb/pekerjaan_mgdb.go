@@ -13,6 +13,8 @@ type PekerjaanMongo struct {
 	CreatedAt   time.Time          			`bson:"created_at" json:"created_at,omitempty" swaggerignore:"true"`
 	UpdatedAt   time.Time          			`bson:"updated_at" json:"updated_at,omitempty" swaggerignore:"true"`
 	
+	// PekerjaanID = id angka yang dipakai /api/pekerjaan (DATA_BACKEND=mongo), sama dengan pekerjaan_alumni.id di Postgres
+	PekerjaanID        int             		`bson:"pekerjaan_id,omitempty" json:"pekerjaan_id,omitempty"`
 	AlumniID           int             	 	`bson:"alumni_id" json:"alumni_id"`
 	NamaPerusahaan     string            	`bson:"nama_perusahaan" json:"nama_perusahaan"`
 	PosisiJabatan      string            	`bson:"posisi_jabatan" json:"posisi_jabatan"`
@@ -26,5 +28,7 @@ type PekerjaanMongo struct {
 	IsDeleted          bool              	`bson:"is_delete" json:"is_delete"`
 	DeletedAt          *time.Time        	`bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 	DeletedBy          string            	`bson:"deleted_by" json:"deleted_by"`
+	// Version dinaikkan repository ($inc) setiap update; dokumen lama tanpa field ini = 0
+	Version            int               	`bson:"version,omitempty" json:"version"`
 }
 
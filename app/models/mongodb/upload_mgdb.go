@@ -14,6 +14,8 @@ type File struct {
     FileSize     int64              `json:"file_size" bson:"file_size"`
     FileType     string             `json:"file_type" bson:"file_type"`
     UploadedAt   time.Time          `json:"uploaded_at" bson:"uploaded_at"`
+    // UploadedBy = subject pengunggah ("<backend>:<id>"), dipakai untuk cek kepemilikan
+    UploadedBy   string             `json:"uploaded_by" bson:"uploaded_by"`
 }
 
 type FileResponse struct {
@@ -24,4 +26,5 @@ type FileResponse struct {
     FileSize     int64     `json:"file_size"`
     FileType     string    `json:"file_type"`
     UploadedAt   time.Time `json:"uploaded_at"`
+    UploadedBy   string    `json:"uploaded_by"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// UpdateMeRequest untuk PATCH /api/me. Email baru baru dipakai setelah link konfirmasi
+// yang dikirim ke alamat tersebut diklik.
+type UpdateMeRequest struct {
+	Email           string `json:"email" example:"alumni.baru@mail.com"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
@@ -2,6 +2,10 @@ package models
 
 import "time"
 
+// AnyVersion = update / delete tanpa syarat version (If-Match kosong atau "*"). Version 0 tetap syarat
+// yang sah: di Mongo artinya dokumen lama yang belum punya field version.
+const AnyVersion = -1
+
 // Alumni merepresentasikan tabel alumni di database
 type Alumni struct {
 	ID         int       `json:"id"`
@@ -15,6 +19,8 @@ type Alumni struct {
 	Alamat     *string   `json:"alamat"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// Version naik setiap update, dikirim sebagai ETag
+	Version int `json:"version"`
 }
 
 type AlumniAngkatan struct {
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// APIKey merepresentasikan tabel api_keys. Key utuh hanya ditampilkan sekali saat dibuat.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP *string    `json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"laporan-kampus"`
+	Scopes []string `json:"scopes" example:"alumni:read,pekerjaan:read"`
+	// ExpiresInDays default 90, maksimal 730
+	ExpiresInDays int `json:"expires_in_days" example:"90"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Key hanya muncul di respons ini
+	Key string `json:"key"`
+}
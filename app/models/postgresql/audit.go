@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditCreate     = "create"
+	AuditUpdate     = "update"
+	AuditDelete     = "delete"
+	AuditSoftDelete = "soft_delete"
+	AuditRestore    = "restore"
+)
+
+// Jenis entitas di audit log. Data Mongo diberi akhiran _mongo karena id-nya berbeda ruang.
+const (
+	AuditEntityAlumni         = "alumni"
+	AuditEntityPekerjaan      = "pekerjaan"
+	AuditEntityAlumniMongo    = "alumni_mongo"
+	AuditEntityPekerjaanMongo = "pekerjaan_mongo"
+	AuditEntityFile           = "file"
+	AuditEntityUser           = "user"
+	AuditEntityUserMongo      = "user_mongo"
+	AuditEntityRole           = "role"
+	AuditEntityAPIKey         = "api_key"
+	AuditEntityOutboxEvent    = "outbox_event"
+	AuditEntityConsistency    = "consistency"
+)
+
+// AuditEntry merepresentasikan tabel audit_logs (append-only). Actor = subject token ("postgres:1",
+// "mongo:<hex>", "apikey:3"), kosong untuk request tanpa login seperti registrasi.
+type AuditEntry struct {
+	ID            int64           `json:"id"`
+	Actor         string          `json:"actor"`
+	ActorUsername string          `json:"actor_username"`
+	Role          string          `json:"role"`
+	IP            string          `json:"ip"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      string          `json:"entity_id"`
+	Before        json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After         json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditFilter dipakai admin API; field kosong / nil = tidak difilter
+type AuditFilter struct {
+	Actor      string
+	EntityType string
+	EntityID   string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -3,11 +3,14 @@ package models
 import "github.com/golang-jwt/jwt/v5"
 
 type User struct {
-	ID       int    `json:"id"`
-	AlumniID *int    `json:"alumni_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	ID            int    `json:"id"`
+	AlumniID      *int   `json:"alumni_id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+	// Disabled = dinonaktifkan admin, login & refresh token ditolak
+	Disabled      bool   `json:"disabled"`
 }
 
 type LoginRequest struct {
@@ -16,13 +19,20 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	User  User   `json:"user"`
-	Token string `json:"token"`
+	User         User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
+// JWTClaims dipakai oleh semua backend. Backend + Subject (sub) menunjukkan asal akun,
+// UserID tetap id asli backend tersebut.
 type JWTClaims struct {
 	UserID   string    `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Backend  string `json:"backend"`
+	FamilyID string `json:"fid,omitempty"`
+	// Unverified = akun self-register yang emailnya belum diverifikasi (ditolak Auth.Required)
+	Unverified bool `json:"unverified,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Jenis perbedaan antara penyimpan sumber & tujuan pada pengecekan konsistensi
+const (
+	// ConsistencyMissing = ada di sumber, tidak ada di tujuan
+	ConsistencyMissing = "missing"
+	// ConsistencyExtra = ada di tujuan, tidak ada di sumber
+	ConsistencyExtra    = "extra"
+	ConsistencyMismatch = "mismatch"
+)
+
+// FieldDiff = satu field yang nilainya berbeda; nilai ditulis sebagai teks supaya bisa diekspor ke CSV
+type FieldDiff struct {
+	Field  string `json:"field"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// ConsistencyDiff = satu baris alumni / pekerjaan yang berbeda. Action = perbaikan yang dilakukan
+// (atau akan dilakukan saat dry-run) di penyimpan tujuan: upsert atau delete.
+type ConsistencyDiff struct {
+	Entity   string      `json:"entity"`
+	ID       int         `json:"id"`
+	NIM      string      `json:"nim,omitempty"`
+	Kind     string      `json:"kind"`
+	Fields   []FieldDiff `json:"fields,omitempty"`
+	Action   string      `json:"action"`
+	Repaired bool        `json:"repaired"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type ConsistencyCounts struct {
+	SourceAlumni    int `json:"source_alumni"`
+	TargetAlumni    int `json:"target_alumni"`
+	SourcePekerjaan int `json:"source_pekerjaan"`
+	TargetPekerjaan int `json:"target_pekerjaan"`
+	Missing         int `json:"missing"`
+	Extra           int `json:"extra"`
+	Mismatched      int `json:"mismatched"`
+	Repaired        int `json:"repaired"`
+	Failed          int `json:"failed"`
+}
+
+// ConsistencyReport = hasil membandingkan data alumni & pekerjaan di Postgres dan MongoDB
+type ConsistencyReport struct {
+	Source    string            `json:"source"`
+	Target    string            `json:"target"`
+	Repair    bool              `json:"repair"`
+	DryRun    bool              `json:"dry_run"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Counts    ConsistencyCounts `json:"counts"`
+	Diffs     []ConsistencyDiff `json:"diffs"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheckResult = hasil satu pengecekan dependency. Error hanya diisi di /api/admin/diagnostics,
+// /readyz publik tidak menampilkan pesan error (bisa berisi host / nama database).
+type HealthCheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessReport = respons /readyz, Status fail (HTTP 503) kalau satu saja pengecekan gagal
+type ReadinessReport struct {
+	Status    string              `json:"status"`
+	CheckedAt time.Time           `json:"checked_at"`
+	Checks    []HealthCheckResult `json:"checks"`
+}
+
+// LivenessReport = respons /healthz, tidak mengecek dependency
+type LivenessReport struct {
+	Status        string  `json:"status"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+// DiagnosticsReport = /api/admin/diagnostics: readiness lengkap dengan pesan error + statistik pool koneksi
+type DiagnosticsReport struct {
+	ReadinessReport
+	UptimeSeconds float64                `json:"uptime_seconds"`
+	Goroutines    int                    `json:"goroutines"`
+	Pools         map[string]interface{} `json:"pools"`
+}
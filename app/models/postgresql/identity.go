@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Backend penyimpan akun user
+const (
+	BackendPostgres = "postgres"
+	BackendMongo    = "mongo"
+)
+
+// Identity adalah bentuk akun yang seragam untuk users (Postgres) maupun users (Mongo).
+// ID tetap dalam format aslinya (int sebagai string atau ObjectID hex), Subject yang dipakai sebagai kunci stabil.
+type Identity struct {
+	Backend  string `json:"backend"`
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	AlumniID *int   `json:"alumni_id"`
+	// EmailVerified false hanya untuk akun self-register yang belum klik link verifikasi
+	EmailVerified bool `json:"email_verified"`
+	// Disabled: akun Postgres dinonaktifkan lewat /api/admin/users, akun Mongo lewat field disabled
+	Disabled bool `json:"disabled"`
+}
+
+// AccountState = status akun terkini yang dibaca setiap kali token diterbitkan (login, 2FA, refresh).
+// Known false untuk akun yang tidak dikelola di Postgres (Mongo): role & verifikasi tetap dari token lama.
+type AccountState struct {
+	Known         bool
+	Disabled      bool
+	Role          string
+	EmailVerified bool
+}
+
+// Subject berbentuk "<backend>:<id>", misal "postgres:12" atau "mongo:65f0c...".
+func (i Identity) Subject() string {
+	return i.Backend + ":" + i.ID
+}
+
+// PostgresIdentity membungkus user Postgres menjadi Identity
+func PostgresIdentity(u User) Identity {
+	return Identity{
+		Backend:  BackendPostgres,
+		ID:       strconv.Itoa(u.ID),
+		Username: u.Username,
+		Email:    u.Email,
+		Role:     u.Role,
+		AlumniID: u.AlumniID,
+
+		EmailVerified: u.EmailVerified,
+		Disabled:      u.Disabled,
+	}
+}
+
+var ErrNotPostgresUser = errors.New("akun bukan user Postgres")
+
+// ErrAccountDisabled: akun dinonaktifkan admin, token tidak boleh diterbitkan
+var ErrAccountDisabled = errors.New("akun dinonaktifkan")
+
+// PostgresUserID mengembalikan id int users Postgres; error kalau token milik backend lain
+func (c *JWTClaims) PostgresUserID() (int, error) {
+	if c.Backend != BackendPostgres {
+		return 0, ErrNotPostgresUser
+	}
+	return strconv.Atoi(c.UserID)
+}
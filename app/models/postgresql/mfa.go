@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// UserMFA merepresentasikan tabel user_mfa. Secret masih terenkripsi (lihat utils.DecryptString).
+type UserMFA struct {
+	Backend      string     `json:"backend"`
+	UserID       string     `json:"user_id"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// MFAChallenge dikembalikan login (pengganti token) kalau akun wajib memasukkan kode 2FA.
+// EnrollmentRequired = admin belum punya 2FA padahal kebijakan mewajibkan, harus enroll dulu.
+type MFAChallenge struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	// Code berisi kode TOTP 6 digit atau salah satu recovery code
+	Code string `json:"code" example:"123456"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// MFAEnrollment berisi secret untuk dimasukkan ke authenticator (manual atau lewat QR dari OTPAuthURI)
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// OIDCLoginState menyimpan state, nonce & PKCE verifier selama user login di IdP (sekali pakai)
+type OIDCLoginState struct {
+	StateHash string
+	Nonce     string
+	Verifier  string
+	ExpiresAt time.Time
+}
+
+// OIDCLink menghubungkan akun IdP (issuer + sub) dengan akun lokal
+type OIDCLink struct {
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Backend     string     `json:"backend"`
+	UserID      string     `json:"user_id"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
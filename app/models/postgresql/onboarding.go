@@ -0,0 +1,25 @@
+package models
+
+// OnboardingRequest dipakai POST /api/admin/onboarding: alumni baru, pekerjaan saat ini (opsional)
+// dan akun login yang langsung ditautkan ke alumni tersebut
+type OnboardingRequest struct {
+	Alumni Alumni `json:"alumni"`
+	// alumni_id pekerjaan diisi otomatis
+	Pekerjaan *PekerjaanAlumni `json:"pekerjaan,omitempty"`
+	User      OnboardingUser   `json:"user"`
+}
+
+// OnboardingUser: email kosong = email alumni, role kosong = alumni
+type OnboardingUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type OnboardingResponse struct {
+	Message   string           `json:"message"`
+	Alumni    *Alumni          `json:"alumni"`
+	Pekerjaan *PekerjaanAlumni `json:"pekerjaan,omitempty"`
+	User      *User            `json:"user"`
+}
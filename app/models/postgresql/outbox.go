@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Aggregate & operasi event outbox (tabel outbox_events)
+const (
+	OutboxAggregateAlumni    = "alumni"
+	OutboxAggregatePekerjaan = "pekerjaan"
+
+	// OutboxOpUpsert membawa isi baris terbaru, OutboxOpDelete hanya id
+	OutboxOpUpsert = "upsert"
+	OutboxOpDelete = "delete"
+)
+
+// Status event: pending menunggu relay, done sudah tersalin ke Mongo, dead gagal melewati batas percobaan
+const (
+	OutboxPending = "pending"
+	OutboxDone    = "done"
+	OutboxDead    = "dead"
+)
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	Aggregate     string          `json:"aggregate"`
+	AggregateID   int             `json:"aggregate_id"`
+	Op            string          `json:"op"`
+	Payload       json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	ProcessedAt   *time.Time      `json:"processed_at,omitempty"`
+}
+
+// Key mengelompokkan event per baris; event satu baris harus disalin berurutan
+func (e OutboxEvent) Key() string {
+	return e.Aggregate + ":" + strconv.Itoa(e.AggregateID)
+}
+
+// ReplicationStatus = kondisi replikasi Postgres → Mongo untuk /api/admin/replication
+type ReplicationStatus struct {
+	Pending int `json:"pending"`
+	Dead    int `json:"dead"`
+	// LagSeconds = umur event pending tertua (0 kalau tidak ada yang menunggu)
+	LagSeconds      float64    `json:"lag_seconds"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	LastRelayedAt   *time.Time `json:"last_relayed_at,omitempty"`
+	RelayRunning    bool       `json:"relay_running"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PasswordResetToken merepresentasikan tabel password_reset_tokens.
+// Token asli hanya dikirim lewat email, di DB cuma hash-nya.
+type PasswordResetToken struct {
+	ID        int        `json:"id"`
+	Backend   string     `json:"backend"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"alumni@mail.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
@@ -20,5 +20,7 @@ type PekerjaanAlumni struct {
 	IsDeleted          bool       `json:"is_delete"`
 	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
 	DeletedBy string      `json:"deleted_by"`
+	// Version naik setiap update / soft delete / restore, dikirim sebagai ETag
+	Version int `json:"version"`
 }
 
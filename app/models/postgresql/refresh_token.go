@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// RefreshToken merepresentasikan tabel refresh_tokens.
+// Satu login = satu family; setiap refresh menghasilkan token baru di family yang sama.
+type RefreshToken struct {
+	ID        int        `json:"id"`
+	Backend   string     `json:"backend"`
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	Role      string     `json:"role"`
+	Verified  bool       `json:"email_verified"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Permission yang dicek middleware.Require. Tambah konstanta di sini + di AllPermissions
+// kalau ada fitur baru; pemetaan role → permission disimpan di tabel role_permissions.
+const (
+	PermAll = "*"
+
+	PermAlumniRead  = "alumni:read"
+	PermAlumniWrite = "alumni:write"
+
+	PermPekerjaanRead    = "pekerjaan:read"
+	PermPekerjaanReadAll = "pekerjaan:read_all"
+	// PermPekerjaanWrite boleh mengubah pekerjaan siapa saja, WriteOwn hanya milik alumni user sendiri
+	PermPekerjaanWrite      = "pekerjaan:write"
+	PermPekerjaanWriteOwn   = "pekerjaan:write_own"
+	PermPekerjaanHardDelete = "pekerjaan:hard_delete"
+
+	PermFilesUpload    = "files:upload"
+	PermFilesReadAll   = "files:read_all"
+	PermFilesDeleteAll = "files:delete_all"
+
+	PermUsersManage    = "users:manage"
+	PermRolesManage    = "roles:manage"
+	PermSecurityManage = "security:manage"
+	PermAPIKeysManage  = "apikeys:manage"
+	PermAuditRead      = "audit:read"
+	// PermReplicationManage: lihat status replikasi Postgres → Mongo & ulangi event dead-letter
+	PermReplicationManage = "replication:manage"
+	// PermDiagnosticsRead: lihat hasil cek dependency lengkap & statistik pool koneksi
+	PermDiagnosticsRead = "diagnostics:read"
+)
+
+// Role bawaan. RoleLegacyUser adalah nama lama role alumni yang masih ada di token / dokumen Mongo.
+const (
+	RoleAdmin         = "admin"
+	RoleOperatorProdi = "operator_prodi"
+	RoleAlumni        = "alumni"
+	RoleViewer        = "viewer"
+	RoleLegacyUser    = "user"
+)
+
+// AllPermissions dipakai untuk validasi input admin API
+var AllPermissions = map[string]string{
+	PermAll:                 "Semua permission (termasuk yang ditambahkan kemudian)",
+	PermAlumniRead:          "Lihat data alumni",
+	PermAlumniWrite:         "Tambah, ubah, hapus data alumni",
+	PermPekerjaanRead:       "Lihat data pekerjaan",
+	PermPekerjaanReadAll:    "Lihat trash & pekerjaan per alumni milik siapa saja",
+	PermPekerjaanWrite:      "Tambah, ubah, hapus, restore pekerjaan siapa saja",
+	PermPekerjaanWriteOwn:   "Ubah, hapus, restore pekerjaan milik sendiri",
+	PermPekerjaanHardDelete: "Hapus permanen pekerjaan",
+	PermFilesUpload:         "Upload file",
+	PermFilesReadAll:        "Lihat file milik siapa saja",
+	PermFilesDeleteAll:      "Hapus file milik siapa saja",
+	PermUsersManage:         "Kelola akun user",
+	PermRolesManage:         "Kelola role & permission",
+	PermSecurityManage:      "Lihat & buka lockout login",
+	PermAPIKeysManage:       "Kelola API key service-to-service",
+	PermAuditRead:           "Lihat audit log perubahan data",
+	PermReplicationManage:   "Lihat status replikasi ke MongoDB & ulangi event yang gagal",
+	PermDiagnosticsRead:     "Lihat diagnosa dependency (Postgres, MongoDB, folder upload, migration) & pool koneksi",
+}
+
+// DefaultRolePermissions sama dengan seed di database/migrations/0001_init.up.sql; dipakai kalau
+// PermissionStore belum dipasang (test) atau DB tidak bisa dibaca.
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: {PermAll},
+	RoleOperatorProdi: {
+		PermAlumniRead, PermAlumniWrite,
+		PermPekerjaanRead, PermPekerjaanReadAll, PermPekerjaanWrite,
+		PermFilesUpload, PermFilesReadAll,
+	},
+	RoleAlumni: {PermAlumniRead, PermPekerjaanRead, PermPekerjaanWriteOwn, PermFilesUpload},
+	RoleViewer: {PermAlumniRead, PermPekerjaanRead},
+}
+
+// SystemRoleDescriptions = deskripsi role bawaan, dipakai seed CLI (cmd/admin seed)
+var SystemRoleDescriptions = map[string]string{
+	RoleAdmin:         "Administrator, semua akses",
+	RoleOperatorProdi: "Operator program studi",
+	RoleAlumni:        "Alumni, kelola data pekerjaan sendiri",
+	RoleViewer:        "Hanya baca data alumni & pekerjaan",
+}
+
+// NormalizeRole memetakan nama role lama ke nama baru
+func NormalizeRole(role string) string {
+	if role == RoleLegacyUser {
+		return RoleAlumni
+	}
+	return role
+}
+
+// Role merepresentasikan tabel roles + role_permissions
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	System      bool      `json:"system"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name" example:"operator_prodi"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" example:"alumni:read,alumni:write"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Session merepresentasikan tabel sessions. ID sama dengan family_id refresh token.
+type Session struct {
+	ID         string     `json:"id"`
+	Backend    string     `json:"backend"`
+	UserID     string     `json:"user_id"`
+	Username   string     `json:"username"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// Current = sesi milik access token yang dipakai request ini
+	Current bool `json:"current"`
+}
+
+// SessionMeta adalah info perangkat yang dicatat saat login / refresh
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
@@ -16,5 +16,15 @@ type AdminCreateUserRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
-	Role     string `json:"role"` // admin/user
+	Role     string `json:"role"` // nama role di tabel roles
+}
+
+// UpdateUserRoleRequest dipakai PUT /api/admin/users/:id/role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// LinkAlumniRequest dipakai PUT /api/admin/users/:id/alumni
+type LinkAlumniRequest struct {
+	AlumniID int `json:"alumni_id"`
 }
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AlumniMongoDriver menyimpan data /api/alumni di koleksi alumni MongoDB (DATA_BACKEND=mongo).
+// Memenuhi AlumniRepositoryInterface Postgres: id = field alumni_id, data tidak ditemukan
+// dikembalikan sebagai sql.ErrNoRows supaya service tidak perlu tahu backend-nya.
+type AlumniMongoDriver struct {
+	collection *mongo.Collection
+	pekerjaan  *mongo.Collection
+}
+
+func NewAlumniMongoDriver(db *mongo.Database) *AlumniMongoDriver {
+	return &AlumniMongoDriver{
+		collection: db.Collection("alumni"),
+		pekerjaan:  db.Collection("pekerjaan"),
+	}
+}
+
+// hasID: dokumen tanpa id angka (dibuat lewat endpoint -mongo lama) tidak punya id di API ini, jadi tidak ikut ditampilkan
+var hasID = bson.M{"$gt": 0}
+
+// byID mencari dokumen lewat id angka; id <= 0 tidak pernah cocok dengan dokumen lama yang id-nya kosong
+func byID(field string, id int) bson.M {
+	if id <= 0 {
+		return bson.M{field: bson.M{"$in": bson.A{}}}
+	}
+	return bson.M{field: id}
+}
+
+// withVersion menambah syarat version seperti "($n < 0 OR version = $n)" di Postgres. Dokumen lama tanpa
+// field version terbaca (dan dikirim sebagai ETag) versi 0, jadi If-Match "0" hanya cocok dengan dokumen itu.
+func withVersion(filter bson.M, version int) bson.M {
+	switch {
+	case version == pgModel.AnyVersion:
+	case version == 0:
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	default:
+		filter["version"] = version
+	}
+	return filter
+}
+
+func alumniFromMongo(m models.AlumniMongo) pgModel.Alumni {
+	return pgModel.Alumni{
+		ID:         m.AlumniID,
+		NIM:        m.NIM,
+		Nama:       m.Nama,
+		Jurusan:    m.Jurusan,
+		Angkatan:   m.Angkatan,
+		TahunLulus: m.TahunLulus,
+		Email:      m.Email,
+		NoTelepon:  optionalString(m.NoTelp),
+		Alamat:     optionalString(m.Alamat),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		Version:    m.Version,
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// notFound menyamakan error "tidak ada" Mongo dengan database/sql
+func notFound(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func (r *AlumniMongoDriver) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]pgModel.Alumni, error) {
+	cur, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []models.AlumniMongo
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	list := make([]pgModel.Alumni, 0, len(docs))
+	for _, d := range docs {
+		list = append(list, alumniFromMongo(d))
+	}
+	return list, nil
+}
+
+func (r *AlumniMongoDriver) GetAllAlumni(ctx context.Context) ([]pgModel.Alumni, error) {
+	return r.find(ctx, bson.M{"alumni_id": hasID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+// GetAlumniAndPekerjaan sama dengan JOIN di Postgres: alumni tanpa pekerjaan dianggap tidak ditemukan
+func (r *AlumniMongoDriver) GetAlumniAndPekerjaan(ctx context.Context, id int) (*pgModel.AlumniPekerjaan, error) {
+	a, err := r.GetAlumniByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var p models.PekerjaanMongo
+	err = r.pekerjaan.FindOne(ctx, bson.M{"alumni_id": id},
+		options.FindOne().SetSort(bson.D{{Key: "pekerjaan_id", Value: 1}}),
+	).Decode(&p)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	res := &pgModel.AlumniPekerjaan{
+		ID: a.ID, NIM: a.NIM, Nama: a.Nama, Jurusan: a.Jurusan, Angkatan: a.Angkatan,
+		TahunLulus: a.TahunLulus, Email: a.Email, NamaPerusahaan: p.NamaPerusahaan, Posisi: p.PosisiJabatan,
+	}
+	if p.TanggalMulaiKerja != nil {
+		res.TahunMulai = p.TanggalMulaiKerja.Year()
+	}
+	if p.TanggalSelesaiKerja != nil {
+		res.TahunSelesai = p.TanggalSelesaiKerja.Year()
+	}
+	return res, nil
+}
+
+func (r *AlumniMongoDriver) GetAlumniByID(ctx context.Context, id int) (*pgModel.Alumni, error) {
+	var doc models.AlumniMongo
+	if err := r.collection.FindOne(ctx, byID("alumni_id", id)).Decode(&doc); err != nil {
+		return nil, notFound(err)
+	}
+	a := alumniFromMongo(doc)
+	return &a, nil
+}
+
+func (r *AlumniMongoDriver) GetAlumniByAngkatan(ctx context.Context, angkatan int) (*pgModel.AlumniAngkatan, error) {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"alumni_id": hasID, "angkatan": angkatan})
+	if err != nil {
+		return nil, err
+	}
+	return &pgModel.AlumniAngkatan{Angkatan: angkatan, Jumlah: int(n)}, nil
+}
+
+func (r *AlumniMongoDriver) CreateAlumni(ctx context.Context, alumni *pgModel.Alumni) (int, error) {
+	id, err := nextSequence(ctx, r.collection, "alumni_id")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	_, err = r.collection.InsertOne(ctx, models.AlumniMongo{
+		AlumniID:   id,
+		NIM:        alumni.NIM,
+		Nama:       alumni.Nama,
+		Jurusan:    alumni.Jurusan,
+		Angkatan:   alumni.Angkatan,
+		TahunLulus: alumni.TahunLulus,
+		Email:      alumni.Email,
+		NoTelp:     derefString(alumni.NoTelepon),
+		Alamat:     derefString(alumni.Alamat),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Version:    1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateAlumni hanya mengubah kolom yang ada di Postgres; tempat_kerja milik /api/alumni-mongo tidak disentuh
+func (r *AlumniMongoDriver) UpdateAlumni(ctx context.Context, id int, alumni *pgModel.Alumni, version int) (int64, error) {
+	res, err := r.collection.UpdateOne(ctx, withVersion(byID("alumni_id", id), version), bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{
+		"nama":        alumni.Nama,
+		"jurusan":     alumni.Jurusan,
+		"angkatan":    alumni.Angkatan,
+		"tahun_lulus": alumni.TahunLulus,
+		"email":       alumni.Email,
+		"no_telepon":  derefString(alumni.NoTelepon),
+		"alamat":      derefString(alumni.Alamat),
+		"updated_at":  time.Now(),
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+// DeleteAlumni ikut menghapus pekerjaan alumni tersebut, sama dengan ON DELETE CASCADE di Postgres.
+// Pekerjaan baru dihapus setelah alumninya terhapus, jadi If-Match yang basi tidak menghapus apa pun.
+func (r *AlumniMongoDriver) DeleteAlumni(ctx context.Context, id int, version int) (int64, error) {
+	res, err := r.collection.DeleteOne(ctx, withVersion(byID("alumni_id", id), version))
+	if err != nil || res.DeletedCount == 0 {
+		return 0, err
+	}
+	if _, err := r.pekerjaan.DeleteMany(ctx, byID("alumni_id", id)); err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// searchFilter meniru "kolom ILIKE '%search%'" untuk beberapa field
+func searchFilter(search string, fields ...string) bson.M {
+	filter := bson.M{}
+	if search == "" {
+		return filter
+	}
+	pattern := bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}
+	or := bson.A{}
+	for _, f := range fields {
+		or = append(or, bson.M{f: pattern})
+	}
+	filter["$or"] = or
+	return filter
+}
+
+// sortOrder meniru "ORDER BY <field> <order>, id ASC"; field sudah lolos whitelist
+func sortOrder(field, order, idField string) bson.D {
+	dir := 1
+	if order == "desc" || order == "DESC" {
+		dir = -1
+	}
+	if field == idField {
+		return bson.D{{Key: idField, Value: dir}}
+	}
+	return bson.D{{Key: field, Value: dir}, {Key: idField, Value: 1}}
+}
+
+func (r *AlumniMongoDriver) listFilter(search string) bson.M {
+	filter := searchFilter(search, "nama", "nim")
+	filter["alumni_id"] = hasID
+	return filter
+}
+
+func (r *AlumniMongoDriver) ListAlumniRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]pgModel.Alumni, error) {
+	field := sanitizeAlumniSort(sortBy)
+	opts := options.Find().
+		SetSort(sortOrder(field, order, "alumni_id")).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	return r.find(ctx, r.listFilter(search), opts)
+}
+
+func (r *AlumniMongoDriver) CountAlumniRepo(ctx context.Context, search string) (int, error) {
+	n, err := r.collection.CountDocuments(ctx, r.listFilter(search))
+	return int(n), err
+}
+
+// sanitizeAlumniSort = whitelist kolom Postgres, "id" dipetakan ke alumni_id
+func sanitizeAlumniSort(s string) string {
+	switch s {
+	case "nim", "nama", "jurusan", "angkatan", "email", "created_at", "updated_at":
+		return s
+	default:
+		return "alumni_id"
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// deleted = balasan server untuk perintah delete yang menghapus n dokumen
+func deleted(n int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+}
+
+func TestDeleteAlumniCascadesPekerjaan(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("alumni terhapus", func(mt *mtest.T) {
+		r := NewAlumniMongoDriver(mt.DB)
+		mt.AddMockResponses(deleted(1), deleted(2))
+
+		n, err := r.DeleteAlumni(context.Background(), 7, 3)
+		if err != nil || n != 1 {
+			t.Fatalf("DeleteAlumni = %d, %v", n, err)
+		}
+		alumni, jobs := mt.GetStartedEvent(), mt.GetStartedEvent()
+		if alumni.CommandName != "delete" || alumni.Command.Lookup("delete").StringValue() != "alumni" {
+			t.Fatalf("perintah pertama = %v", alumni.Command)
+		}
+		if jobs == nil || jobs.Command.Lookup("delete").StringValue() != "pekerjaan" {
+			t.Fatalf("pekerjaan tidak ikut dihapus: %v", jobs)
+		}
+		q := jobs.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+		if id, ok := q.Lookup("alumni_id").AsInt64OK(); !ok || id != 7 {
+			t.Fatalf("filter pekerjaan = %v", q)
+		}
+	})
+
+	mt.Run("version basi tidak menghapus pekerjaan", func(mt *mtest.T) {
+		r := NewAlumniMongoDriver(mt.DB)
+		mt.AddMockResponses(deleted(0))
+
+		n, err := r.DeleteAlumni(context.Background(), 7, 2)
+		if err != nil || n != 0 {
+			t.Fatalf("DeleteAlumni = %d, %v", n, err)
+		}
+		mt.GetStartedEvent()
+		if e := mt.GetStartedEvent(); e != nil {
+			t.Fatalf("perintah tambahan setelah alumni tidak terhapus: %v", e.Command)
+		}
+	})
+}
+
+func TestDeleteAlumniVersionZeroMatchesOnlyUnversioned(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("If-Match 0", func(mt *mtest.T) {
+		r := NewAlumniMongoDriver(mt.DB)
+		mt.AddMockResponses(deleted(0))
+
+		if _, err := r.DeleteAlumni(context.Background(), 7, 0); err != nil {
+			t.Fatal(err)
+		}
+		q := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+		version, err := q.LookupErr("version", "$in")
+		if err != nil {
+			t.Fatalf("version 0 harus jadi syarat, filter = %v", q)
+		}
+		if values, _ := version.Array().Values(); len(values) != 2 {
+			t.Fatalf("version $in = %v, want [0, null]", version)
+		}
+	})
+}
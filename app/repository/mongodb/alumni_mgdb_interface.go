@@ -9,6 +9,7 @@ type AlumniMongoRepositoryInterface interface {
 	Create(ctx context.Context, data *models.AlumniMongo) (*models.AlumniMongo, error)
 	FindAll(ctx context.Context) ([]models.AlumniMongo, error)
 	FindByID(ctx context.Context, id string) (*models.AlumniMongo, error)
-	Update(ctx context.Context, id string, data *models.AlumniMongo) (*models.AlumniMongo, error)
-	Delete(ctx context.Context, id string) error
+	// Update & Delete: version 0 = tanpa syarat; tidak ada dokumen yang cocok = ErrNotMatched
+	Update(ctx context.Context, id string, data *models.AlumniMongo, version int) (*models.AlumniMongo, error)
+	Delete(ctx context.Context, id string, version int) error
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go_clean/app/models/mongodb"
 
@@ -11,6 +12,10 @@ import (
 	// "go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrNotMatched = Update / Delete tidak mengenai dokumen: id tidak ada atau version sudah berubah.
+// Pemanggil membaca ulang dokumen untuk membedakan 404 dan 412.
+var ErrNotMatched = errors.New("data tidak ditemukan atau sudah diubah pengguna lain")
+
 type AlumniMongoRepository struct {
 	collection *mongo.Collection
 }
@@ -23,6 +28,7 @@ func NewAlumniMongoRepository(db *mongo.Database) *AlumniMongoRepository {
 
 // Create
 func (r *AlumniMongoRepository) Create(ctx context.Context, data *models.AlumniMongo) (*models.AlumniMongo, error) {
+	data.Version = 1
 	result, err := r.collection.InsertOne(ctx, data)
 	if err != nil {
 		return nil, err
@@ -67,8 +73,8 @@ func (r *AlumniMongoRepository) FindByID(ctx context.Context, id string) (*model
 	return &result, nil
 }
 
-// Update
-func (r *AlumniMongoRepository) Update(ctx context.Context, id string, data *models.AlumniMongo) (*models.AlumniMongo, error) {
+// Update: version dinaikkan lewat $inc, bukan dari body (field version kosong tidak ikut $set)
+func (r *AlumniMongoRepository) Update(ctx context.Context, id string, data *models.AlumniMongo, version int) (*models.AlumniMongo, error) {
 	filter := bson.M{}
 
 	if objID, err := primitive.ObjectIDFromHex(id); err == nil {
@@ -77,16 +83,20 @@ func (r *AlumniMongoRepository) Update(ctx context.Context, id string, data *mod
 		filter = bson.M{"alumni_id": id}
 	}
 
-	update := bson.M{"$set": data}
-	_, err := r.collection.UpdateOne(ctx, filter, update)
+	data.Version = 0
+	update := bson.M{"$set": data, "$inc": bson.M{"version": 1}}
+	res, err := r.collection.UpdateOne(ctx, withVersion(filter, version), update)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	if res.MatchedCount == 0 {
+		return nil, ErrNotMatched
+	}
+	return r.FindByID(ctx, id)
 }
 
 // Delete
-func (r *AlumniMongoRepository) Delete(ctx context.Context, id string) error {
+func (r *AlumniMongoRepository) Delete(ctx context.Context, id string, version int) error {
 	filter := bson.M{}
 
 	if objID, err := primitive.ObjectIDFromHex(id); err == nil {
@@ -95,7 +105,13 @@ func (r *AlumniMongoRepository) Delete(ctx context.Context, id string) error {
 		filter = bson.M{"alumni_id": id}
 	}
 
-	_, err := r.collection.DeleteOne(ctx, filter)
-	return err
+	res, err := r.collection.DeleteOne(ctx, withVersion(filter, version))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotMatched
+	}
+	return nil
 }
 
@@ -1,7 +1,11 @@
 package repository
 
-import "go_clean/app/models/mongodb"
+import (
+	"context"
+
+	"go_clean/app/models/mongodb"
+)
 
 type AuthRepositoryInterface interface {
-	FindByUsernameOrEmail(identifier string) (*models.LoginMongo, error)
+	FindByUsernameOrEmail(ctx context.Context, identifier string) (*models.LoginMongo, error)
 }
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -18,10 +21,10 @@ func NewUserMongoRepository(db *mongo.Database) *UserMongoRepository {
 	}
 }
 
-func (r *UserMongoRepository) FindByUsernameOrEmail(identifier string) (*models.LoginMongo, error) {
+func (r *UserMongoRepository) FindByUsernameOrEmail(ctx context.Context, identifier string) (*models.LoginMongo, error) {
 	var user models.LoginMongo
 	err := r.Col.FindOne(
-		context.TODO(),
+		ctx,
 		bson.M{
 			"$or": []bson.M{
 				{"username": identifier},
@@ -36,10 +39,101 @@ func (r *UserMongoRepository) FindByUsernameOrEmail(identifier string) (*models.
 	return &user, nil
 }
 
-func (r *UserMongoRepository) CreateUser(u *models.LoginMongo) (*models.LoginMongo, error) {
-	_, err := r.Col.InsertOne(context.TODO(), u)
+func (r *UserMongoRepository) CreateUser(ctx context.Context, u *models.LoginMongo) (*models.LoginMongo, error) {
+	_, err := r.Col.InsertOne(ctx, u)
 	if err != nil {
 		return nil, err
 	}
 	return u, nil
 }
+
+func (r *UserMongoRepository) Backend() string {
+	return pgModel.BackendMongo
+}
+
+// FindIdentity dipakai identity store gabungan (/api/login)
+func (r *UserMongoRepository) FindIdentity(ctx context.Context, identifier string) (*pgModel.Identity, string, error) {
+	u, err := r.FindByUsernameOrEmail(ctx, identifier)
+	if err != nil {
+		return nil, "", err
+	}
+	id := u.Identity()
+	return &id, u.PasswordHash, nil
+}
+
+// FindIdentityByID dipakai login SSO untuk akun yang sudah tertaut ke IdP
+func (r *UserMongoRepository) FindIdentityByID(ctx context.Context, userID string) (*pgModel.Identity, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	var user models.LoginMongo
+	if err := r.Col.FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		return nil, err
+	}
+	id := user.Identity()
+	return &id, nil
+}
+
+// CreateIdentity dipakai auto-provisioning SSO
+func (r *UserMongoRepository) CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*pgModel.Identity, error) {
+	u := &models.LoginMongo{
+		ID:           primitive.NewObjectID(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    time.Now(),
+	}
+	if _, err := r.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	id := u.Identity()
+	return &id, nil
+}
+
+func (r *UserMongoRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	res, err := r.Col.UpdateByID(ctx, objID, bson.M{"$set": bson.M{"password_hash": passwordHash}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *UserMongoRepository) UpdateEmail(ctx context.Context, userID, email string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	res, err := r.Col.UpdateByID(ctx, objID, bson.M{"$set": bson.M{"email": email}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateRole dipakai CLI admin; nama role divalidasi terhadap tabel roles Postgres oleh pemanggil
+func (r *UserMongoRepository) UpdateRole(ctx context.Context, userID, role string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	res, err := r.Col.UpdateByID(ctx, objID, bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -23,6 +24,7 @@ func (m *MockAlumniMongoRepository) Create(ctx context.Context, data *models.Alu
 	}
 
 	data.ID = primitive.NewObjectID()
+	data.Version = 1
 	m.Data[data.ID.Hex()] = data
 	return data, nil
 }
@@ -42,17 +44,19 @@ func (m *MockAlumniMongoRepository) FindByID(ctx context.Context, id string) (*m
 	return nil, errors.New("data tidak ditemukan")
 }
 
-func (m *MockAlumniMongoRepository) Update(ctx context.Context, id string, data *models.AlumniMongo) (*models.AlumniMongo, error) {
-	if _, ok := m.Data[id]; !ok {
-		return nil, errors.New("data tidak ditemukan")
+func (m *MockAlumniMongoRepository) Update(ctx context.Context, id string, data *models.AlumniMongo, version int) (*models.AlumniMongo, error) {
+	cur, ok := m.Data[id]
+	if !ok || (version != pgModel.AnyVersion && cur.Version != version) {
+		return nil, ErrNotMatched
 	}
+	data.Version = cur.Version + 1
 	m.Data[id] = data
 	return data, nil
 }
 
-func (m *MockAlumniMongoRepository) Delete(ctx context.Context, id string) error {
-	if _, ok := m.Data[id]; !ok {
-		return errors.New("data tidak ditemukan")
+func (m *MockAlumniMongoRepository) Delete(ctx context.Context, id string, version int) error {
+	if cur, ok := m.Data[id]; !ok || (version != pgModel.AnyVersion && cur.Version != version) {
+		return ErrNotMatched
 	}
 	delete(m.Data, id)
 	return nil
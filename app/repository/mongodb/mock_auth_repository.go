@@ -1,6 +1,7 @@
 package repository
 
 import (
+    "context"
     "errors"
     "go_clean/app/models/mongodb"
 )
@@ -15,7 +16,7 @@ func NewMockUserMongoRepository() *MockUserMongoRepository {
     }
 }
 
-func (m *MockUserMongoRepository) FindByUsernameOrEmail(identifier string) (*models.LoginMongo, error) {
+func (m *MockUserMongoRepository) FindByUsernameOrEmail(ctx context.Context, identifier string) (*models.LoginMongo, error) {
     user, ok := m.Users[identifier]
     if !ok {
         return nil, errors.New("not found")
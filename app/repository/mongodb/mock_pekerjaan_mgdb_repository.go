@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -22,6 +23,7 @@ func (m *MockPekerjaanMongoRepository) Create(ctx context.Context, p *models.Pek
 		return nil, errors.New("nama pekerjaan tidak boleh kosong")
 	}
 	p.ID = primitive.NewObjectID()
+	p.Version = 1
 	m.Data[p.ID.Hex()] = p
 	return p, nil
 }
@@ -51,17 +53,19 @@ func (m *MockPekerjaanMongoRepository) FindByAlumniID(ctx context.Context, alumn
 	return list, nil
 }
 
-func (m *MockPekerjaanMongoRepository) Update(ctx context.Context, id string, p *models.PekerjaanMongo) (*models.PekerjaanMongo, error) {
-	if _, ok := m.Data[id]; !ok {
-		return nil, errors.New("data tidak ditemukan")
+func (m *MockPekerjaanMongoRepository) Update(ctx context.Context, id string, p *models.PekerjaanMongo, version int) (*models.PekerjaanMongo, error) {
+	cur, ok := m.Data[id]
+	if !ok || (version != pgModel.AnyVersion && cur.Version != version) {
+		return nil, ErrNotMatched
 	}
+	p.Version = cur.Version + 1
 	m.Data[id] = p
 	return p, nil
 }
 
-func (m *MockPekerjaanMongoRepository) Delete(ctx context.Context, id string) error {
-	if _, ok := m.Data[id]; !ok {
-		return errors.New("data tidak ditemukan")
+func (m *MockPekerjaanMongoRepository) Delete(ctx context.Context, id string, version int) error {
+	if cur, ok := m.Data[id]; !ok || (version != pgModel.AnyVersion && cur.Version != version) {
+		return ErrNotMatched
 	}
 	delete(m.Data, id)
 	return nil
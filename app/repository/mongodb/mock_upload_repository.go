@@ -33,6 +33,16 @@ func (m *MockFileRepository) FindAll() ([]models.File, error) {
 	return files, nil
 }
 
+func (m *MockFileRepository) FindByUploader(uploadedBy string) ([]models.File, error) {
+	files := []models.File{}
+	for _, f := range m.Data {
+		if f.UploadedBy == uploadedBy {
+			files = append(files, *f)
+		}
+	}
+	return files, nil
+}
+
 func (m *MockFileRepository) FindByID(id string) (*models.File, error) {
 	if f, ok := m.Data[id]; ok {
 		return f, nil
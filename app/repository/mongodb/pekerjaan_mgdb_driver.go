@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PekerjaanMongoDriver menyimpan data /api/pekerjaan di koleksi pekerjaan MongoDB (DATA_BACKEND=mongo),
+// lengkap dengan soft delete / trash seperti pekerjaan_alumni. Id = field pekerjaan_id.
+type PekerjaanMongoDriver struct {
+	collection *mongo.Collection
+}
+
+func NewPekerjaanMongoDriver(db *mongo.Database) *PekerjaanMongoDriver {
+	return &PekerjaanMongoDriver{
+		collection: db.Collection("pekerjaan"),
+	}
+}
+
+func pekerjaanFromMongo(m models.PekerjaanMongo) pgModel.PekerjaanAlumni {
+	p := pgModel.PekerjaanAlumni{
+		ID:                  m.PekerjaanID,
+		AlumniID:            m.AlumniID,
+		NamaPerusahaan:      m.NamaPerusahaan,
+		PosisiJabatan:       m.PosisiJabatan,
+		BidangIndustri:      m.BidangIndustri,
+		LokasiKerja:         m.LokasiKerja,
+		GajiRange:           m.GajiRange,
+		TanggalSelesaiKerja: m.TanggalSelesaiKerja,
+		StatusPekerjaan:     m.StatusPekerjaan,
+		DeskripsiPekerjaan:  m.DeskripsiPekerjaan,
+		CreatedAt:           m.CreatedAt,
+		UpdatedAt:           m.UpdatedAt,
+		IsDeleted:           m.IsDeleted,
+		DeletedAt:           m.DeletedAt,
+		DeletedBy:           m.DeletedBy,
+		Version:             m.Version,
+	}
+	if m.TanggalMulaiKerja != nil {
+		p.TanggalMulaiKerja = *m.TanggalMulaiKerja
+	}
+	return p
+}
+
+// optionalTime: tanggal kosong disimpan null, sama dengan kolom DATE tanpa nilai
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (r *PekerjaanMongoDriver) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]pgModel.PekerjaanAlumni, error) {
+	filter["pekerjaan_id"] = hasID
+	cur, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []models.PekerjaanMongo
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	list := make([]pgModel.PekerjaanAlumni, 0, len(docs))
+	for _, d := range docs {
+		list = append(list, pekerjaanFromMongo(d))
+	}
+	return list, nil
+}
+
+func orderBy(field string, dir int) *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: field, Value: dir}, {Key: "pekerjaan_id", Value: 1}})
+}
+
+func (r *PekerjaanMongoDriver) activeFilter(search string) bson.M {
+	filter := searchFilter(search, "nama_perusahaan", "posisi_jabatan")
+	filter["is_delete"] = false
+	filter["pekerjaan_id"] = hasID
+	return filter
+}
+
+func (r *PekerjaanMongoDriver) ListPekerjaanRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]pgModel.PekerjaanAlumni, error) {
+	field := sanitizePekerjaanSort(sortBy)
+	opts := options.Find().
+		SetSort(sortOrder(field, order, "pekerjaan_id")).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	return r.find(ctx, r.activeFilter(search), opts)
+}
+
+func (r *PekerjaanMongoDriver) CountPekerjaanRepo(ctx context.Context, search string) (int, error) {
+	n, err := r.collection.CountDocuments(ctx, r.activeFilter(search))
+	return int(n), err
+}
+
+func (r *PekerjaanMongoDriver) GetAllPekerjaan(ctx context.Context) ([]pgModel.PekerjaanAlumni, error) {
+	return r.find(ctx, bson.M{"is_delete": false}, orderBy("created_at", -1))
+}
+
+// GetPekerjaanByID sama dengan Postgres: data di trash tetap bisa diambil
+func (r *PekerjaanMongoDriver) GetPekerjaanByID(ctx context.Context, id int) (*pgModel.PekerjaanAlumni, error) {
+	var doc models.PekerjaanMongo
+	if err := r.collection.FindOne(ctx, byID("pekerjaan_id", id)).Decode(&doc); err != nil {
+		return nil, notFound(err)
+	}
+	p := pekerjaanFromMongo(doc)
+	return &p, nil
+}
+
+func (r *PekerjaanMongoDriver) GetPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]pgModel.PekerjaanAlumni, error) {
+	return r.find(ctx, bson.M{"alumni_id": alumniID}, orderBy("tanggal_mulai_kerja", -1))
+}
+
+func (r *PekerjaanMongoDriver) CreatePekerjaan(ctx context.Context, p *pgModel.PekerjaanAlumni) (int, error) {
+	id, err := nextSequence(ctx, r.collection, "pekerjaan_id")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	_, err = r.collection.InsertOne(ctx, models.PekerjaanMongo{
+		PekerjaanID:         id,
+		AlumniID:            p.AlumniID,
+		NamaPerusahaan:      p.NamaPerusahaan,
+		PosisiJabatan:       p.PosisiJabatan,
+		BidangIndustri:      p.BidangIndustri,
+		LokasiKerja:         p.LokasiKerja,
+		GajiRange:           p.GajiRange,
+		TanggalMulaiKerja:   optionalTime(p.TanggalMulaiKerja),
+		TanggalSelesaiKerja: p.TanggalSelesaiKerja,
+		StatusPekerjaan:     p.StatusPekerjaan,
+		DeskripsiPekerjaan:  p.DeskripsiPekerjaan,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Version:             1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *PekerjaanMongoDriver) UpdatePekerjaan(ctx context.Context, id int, p *pgModel.PekerjaanAlumni, version int) (int64, error) {
+	res, err := r.collection.UpdateOne(ctx, withVersion(byID("pekerjaan_id", id), version), bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{
+		"nama_perusahaan":       p.NamaPerusahaan,
+		"posisi_jabatan":        p.PosisiJabatan,
+		"bidang_industri":       p.BidangIndustri,
+		"lokasi_kerja":          p.LokasiKerja,
+		"gaji_range":            p.GajiRange,
+		"tanggal_mulai_kerja":   optionalTime(p.TanggalMulaiKerja),
+		"tanggal_selesai_kerja": p.TanggalSelesaiKerja,
+		"status_pekerjaan":      p.StatusPekerjaan,
+		"deskripsi_pekerjaan":   p.DeskripsiPekerjaan,
+		"updated_at":            time.Now(),
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+func (r *PekerjaanMongoDriver) SoftDeletePekerjaan(ctx context.Context, id int, deletedBy int, version int) (int64, error) {
+	filter := withVersion(byID("pekerjaan_id", id), version)
+	filter["is_delete"] = false
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{
+		"is_delete":  true,
+		"deleted_at": time.Now(),
+		"deleted_by": strconv.Itoa(deletedBy),
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *PekerjaanMongoDriver) TrashAllPekerjaan(ctx context.Context) ([]pgModel.PekerjaanAlumni, error) {
+	return r.find(ctx, bson.M{"is_delete": true}, orderBy("deleted_at", -1))
+}
+
+func (r *PekerjaanMongoDriver) TrashPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]pgModel.PekerjaanAlumni, error) {
+	return r.find(ctx, bson.M{"is_delete": true, "alumni_id": alumniID}, orderBy("created_at", -1))
+}
+
+func (r *PekerjaanMongoDriver) owned(ctx context.Context, filter bson.M) (bool, error) {
+	n, err := r.collection.CountDocuments(ctx, filter)
+	return n > 0, err
+}
+
+func (r *PekerjaanMongoDriver) IsPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
+	filter := byID("pekerjaan_id", pekerjaanID)
+	filter["alumni_id"] = alumniID
+	return r.owned(ctx, filter)
+}
+
+func (r *PekerjaanMongoDriver) IsTrashedPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
+	filter := byID("pekerjaan_id", pekerjaanID)
+	filter["alumni_id"] = alumniID
+	filter["is_delete"] = true
+	return r.owned(ctx, filter)
+}
+
+func (r *PekerjaanMongoDriver) RestorePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	res, err := r.collection.UpdateOne(ctx, withVersion(byID("pekerjaan_id", id), version), bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{
+		"is_delete":  false,
+		"deleted_at": nil,
+		"deleted_by": "",
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+func (r *PekerjaanMongoDriver) HardDeletePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	filter := withVersion(byID("pekerjaan_id", id), version)
+	filter["is_delete"] = true
+	res, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// PurgeTrash menghapus permanen pekerjaan yang sudah di-trash sebelum waktu tertentu
+func (r *PekerjaanMongoDriver) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{
+		"pekerjaan_id": hasID,
+		"is_delete":    true,
+		"deleted_at":   bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// sanitizePekerjaanSort = whitelist kolom Postgres, "id" dipetakan ke pekerjaan_id
+func sanitizePekerjaanSort(s string) string {
+	switch s {
+	case "alumni_id", "nama_perusahaan", "posisi_jabatan", "tanggal_mulai_kerja", "tanggal_selesai_kerja", "created_at", "updated_at":
+		return s
+	default:
+		return "pekerjaan_id"
+	}
+}
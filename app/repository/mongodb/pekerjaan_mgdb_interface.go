@@ -10,6 +10,7 @@ type PekerjaanMongoRepositoryInterface interface {
 	FindAll(ctx context.Context) ([]models.PekerjaanMongo, error)
 	FindByID(ctx context.Context, id string) (*models.PekerjaanMongo, error)
 	FindByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanMongo, error)
-	Update(ctx context.Context, id string, p *models.PekerjaanMongo) (*models.PekerjaanMongo, error)
-	Delete(ctx context.Context, id string) error
+	// Update & Delete: version 0 = tanpa syarat; tidak ada dokumen yang cocok = ErrNotMatched
+	Update(ctx context.Context, id string, p *models.PekerjaanMongo, version int) (*models.PekerjaanMongo, error)
+	Delete(ctx context.Context, id string, version int) error
 }
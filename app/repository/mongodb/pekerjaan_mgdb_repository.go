@@ -19,6 +19,7 @@ func NewPekerjaanMongoRepository(db *mongo.Database) *PekerjaanMongoRepository {
 }
 
 func (r *PekerjaanMongoRepository) Create(ctx context.Context, p *models.PekerjaanMongo) (*models.PekerjaanMongo, error) {
+	p.Version = 1
 	result, err := r.collection.InsertOne(ctx, p)
 	if err != nil {
 		return nil, err
@@ -68,23 +69,34 @@ func (r *PekerjaanMongoRepository) FindByAlumniID(ctx context.Context, alumniID
 	return list, nil
 }
 
-func (r *PekerjaanMongoRepository) Update(ctx context.Context, id string, p *models.PekerjaanMongo) (*models.PekerjaanMongo, error) {
+// Update: version dinaikkan lewat $inc, bukan dari body (field version kosong tidak ikut $set)
+func (r *PekerjaanMongoRepository) Update(ctx context.Context, id string, p *models.PekerjaanMongo, version int) (*models.PekerjaanMongo, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	_, err = r.collection.UpdateByID(ctx, objID, bson.M{"$set": p})
+	p.Version = 0
+	res, err := r.collection.UpdateOne(ctx, withVersion(bson.M{"_id": objID}, version), bson.M{"$set": p, "$inc": bson.M{"version": 1}})
 	if err != nil {
 		return nil, err
 	}
+	if res.MatchedCount == 0 {
+		return nil, ErrNotMatched
+	}
 	return r.FindByID(ctx, id)
 }
 
-func (r *PekerjaanMongoRepository) Delete(ctx context.Context, id string) error {
+func (r *PekerjaanMongoRepository) Delete(ctx context.Context, id string, version int) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
-	return err
+	res, err := r.collection.DeleteOne(ctx, withVersion(bson.M{"_id": objID}, version))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotMatched
+	}
+	return nil
 }
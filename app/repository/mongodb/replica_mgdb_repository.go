@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReplicaMongoRepository menerapkan event outbox Postgres ke koleksi alumni & pekerjaan.
+// Semua operasi idempotent: upsert berdasarkan id Postgres (alumni_id / pekerjaan_id) dengan isi baris
+// terbaru, dan menghapus dokumen yang sudah tidak ada tidak dianggap gagal. Event boleh diulang.
+type ReplicaMongoRepository struct {
+	alumni    *mongo.Collection
+	pekerjaan *mongo.Collection
+}
+
+func NewReplicaMongoRepository(db *mongo.Database) *ReplicaMongoRepository {
+	return &ReplicaMongoRepository{
+		alumni:    db.Collection("alumni"),
+		pekerjaan: db.Collection("pekerjaan"),
+	}
+}
+
+// replicaVersion: version ikut disalin supaya ETag sama di kedua backend; event lama tanpa version tidak menimpanya
+func replicaVersion(set bson.M, version int) bson.M {
+	if version > 0 {
+		set["version"] = version
+	}
+	return set
+}
+
+// UpsertAlumni tidak menyentuh tempat_kerja yang hanya ada di Mongo. Dokumen lama tanpa alumni_id
+// dengan NIM yang sama dipakai ulang (nim unik di koleksi), bukan dibuat dokumen baru.
+func (r *ReplicaMongoRepository) UpsertAlumni(ctx context.Context, a pgModel.Alumni) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"alumni_id": a.ID},
+		bson.M{"nim": a.NIM, "alumni_id": bson.M{"$not": hasID}},
+	}}
+	_, err := r.alumni.UpdateOne(ctx, filter, bson.M{"$set": replicaVersion(bson.M{
+		"alumni_id":   a.ID,
+		"nim":         a.NIM,
+		"nama":        a.Nama,
+		"jurusan":     a.Jurusan,
+		"angkatan":    a.Angkatan,
+		"tahun_lulus": a.TahunLulus,
+		"email":       a.Email,
+		"no_telepon":  derefString(a.NoTelepon),
+		"alamat":      derefString(a.Alamat),
+		"created_at":  a.CreatedAt,
+		"updated_at":  a.UpdatedAt,
+	}, a.Version)}, options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteAlumni ikut menghapus pekerjaan alumni tersebut, sama dengan ON DELETE CASCADE di Postgres
+func (r *ReplicaMongoRepository) DeleteAlumni(ctx context.Context, id int) error {
+	if _, err := r.pekerjaan.DeleteMany(ctx, bson.M{"alumni_id": id}); err != nil {
+		return err
+	}
+	_, err := r.alumni.DeleteMany(ctx, bson.M{"alumni_id": id})
+	return err
+}
+
+func (r *ReplicaMongoRepository) UpsertPekerjaan(ctx context.Context, p pgModel.PekerjaanAlumni) error {
+	_, err := r.pekerjaan.UpdateOne(ctx, bson.M{"pekerjaan_id": p.ID}, bson.M{"$set": replicaVersion(bson.M{
+		"pekerjaan_id":          p.ID,
+		"alumni_id":             p.AlumniID,
+		"nama_perusahaan":       p.NamaPerusahaan,
+		"posisi_jabatan":        p.PosisiJabatan,
+		"bidang_industri":       p.BidangIndustri,
+		"lokasi_kerja":          p.LokasiKerja,
+		"gaji_range":            p.GajiRange,
+		"tanggal_mulai_kerja":   optionalTime(p.TanggalMulaiKerja),
+		"tanggal_selesai_kerja": p.TanggalSelesaiKerja,
+		"status_pekerjaan":      p.StatusPekerjaan,
+		"deskripsi_pekerjaan":   p.DeskripsiPekerjaan,
+		"is_delete":             p.IsDeleted,
+		"deleted_at":            p.DeletedAt,
+		"deleted_by":            p.DeletedBy,
+		"created_at":            p.CreatedAt,
+		"updated_at":            p.UpdatedAt,
+	}, p.Version)}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *ReplicaMongoRepository) DeletePekerjaan(ctx context.Context, id int) error {
+	_, err := r.pekerjaan.DeleteMany(ctx, bson.M{"pekerjaan_id": id})
+	return err
+}
+
+// SnapshotAlumni = semua dokumen alumni, termasuk dokumen lama tanpa alumni_id (id 0, dicocokkan lewat NIM)
+func (r *ReplicaMongoRepository) SnapshotAlumni(ctx context.Context) ([]pgModel.Alumni, error) {
+	cur, err := r.alumni.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "alumni_id", Value: 1}, {Key: "nim", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []models.AlumniMongo
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	list := make([]pgModel.Alumni, 0, len(docs))
+	for _, d := range docs {
+		list = append(list, alumniFromMongo(d))
+	}
+	return list, nil
+}
+
+// SnapshotPekerjaan = semua pekerjaan ber-pekerjaan_id termasuk trash; dokumen lama tanpa id tidak punya pasangan di Postgres
+func (r *ReplicaMongoRepository) SnapshotPekerjaan(ctx context.Context) ([]pgModel.PekerjaanAlumni, error) {
+	cur, err := r.pekerjaan.Find(ctx, bson.M{"pekerjaan_id": hasID}, options.Find().SetSort(bson.D{{Key: "pekerjaan_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []models.PekerjaanMongo
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	list := make([]pgModel.PekerjaanAlumni, 0, len(docs))
+	for _, d := range docs {
+		list = append(list, pekerjaanFromMongo(d))
+	}
+	return list, nil
+}
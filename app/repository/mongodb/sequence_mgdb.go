@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// nextSequence memberi id angka berikutnya untuk field di koleksi (pengganti SERIAL Postgres).
+// Counter disimpan di koleksi "counters" dan tidak pernah lebih kecil dari id terbesar yang sudah ada,
+// jadi dokumen lama (misal alumni_id yang diisi manual lewat /api/alumni-mongo) tidak bentrok.
+func nextSequence(ctx context.Context, coll *mongo.Collection, field string) (int, error) {
+	var last bson.M
+	err := coll.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: field, Value: -1}}).SetProjection(bson.M{field: 1}),
+	).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+	max := 0
+	switch n := last[field].(type) {
+	case int32:
+		max = int(n)
+	case int64:
+		max = int(n)
+	}
+
+	counters := coll.Database().Collection("counters")
+	key := bson.M{"_id": coll.Name() + "." + field}
+	_, err = counters.UpdateOne(ctx, key, bson.M{"$max": bson.M{"seq": max}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = counters.FindOneAndUpdate(ctx, key, bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Seq, err
+}
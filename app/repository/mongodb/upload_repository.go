@@ -13,6 +13,7 @@ import (
 type FileRepository interface {
     Create(file *models.File) error
     FindAll() ([]models.File, error)
+    FindByUploader(uploadedBy string) ([]models.File, error)
     FindByID(id string) (*models.File, error)
     Delete(id string) error
 }
@@ -59,6 +60,23 @@ func (r *fileRepository) FindAll() ([]models.File, error) {
     return files, nil
 }
 
+func (r *fileRepository) FindByUploader(uploadedBy string) ([]models.File, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    files := []models.File{}
+    cursor, err := r.collection.Find(ctx, bson.M{"uploaded_by": uploadedBy})
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    if err = cursor.All(ctx, &files); err != nil {
+        return nil, err
+    }
+    return files, nil
+}
+
 func (r *fileRepository) FindByID(id string) (*models.File, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
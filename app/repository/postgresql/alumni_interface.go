@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type AlumniRepositoryInterface interface {
+	GetAllAlumni(ctx context.Context) ([]models.Alumni, error)
+	GetAlumniAndPekerjaan(ctx context.Context, nim int) (*models.AlumniPekerjaan, error)
+	GetAlumniByID(ctx context.Context, id int) (*models.Alumni, error)
+	GetAlumniByAngkatan(ctx context.Context, angkatan int) (*models.AlumniAngkatan, error)
+	CreateAlumni(ctx context.Context, alumni *models.Alumni) (int, error)
+	UpdateAlumni(ctx context.Context, id int, alumni *models.Alumni, version int) (int64, error)
+	DeleteAlumni(ctx context.Context, id int, version int) (int64, error)
+	ListAlumniRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.Alumni, error)
+	CountAlumniRepo(ctx context.Context, search string) (int, error)
+}
@@ -1,10 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"go_clean/app/models/postgresql"
-	"go_clean/database"
 	"time"
 )
 
@@ -17,8 +17,8 @@ func AlumniSortable() []string {
 	return []string{"nim", "nama", "jurusan", "angkatan", "email"}
 }
 
-func (r *AlumniRepository) GetAllAlumni() ([]models.Alumni, error) {
-	rows, err := r.DB.Query("SELECT id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at FROM alumni ORDER BY created_at DESC")
+func (r *AlumniRepository) GetAllAlumni(ctx context.Context) ([]models.Alumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at, version FROM alumni ORDER BY created_at DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,7 @@ func (r *AlumniRepository) GetAllAlumni() ([]models.Alumni, error) {
 	var alumniList []models.Alumni
 	for rows.Next() {
 		var a models.Alumni
-		if err := rows.Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		if err := rows.Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt, &a.Version); err != nil {
 			return nil, err
 		}
 		alumniList = append(alumniList, a)
@@ -35,7 +35,7 @@ func (r *AlumniRepository) GetAllAlumni() ([]models.Alumni, error) {
 	return alumniList, nil
 }
 
-func (r *AlumniRepository) GetAlumniAndPekerjaan(nim int) (*models.AlumniPekerjaan, error) {
+func (r *AlumniRepository) GetAlumniAndPekerjaan(ctx context.Context, nim int) (*models.AlumniPekerjaan, error) {
 	query := `
         SELECT a.id, a.nim, a.nama, a.jurusan, a.angkatan, a.tahun_lulus, a.email,
         p.nama_perusahaan, p.posisi_jabatan, p.tanggal_mulai_kerja, p.tanggal_selesai_kerja
@@ -44,7 +44,7 @@ func (r *AlumniRepository) GetAlumniAndPekerjaan(nim int) (*models.AlumniPekerja
         WHERE a.id = $1
 
     `
-	row := r.DB.QueryRow(query, nim)
+	row := conn(ctx, r.DB).QueryRowContext(ctx, query, nim)
 
 	var result models.AlumniPekerjaan
 	err := row.Scan(
@@ -59,53 +59,99 @@ func (r *AlumniRepository) GetAlumniAndPekerjaan(nim int) (*models.AlumniPekerja
 	return &result, nil
 }
 
-func (r *AlumniRepository) GetAlumniByID(id int) (*models.Alumni, error) {
+func (r *AlumniRepository) GetAlumniByID(ctx context.Context, id int) (*models.Alumni, error) {
 	var a models.Alumni
-	err := r.DB.QueryRow("SELECT id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at FROM alumni WHERE id = $1", id).Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt)
+	err := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at, version FROM alumni WHERE id = $1", id).Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt, &a.Version)
 	if err != nil {
 		return nil, err
 	}
 	return &a, nil
 }
 
-func (r *AlumniRepository) GetAlumniByAngkatan(angkatan int) (*models.AlumniAngkatan, error) {
+func (r *AlumniRepository) GetAlumniByAngkatan(ctx context.Context, angkatan int) (*models.AlumniAngkatan, error) {
 	jumlahalumni := &models.AlumniAngkatan{Angkatan: angkatan}
-	err := r.DB.QueryRow("SELECT COUNT(*) FROM alumni WHERE angkatan = $1", angkatan).Scan(&jumlahalumni.Jumlah)
+	err := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT COUNT(*) FROM alumni WHERE angkatan = $1", angkatan).Scan(&jumlahalumni.Jumlah)
 	if err != nil {
 		return nil, err
 	}
 	return jumlahalumni, nil
 }
 
-func (r *AlumniRepository) CreateAlumni(alumni *models.Alumni) (int, error) {
-	var id int
-	err := r.DB.QueryRow(
-		"INSERT INTO alumni (nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
+// alumniReturning = kolom lengkap untuk payload outbox (RETURNING setelah INSERT / UPDATE)
+const alumniReturning = `RETURNING id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at, version`
+
+func scanAlumniRow(row *sql.Row, a *models.Alumni) error {
+	return row.Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt, &a.Version)
+}
+
+func (r *AlumniRepository) CreateAlumni(ctx context.Context, alumni *models.Alumni) (int, error) {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var a models.Alumni
+	err = scanAlumniRow(tx.QueryRowContext(ctx,
+		"INSERT INTO alumni (nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) "+alumniReturning,
 		alumni.NIM, alumni.Nama, alumni.Jurusan, alumni.Angkatan, alumni.TahunLulus, alumni.Email, alumni.NoTelepon, alumni.Alamat, time.Now(), time.Now(),
-	).Scan(&id)
+	), &a)
 	if err != nil {
 		return 0, err
 	}
-	return id, nil
+	if err := appendOutbox(ctx, tx, models.OutboxAggregateAlumni, a.ID, models.OutboxOpUpsert, a); err != nil {
+		return 0, err
+	}
+	return a.ID, tx.Commit()
 }
 
-func (r *AlumniRepository) UpdateAlumni(id int, alumni *models.Alumni) (int64, error) {
-	result, err := r.DB.Exec(
-		"UPDATE alumni SET nama = $1, jurusan = $2, angkatan = $3, tahun_lulus = $4, email = $5, no_telepon = $6, alamat = $7, updated_at = $8 WHERE id = $9",
-		alumni.Nama, alumni.Jurusan, alumni.Angkatan, alumni.TahunLulus, alumni.Email, alumni.NoTelepon, alumni.Alamat, time.Now(), id,
-	)
+// UpdateAlumni hanya mengubah baris yang version-nya masih sama dengan version (0 = tanpa syarat) lalu menaikkan version.
+// 0 baris berarti id tidak ada atau sudah diubah orang lain; service membedakannya dengan membaca ulang.
+func (r *AlumniRepository) UpdateAlumni(ctx context.Context, id int, alumni *models.Alumni, version int) (int64, error) {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var a models.Alumni
+	err = scanAlumniRow(tx.QueryRowContext(ctx,
+		"UPDATE alumni SET nama = $1, jurusan = $2, angkatan = $3, tahun_lulus = $4, email = $5, no_telepon = $6, alamat = $7, updated_at = $8, version = version + 1 WHERE id = $9 AND ($10 < 0 OR version = $10) "+alumniReturning,
+		alumni.Nama, alumni.Jurusan, alumni.Angkatan, alumni.TahunLulus, alumni.Email, alumni.NoTelepon, alumni.Alamat, time.Now(), id, version,
+	), &a)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	if err := appendOutbox(ctx, tx, models.OutboxAggregateAlumni, a.ID, models.OutboxOpUpsert, a); err != nil {
+		return 0, err
+	}
+	return 1, tx.Commit()
 }
 
-func (r *AlumniRepository) DeleteAlumni(id int) (int64, error) {
-	result, err := r.DB.Exec("DELETE FROM alumni WHERE id = $1", id)
+// DeleteAlumni juga menghapus pekerjaan_alumni (ON DELETE CASCADE); relay menghapus pekerjaan di Mongo
+// lewat event delete alumni yang sama. version 0 = tanpa syarat, sama seperti UpdateAlumni.
+func (r *AlumniRepository) DeleteAlumni(ctx context.Context, id int, version int) (int64, error) {
+	tx, err := beginTx(ctx, r.DB)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM alumni WHERE id = $1 AND ($2 < 0 OR version = $2)", id, version)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return n, err
+	}
+	if err := appendOutbox(ctx, tx, models.OutboxAggregateAlumni, id, models.OutboxOpDelete, nil); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
 }
 
 func sanitizeAlumniSort(s string) string {
@@ -124,7 +170,7 @@ func sanitizeOrderAlumni(o string) string {
 	return "ASC"
 }
 
-func ListAlumniRepo(search, sortBy, order string, limit, offset int) ([]models.Alumni, error) {
+func (r *AlumniRepository) ListAlumniRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.Alumni, error) {
 	// Sanitasi sort & order biar aman dari SQL injection via fmt.Sprintf
 	sortBy = sanitizeAlumniSort(sortBy)
 	order = sanitizeOrderAlumni(order)
@@ -137,7 +183,7 @@ func ListAlumniRepo(search, sortBy, order string, limit, offset int) ([]models.A
         LIMIT $2 OFFSET $3
     `, sortBy, order)
 
-	rows, err := database.DB.Query(query, "%"+search+"%", limit, offset)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, query, "%"+search+"%", limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -154,9 +200,9 @@ func ListAlumniRepo(search, sortBy, order string, limit, offset int) ([]models.A
 	return items, rows.Err()
 }
 
-func CountAlumniRepo(search string) (int, error) {
+func (r *AlumniRepository) CountAlumniRepo(ctx context.Context, search string) (int, error) {
 	var total int
-	err := database.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
         SELECT COUNT(*)
         FROM alumni
         WHERE (nama ILIKE $1 OR CAST(nim AS TEXT) ILIKE $1)
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type APIKeyRepositoryInterface interface {
+	Create(ctx context.Context, k *models.APIKey) error
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id int) (int64, error)
+	TouchLastUsed(ctx context.Context, id int, ip string) error
+}
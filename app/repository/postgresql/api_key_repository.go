@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"go_clean/app/models/postgresql"
+)
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes), &k.CreatedBy,
+		&k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP, &k.RevokedAt, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	return conn(ctx, r.DB).QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, k.Name, k.Prefix, k.KeyHash, pq.Array(k.Scopes), k.CreatedBy, k.ExpiresAt).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return scanAPIKey(conn(ctx, r.DB).QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) (int64, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// TouchLastUsed dibatasi sekali per menit per key supaya tiap request tidak selalu menulis ke DB
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int, ip string) error {
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id, ip)
+	return err
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type AuditRepositoryInterface interface {
+	Insert(ctx context.Context, e *models.AuditEntry) error
+	List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error)
+}
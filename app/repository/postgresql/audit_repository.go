@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"go_clean/app/models/postgresql"
+)
+
+// AuditRepository hanya bisa menambah dan membaca; tabel audit_logs menolak UPDATE / DELETE
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+const auditColumns = `id, actor, actor_username, role, ip, action, entity_type, entity_id, before_data, after_data, created_at`
+
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
+
+func (r *AuditRepository) Insert(ctx context.Context, e *models.AuditEntry) error {
+	return conn(ctx, r.DB).QueryRowContext(ctx, `
+		INSERT INTO audit_logs (actor, actor_username, role, ip, action, entity_type, entity_id, before_data, after_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, e.Actor, e.ActorUsername, e.Role, e.IP, e.Action, e.EntityType, e.EntityID,
+		nullJSON(e.Before), nullJSON(e.After)).Scan(&e.ID, &e.CreatedAt)
+}
+
+// List mengembalikan entri sesuai filter (terbaru dulu) beserta total tanpa limit
+func (r *AuditRepository) List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, int, error) {
+	conds := []string{}
+	args := []interface{}{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, strings.Replace(cond, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+	if f.Actor != "" {
+		// actor bisa berupa subject ("postgres:1") atau username
+		add("? IN (actor, actor_username)", f.Actor)
+	}
+	if f.EntityType != "" {
+		add("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = ?", f.EntityID)
+	}
+	if f.Action != "" {
+		add("action = ?", f.Action)
+	}
+	if f.From != nil {
+		add("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < ?", *f.To)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT `+auditColumns+` FROM audit_logs`+where+
+		` ORDER BY created_at DESC, id DESC LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.Actor, &e.ActorUsername, &e.Role, &e.IP, &e.Action,
+			&e.EntityType, &e.EntityID, &before, &after, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		e.Before, e.After = before, after
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
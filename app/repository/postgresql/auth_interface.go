@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type AuthRepositoryInterface interface {
+	GetByUsernameOrEmail(ctx context.Context, identifier string) (*models.User, string, error)
+	ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error)
+	LoginRepo(ctx context.Context, identifier string) (*models.User, string, error)
+	Backend() string
+	FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error)
+	FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error)
+	CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*models.Identity, error)
+	UpdateEmail(ctx context.Context, userID, email string) error
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+	Create(ctx context.Context, username, email, passwordHash, role string, emailVerified bool) (*models.User, error)
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"go_clean/app/models/postgresql"
+	"strconv"
+	"strings"
 )
 
 
@@ -10,23 +13,23 @@ type AuthRepository struct {
 	DB *sql.DB
 }
 
-func (r *AuthRepository) GetByUsernameOrEmail(identifier string) (*models.User, string, error) {
+func (r *AuthRepository) GetByUsernameOrEmail(ctx context.Context, identifier string) (*models.User, string, error) {
 	u := models.User{}
 	var hash string
-	err := r.DB.QueryRow(`
-		SELECT id, username, email, password_hash, role
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT id, username, email, password_hash, role, alumni_id, email_verified, disabled_at IS NOT NULL
 		FROM users
 		WHERE username = $1 OR email = $1
-	`, identifier).Scan(&u.ID, &u.Username, &u.Email, &hash, &u.Role)
+	`, identifier).Scan(&u.ID, &u.Username, &u.Email, &hash, &u.Role, &u.AlumniID, &u.EmailVerified, &u.Disabled)
 	if err != nil {
 		return nil, "", err
 	}
 	return &u, hash, nil
 }
 
-func (r *AuthRepository) ExistsByUsernameOrEmail(username, email string) (bool, error) {
+func (r *AuthRepository) ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error) {
 	var exists bool
-	err := r.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT 1 FROM users WHERE username = $1 OR email = $2
 		)
@@ -34,11 +37,33 @@ func (r *AuthRepository) ExistsByUsernameOrEmail(username, email string) (bool,
 	return exists, err
 }
 
-func (r *AuthRepository) LoginRepo(identifier string) (*models.User, string, error) {
+// Create menyimpan user baru. emailVerified false untuk akun self-register yang wajib verifikasi email.
+func (r *AuthRepository) Create(ctx context.Context, username, email, passwordHash, role string, emailVerified bool) (*models.User, error) {
+	role = models.NormalizeRole(strings.ToLower(role))
+	var roleExists bool
+	if err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, role).Scan(&roleExists); err != nil {
+		return nil, err
+	}
+	if !roleExists {
+		return nil, ErrInvalidRole
+	}
+	var u models.User
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		INSERT INTO users (username, email, password_hash, role, email_verified)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, username, email, role, email_verified
+	`, username, email, passwordHash, role, emailVerified).Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.EmailVerified)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *AuthRepository) LoginRepo(ctx context.Context, identifier string) (*models.User, string, error) {
 	var u models.User
 	var hash string
 
-	err := r.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
 		SELECT id, username, email, password_hash, role
 		FROM users
 		WHERE username = $1 OR email = $1
@@ -51,3 +76,78 @@ func (r *AuthRepository) LoginRepo(identifier string) (*models.User, string, err
 	return &u, hash, nil
 }
 
+
+func (r *AuthRepository) Backend() string {
+	return models.BackendPostgres
+}
+
+// FindIdentity dipakai identity store gabungan (/api/login)
+func (r *AuthRepository) FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error) {
+	u, hash, err := r.GetByUsernameOrEmail(ctx, identifier)
+	if err != nil {
+		return nil, "", err
+	}
+	id := models.PostgresIdentity(*u)
+	return &id, hash, nil
+}
+
+// FindIdentityByID dipakai login SSO untuk akun yang sudah tertaut ke IdP
+func (r *AuthRepository) FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+	u := models.User{}
+	err = conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT id, username, email, role, alumni_id, email_verified, disabled_at IS NOT NULL
+		FROM users
+		WHERE id = $1
+	`, id).Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.AlumniID, &u.EmailVerified, &u.Disabled)
+	if err != nil {
+		return nil, err
+	}
+	ident := models.PostgresIdentity(u)
+	return &ident, nil
+}
+
+// CreateIdentity dipakai auto-provisioning SSO. Email sudah diverifikasi IdP.
+func (r *AuthRepository) CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*models.Identity, error) {
+	u, err := r.Create(ctx, username, email, passwordHash, role, true)
+	if err != nil {
+		return nil, err
+	}
+	ident := models.PostgresIdentity(*u)
+	return &ident, nil
+}
+
+// UpdateEmail dipakai setelah link konfirmasi ganti email diklik, jadi email baru langsung terverifikasi
+func (r *AuthRepository) UpdateEmail(ctx context.Context, userID, email string) error {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE users SET email = $1, email_verified = TRUE WHERE id = $2`, email, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdatePassword menerima id dalam bentuk string supaya seragam dengan identity store Mongo
+func (r *AuthRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
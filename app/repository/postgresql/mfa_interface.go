@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+type MFARepositoryInterface interface {
+	Get(ctx context.Context, backend, userID string) (*models.UserMFA, error)
+	SavePending(ctx context.Context, backend, userID, encSecret string) (bool, error)
+	Enable(ctx context.Context, backend, userID string, step int64, codeHashes []string) error
+	MarkStepUsed(ctx context.Context, backend, userID string, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, backend, userID, codeHash string) (bool, error)
+	UseChallenge(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, backend, userID string) (int, error)
+	ReplaceRecoveryCodes(ctx context.Context, backend, userID string, codeHashes []string) error
+	Disable(ctx context.Context, backend, userID string) error
+}
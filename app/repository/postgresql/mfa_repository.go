@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+type MFARepository struct {
+	DB *sql.DB
+}
+
+func (r *MFARepository) Get(ctx context.Context, backend, userID string) (*models.UserMFA, error) {
+	var m models.UserMFA
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT backend, user_id, secret, enabled, last_used_step, confirmed_at, created_at
+		FROM user_mfa
+		WHERE backend = $1 AND user_id = $2
+	`, backend, userID).Scan(&m.Backend, &m.UserID, &m.Secret, &m.Enabled, &m.LastUsedStep, &m.ConfirmedAt, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SavePending menyimpan secret baru yang belum dikonfirmasi. 2FA yang sudah aktif tidak ditimpa:
+// hasilnya false supaya enroll ulang tidak bisa dipakai untuk mengganti authenticator diam-diam.
+func (r *MFARepository) SavePending(ctx context.Context, backend, userID, encSecret string) (bool, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `
+		INSERT INTO user_mfa (backend, user_id, secret)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (backend, user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+			WHERE user_mfa.enabled = FALSE
+	`, backend, userID, encSecret)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Enable mengaktifkan 2FA dan mengganti semua recovery code dalam satu transaksi
+func (r *MFARepository) Enable(ctx context.Context, backend, userID string, step int64, codeHashes []string) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa SET enabled = TRUE, confirmed_at = NOW(), last_used_step = $3
+		WHERE backend = $1 AND user_id = $2 AND enabled = FALSE
+	`, backend, userID, step)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if err := replaceRecoveryCodes(ctx, tx, backend, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkStepUsed menyimpan time-step terakhir. false berarti kode step itu (atau yang lebih baru) sudah dipakai.
+func (r *MFARepository) MarkStepUsed(ctx context.Context, backend, userID string, step int64) (bool, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE user_mfa SET last_used_step = $3
+		WHERE backend = $1 AND user_id = $2 AND last_used_step < $3
+	`, backend, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ConsumeRecoveryCode memakai satu recovery code secara atomik
+func (r *MFARepository) ConsumeRecoveryCode(ctx context.Context, backend, userID, codeHash string) (bool, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM mfa_recovery_codes
+			WHERE backend = $1 AND user_id = $2 AND code_hash = $3 AND used_at IS NULL
+			LIMIT 1
+		) AND used_at IS NULL
+	`, backend, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// UseChallenge menandai mfa_token (hash) sudah dipakai. false berarti token itu sudah pernah dipakai.
+func (r *MFARepository) UseChallenge(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `
+		INSERT INTO mfa_used_challenges (token_hash, expires_at) VALUES ($1, $2)
+		ON CONFLICT (token_hash) DO NOTHING
+	`, tokenHash, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	// sekalian buang token yang sudah kedaluwarsa, tidak mungkin dipakai lagi
+	_, _ = conn(ctx, r.DB).ExecContext(ctx, `DELETE FROM mfa_used_challenges WHERE expires_at < NOW()`)
+	return n > 0, nil
+}
+
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, backend, userID string) (int, error) {
+	var n int
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM mfa_recovery_codes
+		WHERE backend = $1 AND user_id = $2 AND used_at IS NULL
+	`, backend, userID).Scan(&n)
+	return n, err
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, backend, userID string, codeHashes []string) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := replaceRecoveryCodes(ctx, tx, backend, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Disable menghapus 2FA beserta recovery code-nya
+func (r *MFARepository) Disable(ctx context.Context, backend, userID string) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE backend = $1 AND user_id = $2`, backend, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE backend = $1 AND user_id = $2`, backend, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx DBTX, backend, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE backend = $1 AND user_id = $2`, backend, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (backend, user_id, code_hash) VALUES ($1, $2, $3)
+		`, backend, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// MockAlumniRepository = AlumniRepositoryInterface di memori untuk test service.
+// Data tidak ditemukan dikembalikan sebagai sql.ErrNoRows, sama dengan repository aslinya.
+type MockAlumniRepository struct {
+	Data   map[int]*models.Alumni
+	nextID int
+	// Pekerjaan opsional, dipakai GetAlumniAndPekerjaan (JOIN pekerjaan_alumni)
+	Pekerjaan *MockPekerjaanRepository
+}
+
+func NewMockAlumniRepository() *MockAlumniRepository {
+	return &MockAlumniRepository{
+		Data: make(map[int]*models.Alumni),
+	}
+}
+
+// sorted mengembalikan salinan data, urut id (stabil untuk test)
+func (m *MockAlumniRepository) sorted() []models.Alumni {
+	list := make([]models.Alumni, 0, len(m.Data))
+	for _, a := range m.Data {
+		list = append(list, *a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (m *MockAlumniRepository) GetAllAlumni(ctx context.Context) ([]models.Alumni, error) {
+	list := m.sorted()
+	// sama dengan ORDER BY created_at DESC
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *MockAlumniRepository) GetAlumniAndPekerjaan(ctx context.Context, id int) (*models.AlumniPekerjaan, error) {
+	a, ok := m.Data[id]
+	if !ok || m.Pekerjaan == nil {
+		return nil, sql.ErrNoRows
+	}
+	for _, p := range m.Pekerjaan.sorted() {
+		if p.AlumniID != id {
+			continue
+		}
+		res := &models.AlumniPekerjaan{
+			ID: a.ID, NIM: a.NIM, Nama: a.Nama, Jurusan: a.Jurusan, Angkatan: a.Angkatan,
+			TahunLulus: a.TahunLulus, Email: a.Email, NamaPerusahaan: p.NamaPerusahaan, Posisi: p.PosisiJabatan,
+			TahunMulai: p.TanggalMulaiKerja.Year(),
+		}
+		if p.TanggalSelesaiKerja != nil {
+			res.TahunSelesai = p.TanggalSelesaiKerja.Year()
+		}
+		return res, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockAlumniRepository) GetAlumniByID(ctx context.Context, id int) (*models.Alumni, error) {
+	a, ok := m.Data[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (m *MockAlumniRepository) GetAlumniByAngkatan(ctx context.Context, angkatan int) (*models.AlumniAngkatan, error) {
+	res := &models.AlumniAngkatan{Angkatan: angkatan}
+	for _, a := range m.Data {
+		if a.Angkatan == angkatan {
+			res.Jumlah++
+		}
+	}
+	return res, nil
+}
+
+func (m *MockAlumniRepository) CreateAlumni(ctx context.Context, alumni *models.Alumni) (int, error) {
+	m.nextID++
+	cp := *alumni
+	cp.ID, cp.Version = m.nextID, 1
+	cp.CreatedAt, cp.UpdatedAt = time.Now(), time.Now()
+	m.Data[cp.ID] = &cp
+	return cp.ID, nil
+}
+
+// versionMatches meniru "($n < 0 OR version = $n)"
+func versionMatches(current, want int) bool {
+	return want == models.AnyVersion || current == want
+}
+
+func (m *MockAlumniRepository) UpdateAlumni(ctx context.Context, id int, alumni *models.Alumni, version int) (int64, error) {
+	a, ok := m.Data[id]
+	if !ok || !versionMatches(a.Version, version) {
+		return 0, nil
+	}
+	a.Nama, a.Jurusan, a.Angkatan, a.TahunLulus = alumni.Nama, alumni.Jurusan, alumni.Angkatan, alumni.TahunLulus
+	a.Email, a.NoTelepon, a.Alamat, a.UpdatedAt = alumni.Email, alumni.NoTelepon, alumni.Alamat, time.Now()
+	a.Version++
+	return 1, nil
+}
+
+func (m *MockAlumniRepository) DeleteAlumni(ctx context.Context, id int, version int) (int64, error) {
+	if a, ok := m.Data[id]; !ok || !versionMatches(a.Version, version) {
+		return 0, nil
+	}
+	delete(m.Data, id)
+	return 1, nil
+}
+
+func (m *MockAlumniRepository) filter(search string) []models.Alumni {
+	var list []models.Alumni
+	for _, a := range m.sorted() {
+		if containsFold(a.Nama, search) || containsFold(a.NIM, search) {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+func (m *MockAlumniRepository) ListAlumniRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.Alumni, error) {
+	list := m.filter(search)
+	field := sanitizeAlumniSort(sortBy)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch field {
+		case "nim":
+			return a.NIM < b.NIM
+		case "nama":
+			return a.Nama < b.Nama
+		case "jurusan":
+			return a.Jurusan < b.Jurusan
+		case "angkatan":
+			return a.Angkatan < b.Angkatan
+		case "email":
+			return a.Email < b.Email
+		}
+		return a.ID < b.ID
+	})
+	if sanitizeOrderAlumni(order) == "DESC" {
+		reverse(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+	}
+	lo, hi := window(len(list), limit, offset)
+	return list[lo:hi], nil
+}
+
+func (m *MockAlumniRepository) CountAlumniRepo(ctx context.Context, search string) (int, error) {
+	return len(m.filter(search)), nil
+}
+
+// containsFold meniru ILIKE '%search%'
+func containsFold(s, search string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(search))
+}
+
+// window meniru LIMIT / OFFSET
+func window(n, limit, offset int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+	end := n
+	if limit >= 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
+
+func reverse(n int, swap func(i, j int)) {
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
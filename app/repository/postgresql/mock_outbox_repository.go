@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// MockOutboxRepository = OutboxRepositoryInterface di memori. Event ditambah lewat Add
+// (di Postgres event ditulis oleh repository alumni / pekerjaan di transaksi yang sama).
+type MockOutboxRepository struct {
+	mu     sync.Mutex
+	Events map[int64]*models.OutboxEvent
+	nextID int64
+	// Locked mensimulasikan relay instance lain yang sedang memegang advisory lock
+	Locked bool
+}
+
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{
+		Events: make(map[int64]*models.OutboxEvent),
+	}
+}
+
+// Add menambah event pending, payload di-marshal seperti appendOutbox
+func (m *MockOutboxRepository) Add(e models.OutboxEvent) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	e.ID = m.nextID
+	if e.Status == "" {
+		e.Status = models.OutboxPending
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = e.CreatedAt
+	}
+	m.Events[e.ID] = &e
+	return e.ID
+}
+
+func (m *MockOutboxRepository) with(status string) []models.OutboxEvent {
+	list := []models.OutboxEvent{}
+	for _, e := range m.Events {
+		if e.Status == status {
+			list = append(list, *e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Pending meniru filter SQL OutboxRepository.Pending
+func (m *MockOutboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	all := m.with(models.OutboxPending)
+	list := []models.OutboxEvent{}
+	for i, e := range all {
+		if len(list) == limit {
+			break
+		}
+		if e.NextAttemptAt.After(now) || heldBack(e, all[:i], now) {
+			continue
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+// heldBack: ada event lebih awal untuk baris yang sama yang menunggu backoff, atau upsert pekerjaan
+// milik alumni e yang belum tersalin
+func heldBack(e models.OutboxEvent, earlier []models.OutboxEvent, now time.Time) bool {
+	for _, x := range earlier {
+		if x.Key() == e.Key() && x.NextAttemptAt.After(now) {
+			return true
+		}
+		if e.Aggregate == models.OutboxAggregateAlumni && x.Aggregate == models.OutboxAggregatePekerjaan && x.Op == models.OutboxOpUpsert {
+			var p struct {
+				AlumniID int `json:"alumni_id"`
+			}
+			if json.Unmarshal(x.Payload, &p) == nil && p.AlumniID == e.AggregateID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *MockOutboxRepository) MarkDone(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.Events[id]; ok {
+		now := time.Now()
+		e.Status = models.OutboxDone
+		e.Attempts++
+		e.LastError = ""
+		e.ProcessedAt = &now
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, next time.Time, dead bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.Events[id]; ok {
+		e.Attempts++
+		e.LastError = errMsg
+		e.NextAttemptAt = next
+		if dead {
+			e.Status = models.OutboxDead
+		}
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) Dead(ctx context.Context, limit, offset int) ([]models.OutboxEvent, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.with(models.OutboxDead)
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	total := len(list)
+	if offset >= total {
+		return []models.OutboxEvent{}, total, nil
+	}
+	list = list[offset:]
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, total, nil
+}
+
+func (m *MockOutboxRepository) Requeue(ctx context.Context, id int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.Events[id]
+	if !ok || e.Status != models.OutboxDead {
+		return 0, nil
+	}
+	e.Status = models.OutboxPending
+	e.Attempts = 0
+	e.NextAttemptAt = time.Now()
+	return 1, nil
+}
+
+func (m *MockOutboxRepository) Status(ctx context.Context) (*models.ReplicationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var s models.ReplicationStatus
+	for _, e := range m.Events {
+		switch e.Status {
+		case models.OutboxPending:
+			s.Pending++
+			if s.OldestPendingAt == nil || e.CreatedAt.Before(*s.OldestPendingAt) {
+				t := e.CreatedAt
+				s.OldestPendingAt = &t
+			}
+		case models.OutboxDead:
+			s.Dead++
+		}
+	}
+	return &s, nil
+}
+
+func (m *MockOutboxRepository) PurgeDone(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, e := range m.Events {
+		if e.Status == models.OutboxDone && e.ProcessedAt != nil && e.ProcessedAt.Before(before) {
+			delete(m.Events, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockOutboxRepository) TryLock(ctx context.Context) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Locked {
+		return nil, false, nil
+	}
+	m.Locked = true
+	return func() {
+		m.mu.Lock()
+		m.Locked = false
+		m.mu.Unlock()
+	}, true, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// MockPekerjaanRepository = PekerjaanRepositoryInterface di memori, termasuk soft delete / trash
+type MockPekerjaanRepository struct {
+	Data   map[int]*models.PekerjaanAlumni
+	nextID int
+}
+
+func NewMockPekerjaanRepository() *MockPekerjaanRepository {
+	return &MockPekerjaanRepository{
+		Data: make(map[int]*models.PekerjaanAlumni),
+	}
+}
+
+func (m *MockPekerjaanRepository) sorted() []models.PekerjaanAlumni {
+	list := make([]models.PekerjaanAlumni, 0, len(m.Data))
+	for _, p := range m.Data {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// where mengembalikan salinan data yang lolos filter, urut id
+func (m *MockPekerjaanRepository) where(keep func(p models.PekerjaanAlumni) bool) []models.PekerjaanAlumni {
+	list := []models.PekerjaanAlumni{}
+	for _, p := range m.sorted() {
+		if keep(p) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+func (m *MockPekerjaanRepository) active(search string) []models.PekerjaanAlumni {
+	return m.where(func(p models.PekerjaanAlumni) bool {
+		return !p.IsDeleted && (containsFold(p.NamaPerusahaan, search) || containsFold(p.PosisiJabatan, search))
+	})
+}
+
+func (m *MockPekerjaanRepository) ListPekerjaanRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.PekerjaanAlumni, error) {
+	list := m.active(search)
+	field := sanitizePekerjaanSort(sortBy)
+	less := func(a, b models.PekerjaanAlumni) bool {
+		switch field {
+		case "alumni_id":
+			return a.AlumniID < b.AlumniID
+		case "nama_perusahaan":
+			return a.NamaPerusahaan < b.NamaPerusahaan
+		case "posisi_jabatan":
+			return a.PosisiJabatan < b.PosisiJabatan
+		case "tanggal_mulai_kerja":
+			return a.TanggalMulaiKerja.Before(b.TanggalMulaiKerja)
+		}
+		return a.ID < b.ID
+	}
+	desc := sanitizeOrderPekerjaan(order) == "DESC"
+	// ORDER BY <field> <order>, id ASC
+	sort.SliceStable(list, func(i, j int) bool {
+		if desc {
+			return less(list[j], list[i])
+		}
+		return less(list[i], list[j])
+	})
+	lo, hi := window(len(list), limit, offset)
+	return list[lo:hi], nil
+}
+
+func (m *MockPekerjaanRepository) CountPekerjaanRepo(ctx context.Context, search string) (int, error) {
+	return len(m.active(search)), nil
+}
+
+func (m *MockPekerjaanRepository) GetAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+	return m.active(""), nil
+}
+
+// GetPekerjaanByID sama dengan aslinya: data di trash tetap bisa diambil
+func (m *MockPekerjaanRepository) GetPekerjaanByID(ctx context.Context, id int) (*models.PekerjaanAlumni, error) {
+	p, ok := m.Data[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (m *MockPekerjaanRepository) GetPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error) {
+	list := m.where(func(p models.PekerjaanAlumni) bool { return p.AlumniID == alumniID })
+	sort.SliceStable(list, func(i, j int) bool { return list[i].TanggalMulaiKerja.After(list[j].TanggalMulaiKerja) })
+	return list, nil
+}
+
+func (m *MockPekerjaanRepository) CreatePekerjaan(ctx context.Context, p *models.PekerjaanAlumni) (int, error) {
+	m.nextID++
+	cp := *p
+	cp.ID, cp.Version = m.nextID, 1
+	cp.CreatedAt, cp.UpdatedAt = time.Now(), time.Now()
+	cp.IsDeleted, cp.DeletedAt, cp.DeletedBy = false, nil, ""
+	m.Data[cp.ID] = &cp
+	return cp.ID, nil
+}
+
+func (m *MockPekerjaanRepository) UpdatePekerjaan(ctx context.Context, id int, p *models.PekerjaanAlumni, version int) (int64, error) {
+	cur, ok := m.Data[id]
+	if !ok || !versionMatches(cur.Version, version) {
+		return 0, nil
+	}
+	cur.NamaPerusahaan, cur.PosisiJabatan, cur.BidangIndustri, cur.LokasiKerja = p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja
+	cur.GajiRange, cur.TanggalMulaiKerja, cur.TanggalSelesaiKerja = p.GajiRange, p.TanggalMulaiKerja, p.TanggalSelesaiKerja
+	cur.StatusPekerjaan, cur.DeskripsiPekerjaan, cur.UpdatedAt = p.StatusPekerjaan, p.DeskripsiPekerjaan, time.Now()
+	cur.Version++
+	return 1, nil
+}
+
+func (m *MockPekerjaanRepository) SoftDeletePekerjaan(ctx context.Context, id int, deletedBy int, version int) (int64, error) {
+	p, ok := m.Data[id]
+	if !ok || p.IsDeleted || !versionMatches(p.Version, version) {
+		return 0, nil
+	}
+	now := time.Now()
+	p.IsDeleted, p.DeletedAt, p.DeletedBy = true, &now, strconv.Itoa(deletedBy)
+	p.Version++
+	return 1, nil
+}
+
+func (m *MockPekerjaanRepository) trash(keep func(p models.PekerjaanAlumni) bool) []models.PekerjaanAlumni {
+	return m.where(func(p models.PekerjaanAlumni) bool { return p.IsDeleted && keep(p) })
+}
+
+func (m *MockPekerjaanRepository) TrashAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+	return m.trash(func(models.PekerjaanAlumni) bool { return true }), nil
+}
+
+func (m *MockPekerjaanRepository) TrashPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error) {
+	return m.trash(func(p models.PekerjaanAlumni) bool { return p.AlumniID == alumniID }), nil
+}
+
+func (m *MockPekerjaanRepository) IsPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
+	p, ok := m.Data[pekerjaanID]
+	return ok && p.AlumniID == alumniID, nil
+}
+
+func (m *MockPekerjaanRepository) RestorePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	p, ok := m.Data[id]
+	if !ok || !versionMatches(p.Version, version) {
+		return 0, nil
+	}
+	p.IsDeleted, p.DeletedAt, p.DeletedBy = false, nil, ""
+	p.Version++
+	return 1, nil
+}
+
+func (m *MockPekerjaanRepository) HardDeletePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	p, ok := m.Data[id]
+	if !ok || !p.IsDeleted || !versionMatches(p.Version, version) {
+		return 0, nil
+	}
+	delete(m.Data, id)
+	return 1, nil
+}
+
+func (m *MockPekerjaanRepository) IsTrashedPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
+	p, ok := m.Data[pekerjaanID]
+	return ok && p.IsDeleted && p.AlumniID == alumniID, nil
+}
+
+func (m *MockPekerjaanRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, p := range m.Data {
+		if p.IsDeleted && p.DeletedAt != nil && p.DeletedAt.Before(before) {
+			delete(m.Data, id)
+			n++
+		}
+	}
+	return n, nil
+}
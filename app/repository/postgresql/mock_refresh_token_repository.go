@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// MockRefreshTokenRepository = RefreshTokenRepositoryInterface di memori, sekaligus
+// middleware.SessionStore supaya Auth.Required bisa menolak sesi yang sudah dicabut.
+type MockRefreshTokenRepository struct {
+	Tokens   map[int]*models.RefreshToken
+	Sessions map[string]*models.Session
+	nextID   int
+}
+
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		Tokens:   make(map[int]*models.RefreshToken),
+		Sessions: make(map[string]*models.Session),
+	}
+}
+
+func (m *MockRefreshTokenRepository) insert(t *models.RefreshToken) {
+	m.nextID++
+	t.ID, t.CreatedAt = m.nextID, time.Now()
+	cp := *t
+	m.Tokens[t.ID] = &cp
+}
+
+func (m *MockRefreshTokenRepository) StartSession(ctx context.Context, sess *models.Session, t *models.RefreshToken) error {
+	now := time.Now()
+	sess.CreatedAt, sess.LastSeenAt = now, now
+	cp := *sess
+	m.Sessions[sess.ID] = &cp
+	m.insert(t)
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	for _, t := range m.Tokens {
+		if t.TokenHash == hash {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, oldID int, next *models.RefreshToken, meta models.SessionMeta) error {
+	old, ok := m.Tokens[oldID]
+	if !ok || old.UsedAt != nil || old.RevokedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	now := time.Now()
+	old.UsedAt = &now
+	m.insert(next)
+	if s, ok := m.Sessions[next.FamilyID]; ok {
+		s.ExpiresAt, s.LastSeenAt, s.IP, s.UserAgent = next.ExpiresAt, now, meta.IP, meta.UserAgent
+	}
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) revoke(match func(t *models.RefreshToken) bool, matchSession func(s *models.Session) bool) {
+	now := time.Now()
+	for _, t := range m.Tokens {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &now
+		}
+	}
+	for _, s := range m.Sessions {
+		if s.RevokedAt == nil && matchSession(s) {
+			s.RevokedAt = &now
+		}
+	}
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	m.revoke(func(t *models.RefreshToken) bool { return t.FamilyID == familyID },
+		func(s *models.Session) bool { return s.ID == familyID })
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, backend, userID string) error {
+	m.revoke(func(t *models.RefreshToken) bool { return t.Backend == backend && t.UserID == userID },
+		func(s *models.Session) bool { return s.Backend == backend && s.UserID == userID })
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeOthersForUser(ctx context.Context, backend, userID, keepFamilyID string) error {
+	m.revoke(func(t *models.RefreshToken) bool {
+		return t.Backend == backend && t.UserID == userID && t.FamilyID != keepFamilyID
+	}, func(s *models.Session) bool {
+		return s.Backend == backend && s.UserID == userID && s.ID != keepFamilyID
+	})
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) SessionActivity(ctx context.Context, id string) (bool, time.Time, error) {
+	s, ok := m.Sessions[id]
+	if !ok {
+		return true, time.Time{}, nil
+	}
+	return s.RevokedAt != nil, s.LastSeenAt, nil
+}
+
+func (m *MockRefreshTokenRepository) TouchSession(ctx context.Context, id string, meta models.SessionMeta) error {
+	if s, ok := m.Sessions[id]; ok {
+		s.LastSeenAt, s.IP, s.UserAgent = time.Now(), meta.IP, meta.UserAgent
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "context"
+
+// MockUnitOfWork menjalankan fn tanpa transaksi sungguhan dan mencatat hasilnya.
+// Data mock tidak di-rollback; test cukup memeriksa Rollbacks.
+type MockUnitOfWork struct {
+	Commits   int
+	Rollbacks int
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		m.Rollbacks++
+		return err
+	}
+	m.Commits++
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go_clean/app/models/postgresql"
+)
+
+// MockUserRepository = UserRepositoryInterface di memori. Role divalidasi terhadap
+// models.DefaultRolePermissions (pengganti tabel roles).
+type MockUserRepository struct {
+	Data map[int]*models.User
+	// Alumni opsional: kalau diisi, SetAlumniID menolak alumni yang tidak ada
+	Alumni *MockAlumniRepository
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{
+		Data: make(map[int]*models.User),
+	}
+}
+
+// InsertUser menyimpan user apa adanya (id dari pemanggil)
+func (m *MockUserRepository) InsertUser(u *models.User) {
+	cp := *u
+	m.Data[u.ID] = &cp
+}
+
+func (m *MockUserRepository) filter(search string) []models.User {
+	var list []models.User
+	for _, u := range m.Data {
+		if containsFold(u.Username, search) || containsFold(u.Email, search) {
+			list = append(list, *u)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (m *MockUserRepository) GetUsersRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.User, error) {
+	list := m.filter(search)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch sortBy {
+		case "username":
+			return a.Username < b.Username
+		case "email":
+			return a.Email < b.Email
+		case "role":
+			return a.Role < b.Role
+		}
+		return a.ID < b.ID
+	})
+	if strings.EqualFold(order, "desc") {
+		reverse(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+	}
+	lo, hi := window(len(list), limit, offset)
+	return list[lo:hi], nil
+}
+
+func (m *MockUserRepository) CountUsersRepo(ctx context.Context, search string) (int, error) {
+	return len(m.filter(search)), nil
+}
+
+func (m *MockUserRepository) ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error) {
+	for _, u := range m.Data {
+		if strings.EqualFold(u.Username, username) || strings.EqualFold(u.Email, email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Create meniru AuthRepository.Create: role harus ada, id berikutnya = id terbesar + 1
+func (m *MockUserRepository) Create(ctx context.Context, username, email, passwordHash, role string, emailVerified bool) (*models.User, error) {
+	role = models.NormalizeRole(strings.ToLower(role))
+	if _, ok := models.DefaultRolePermissions[role]; !ok {
+		return nil, ErrInvalidRole
+	}
+	id := 1
+	for existing := range m.Data {
+		if existing >= id {
+			id = existing + 1
+		}
+	}
+	u := &models.User{ID: id, Username: username, Email: email, Role: role, EmailVerified: emailVerified}
+	m.InsertUser(u)
+	return u, nil
+}
+
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	u, ok := m.Data[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id int) (int64, error) {
+	u, ok := m.Data[id]
+	if !ok {
+		return 0, nil
+	}
+	u.EmailVerified = true
+	return 1, nil
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id int, role string) (int64, error) {
+	role = models.NormalizeRole(strings.ToLower(role))
+	if _, ok := models.DefaultRolePermissions[role]; !ok {
+		return 0, ErrInvalidRole
+	}
+	u, ok := m.Data[id]
+	if !ok {
+		return 0, nil
+	}
+	u.Role = role
+	return 1, nil
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id int, disabled bool) (int64, error) {
+	u, ok := m.Data[id]
+	if !ok {
+		return 0, nil
+	}
+	u.Disabled = disabled
+	return 1, nil
+}
+
+func (m *MockUserRepository) SetAlumniID(ctx context.Context, id int, alumniID *int) (int64, error) {
+	if alumniID != nil && m.Alumni != nil {
+		if _, ok := m.Alumni.Data[*alumniID]; !ok {
+			return 0, ErrAlumniNotFound
+		}
+	}
+	u, ok := m.Data[id]
+	if !ok {
+		return 0, nil
+	}
+	u.AlumniID = alumniID
+	return 1, nil
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id int) (int64, error) {
+	if _, ok := m.Data[id]; !ok {
+		return 0, nil
+	}
+	delete(m.Data, id)
+	return 1, nil
+}
+
+func (m *MockUserRepository) AccountState(ctx context.Context, backend, userID string) (models.AccountState, error) {
+	if backend != models.BackendPostgres {
+		return models.AccountState{}, nil
+	}
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return models.AccountState{}, nil
+	}
+	u, ok := m.Data[id]
+	if !ok {
+		return models.AccountState{Known: true, Disabled: true}, nil
+	}
+	return models.AccountState{Known: true, Disabled: u.Disabled, Role: u.Role, EmailVerified: u.EmailVerified}, nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type OIDCRepositoryInterface interface {
+	SaveState(ctx context.Context, st *models.OIDCLoginState) error
+	ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	FindLink(ctx context.Context, issuer, subject string) (*models.OIDCLink, error)
+	SaveLink(ctx context.Context, l *models.OIDCLink) error
+	TouchLink(ctx context.Context, issuer, subject, email string) error
+}
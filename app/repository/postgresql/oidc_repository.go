@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go_clean/app/models/postgresql"
+)
+
+type OIDCRepository struct {
+	DB *sql.DB
+}
+
+func (r *OIDCRepository) SaveState(ctx context.Context, st *models.OIDCLoginState) error {
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, nonce, verifier, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, st.StateHash, st.Nonce, st.Verifier, st.ExpiresAt)
+	return err
+}
+
+// ConsumeState mengambil sekaligus menghapus state yang belum kedaluwarsa (sql.ErrNoRows kalau tidak ada)
+func (r *OIDCRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	var st models.OIDCLoginState
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND expires_at > NOW()
+		RETURNING state_hash, nonce, verifier, expires_at
+	`, stateHash).Scan(&st.StateHash, &st.Nonce, &st.Verifier, &st.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	// sekalian buang state lama yang tidak pernah diselesaikan
+	_, _ = conn(ctx, r.DB).ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < NOW() - INTERVAL '1 day'`)
+	return &st, nil
+}
+
+func (r *OIDCRepository) FindLink(ctx context.Context, issuer, subject string) (*models.OIDCLink, error) {
+	var l models.OIDCLink
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT issuer, subject, backend, user_id, email, created_at, last_login_at
+		FROM oidc_identities
+		WHERE issuer = $1 AND subject = $2
+	`, issuer, subject).Scan(&l.Issuer, &l.Subject, &l.Backend, &l.UserID, &l.Email, &l.CreatedAt, &l.LastLoginAt)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *OIDCRepository) SaveLink(ctx context.Context, l *models.OIDCLink) error {
+	return conn(ctx, r.DB).QueryRowContext(ctx, `
+		INSERT INTO oidc_identities (issuer, subject, backend, user_id, email, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at, last_login_at
+	`, l.Issuer, l.Subject, l.Backend, l.UserID, l.Email).Scan(&l.CreatedAt, &l.LastLoginAt)
+}
+
+func (r *OIDCRepository) TouchLink(ctx context.Context, issuer, subject, email string) error {
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE oidc_identities SET last_login_at = NOW(), email = $3
+		WHERE issuer = $1 AND subject = $2
+	`, issuer, subject, email)
+	return err
+}
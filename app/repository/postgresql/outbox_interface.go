@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+type OutboxRepositoryInterface interface {
+	Pending(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkDone(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, errMsg string, next time.Time, dead bool) error
+	Dead(ctx context.Context, limit, offset int) ([]models.OutboxEvent, int, error)
+	Requeue(ctx context.Context, id int64) (int64, error)
+	Status(ctx context.Context) (*models.ReplicationStatus, error)
+	PurgeDone(ctx context.Context, before time.Time) (int64, error)
+	TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
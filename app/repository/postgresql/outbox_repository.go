@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// outboxLockKey = kunci pg_try_advisory_lock supaya hanya satu instance yang menjalankan relay per batch
+const outboxLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// OutboxRepository membaca & memperbarui status event outbox untuk relay.
+// Event-nya sendiri ditulis lewat appendOutbox oleh AlumniRepository / PekerjaanRepository.
+type OutboxRepository struct {
+	DB *sql.DB
+}
+
+// appendOutbox wajib dipanggil di transaksi yang sama dengan perubahan datanya,
+// supaya perubahan yang ter-commit selalu punya event dan yang di-rollback tidak
+func appendOutbox(ctx context.Context, tx DBTX, aggregate string, id int, op string, payload interface{}) error {
+	var data interface{}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox_events (aggregate, aggregate_id, op, payload)
+		VALUES ($1, $2, $3, $4)
+	`, aggregate, id, op, data)
+	return err
+}
+
+const outboxColumns = `id, aggregate, aggregate_id, op, payload, status, attempts, last_error, next_attempt_at, created_at, processed_at`
+
+func scanOutbox(rows *sql.Rows) ([]models.OutboxEvent, error) {
+	defer rows.Close()
+	list := []models.OutboxEvent{}
+	for rows.Next() {
+		var e models.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Aggregate, &e.AggregateID, &e.Op, &payload, &e.Status, &e.Attempts,
+			&e.LastError, &e.NextAttemptAt, &e.CreatedAt, &e.ProcessedAt); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			e.Payload = payload
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Pending mengembalikan event yang sudah waktunya disalin, urut id. Event ditahan (tidak ikut) kalau
+// barisnya masih punya event lebih awal yang menunggu backoff, atau kalau event alumni dan masih ada
+// upsert pekerjaan alumni itu yang lebih awal. Dengan begitu baris yang macet tidak memenuhi batch.
+func (r *OutboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `
+		SELECT `+outboxColumns+` FROM outbox_events e
+		WHERE e.status = 'pending' AND e.next_attempt_at <= $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events x
+			WHERE x.status = 'pending' AND x.id < e.id AND (
+				(x.aggregate = e.aggregate AND x.aggregate_id = e.aggregate_id AND x.next_attempt_at > $1)
+				OR (e.aggregate = 'alumni' AND x.aggregate = 'pekerjaan' AND x.op = 'upsert'
+					AND (x.payload->>'alumni_id')::int = e.aggregate_id)
+			)
+		  )
+		ORDER BY e.id
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutbox(rows)
+}
+
+func (r *OutboxRepository) MarkDone(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE outbox_events SET status = 'done', attempts = attempts + 1, last_error = '', processed_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed mencatat percobaan gagal; dead = pindah ke dead-letter (tidak dicoba lagi otomatis)
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, next time.Time, dead bool) error {
+	status := models.OutboxPending
+	if dead {
+		status = models.OutboxDead
+	}
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE outbox_events SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+		WHERE id = $1
+	`, id, status, errMsg, next)
+	return err
+}
+
+// Dead = daftar dead-letter, terbaru dulu
+func (r *OutboxRepository) Dead(ctx context.Context, limit, offset int) ([]models.OutboxEvent, int, error) {
+	var total int
+	if err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox_events WHERE status = 'dead'`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `
+		SELECT `+outboxColumns+` FROM outbox_events
+		WHERE status = 'dead'
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := scanOutbox(rows)
+	return list, total, err
+}
+
+// Requeue mengembalikan event dead-letter ke antrian dengan hitungan percobaan dari nol
+func (r *OutboxRepository) Requeue(ctx context.Context, id int64) (int64, error) {
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE outbox_events SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+	`, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Status menghitung antrian & umur event pending tertua (lag replikasi)
+func (r *OutboxRepository) Status(ctx context.Context) (*models.ReplicationStatus, error) {
+	var s models.ReplicationStatus
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'dead'),
+			MIN(created_at) FILTER (WHERE status = 'pending')
+		FROM outbox_events
+	`).Scan(&s.Pending, &s.Dead, &s.OldestPendingAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// PurgeDone menghapus event yang sudah tersalin sebelum waktu tertentu
+func (r *OutboxRepository) PurgeDone(ctx context.Context, before time.Time) (int64, error) {
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `DELETE FROM outbox_events WHERE status = 'done' AND processed_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// TryLock mengambil advisory lock relay di satu koneksi; ok=false kalau instance lain sedang memegangnya.
+// unlock wajib dipanggil kalau ok=true.
+func (r *OutboxRepository) TryLock(ctx context.Context) (unlock func(), ok bool, err error) {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, outboxLockKey).Scan(&ok); err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, outboxLockKey)
+		conn.Close()
+	}, true, nil
+}
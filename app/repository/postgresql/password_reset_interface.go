@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type PasswordResetRepositoryInterface interface {
+	Create(ctx context.Context, t *models.PasswordResetToken) error
+	Consume(ctx context.Context, hash string) (*models.PasswordResetToken, error)
+}
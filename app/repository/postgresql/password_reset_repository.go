@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go_clean/app/models/postgresql"
+)
+
+type PasswordResetRepository struct {
+	DB *sql.DB
+}
+
+// Create menyimpan token baru dan menonaktifkan token reset lain milik user yang sama
+func (r *PasswordResetRepository) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE backend = $1 AND user_id = $2 AND used_at IS NULL
+	`, t.Backend, t.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO password_reset_tokens (backend, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, t.Backend, t.UserID, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Consume menandai token terpakai secara atomik. sql.ErrNoRows kalau token tidak ada,
+// sudah dipakai, atau kedaluwarsa.
+func (r *PasswordResetRepository) Consume(ctx context.Context, hash string) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, backend, user_id, token_hash, expires_at, used_at, created_at
+	`, hash).Scan(&t.ID, &t.Backend, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
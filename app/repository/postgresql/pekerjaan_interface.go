@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+type PekerjaanRepositoryInterface interface {
+	ListPekerjaanRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.PekerjaanAlumni, error)
+	CountPekerjaanRepo(ctx context.Context, search string) (int, error)
+	GetAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error)
+	GetPekerjaanByID(ctx context.Context, id int) (*models.PekerjaanAlumni, error)
+	GetPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error)
+	CreatePekerjaan(ctx context.Context, p *models.PekerjaanAlumni) (int, error)
+	UpdatePekerjaan(ctx context.Context, id int, p *models.PekerjaanAlumni, version int) (int64, error)
+	SoftDeletePekerjaan(ctx context.Context, id int, deletedBy int, version int) (int64, error)
+	TrashAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error)
+	TrashPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error)
+	IsPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error)
+	RestorePekerjaanByID(ctx context.Context, id int, version int) (int64, error)
+	HardDeletePekerjaanByID(ctx context.Context, id int, version int) (int64, error)
+	IsTrashedPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
@@ -1,11 +1,11 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"database/sql"
 	"go_clean/app/models/postgresql"
 	"time"
-	"go_clean/database"
 )
 
 type PekerjaanRepository struct {
@@ -38,13 +38,13 @@ func sanitizeOrderPekerjaan(o string) string {
 
 // --- Fungsi utama untuk List & Count (mirip Alumni) ---
 
-func ListPekerjaanRepo(search, sortBy, order string, limit, offset int) ([]models.PekerjaanAlumni, error) {
+func (r *PekerjaanRepository) ListPekerjaanRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.PekerjaanAlumni, error) {
 	sortBy = sanitizePekerjaanSort(sortBy)
 	order = sanitizeOrderPekerjaan(order)
 
 	query := fmt.Sprintf(`
 		SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range,
-			   tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at
+			   tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at, version
 		FROM pekerjaan_alumni
 		WHERE is_delete = FALSE
 		  AND (nama_perusahaan ILIKE $1 OR posisi_jabatan ILIKE $1)
@@ -52,7 +52,7 @@ func ListPekerjaanRepo(search, sortBy, order string, limit, offset int) ([]model
 		LIMIT $2 OFFSET $3
 	`, sortBy, order)
 
-	rows, err := database.DB.Query(query, "%"+search+"%", limit, offset)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, query, "%"+search+"%", limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +64,7 @@ func ListPekerjaanRepo(search, sortBy, order string, limit, offset int) ([]model
 		if err := rows.Scan(
 			&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri,
 			&p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja,
-			&p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt,
+			&p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt, &p.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -73,9 +73,9 @@ func ListPekerjaanRepo(search, sortBy, order string, limit, offset int) ([]model
 	return items, rows.Err()
 }
 
-func CountPekerjaanRepo(search string) (int, error) {
+func (r *PekerjaanRepository) CountPekerjaanRepo(ctx context.Context, search string) (int, error) {
 	var total int
-	err := database.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM pekerjaan_alumni
 		WHERE is_delete = FALSE
@@ -89,8 +89,8 @@ func CountPekerjaanRepo(search string) (int, error) {
 
 
 
-func (r *PekerjaanRepository) GetAllPekerjaan() ([]models.PekerjaanAlumni, error) {
-	rows, err := r.DB.Query("SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at, is_delete FROM pekerjaan_alumni WHERE is_delete = FALSE ORDER BY created_at DESC")
+func (r *PekerjaanRepository) GetAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at, is_delete, version FROM pekerjaan_alumni WHERE is_delete = FALSE ORDER BY created_at DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +99,7 @@ func (r *PekerjaanRepository) GetAllPekerjaan() ([]models.PekerjaanAlumni, error
 	var pekerjaanList []models.PekerjaanAlumni
 	for rows.Next() {
 		var p models.PekerjaanAlumni
-		if err := rows.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt, &p.IsDeleted); err != nil {
+		if err := rows.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt, &p.IsDeleted, &p.Version); err != nil {
 			return nil, err
 		}
 		pekerjaanList = append(pekerjaanList, p)
@@ -107,17 +107,17 @@ func (r *PekerjaanRepository) GetAllPekerjaan() ([]models.PekerjaanAlumni, error
 	return pekerjaanList, nil
 }
 
-func (r *PekerjaanRepository) GetPekerjaanByID(id int) (*models.PekerjaanAlumni, error) {
+func (r *PekerjaanRepository) GetPekerjaanByID(ctx context.Context, id int) (*models.PekerjaanAlumni, error) {
 	var p models.PekerjaanAlumni
-	err := r.DB.QueryRow("SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at FROM pekerjaan_alumni WHERE id = $1", id).Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt)
+	err := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at, version FROM pekerjaan_alumni WHERE id = $1", id).Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt, &p.Version)
 	if err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
-func (r *PekerjaanRepository) GetPekerjaanByAlumniID(alumniID int) ([]models.PekerjaanAlumni, error) {
-	rows, err := r.DB.Query("SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at FROM pekerjaan_alumni WHERE alumni_id = $1 ORDER BY tanggal_mulai_kerja DESC", alumniID)
+func (r *PekerjaanRepository) GetPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at, version FROM pekerjaan_alumni WHERE alumni_id = $1 ORDER BY tanggal_mulai_kerja DESC", alumniID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +126,7 @@ func (r *PekerjaanRepository) GetPekerjaanByAlumniID(alumniID int) ([]models.Pek
 	var pekerjaanList []models.PekerjaanAlumni
 	for rows.Next() {
 		var p models.PekerjaanAlumni
-		if err := rows.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt, &p.Version); err != nil {
 			return nil, err
 		}
 		pekerjaanList = append(pekerjaanList, p)
@@ -134,52 +134,125 @@ func (r *PekerjaanRepository) GetPekerjaanByAlumniID(alumniID int) ([]models.Pek
 	return pekerjaanList, nil
 }
 
-func (r *PekerjaanRepository) CreatePekerjaan(p *models.PekerjaanAlumni) (int, error) {
-	var id int
-	err := r.DB.QueryRow(
-		`INSERT INTO pekerjaan_alumni (alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
-		p.AlumniID, p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja, p.GajiRange, p.TanggalMulaiKerja, p.TanggalSelesaiKerja, p.StatusPekerjaan, p.DeskripsiPekerjaan, time.Now(), time.Now(),
-	).Scan(&id)
-	return id, err
+// pekerjaanReturning = kolom lengkap untuk payload outbox (RETURNING setelah INSERT / UPDATE)
+const pekerjaanReturning = `RETURNING id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range,
+	tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at,
+	is_delete, deleted_at, deleted_by, version`
+
+func scanPekerjaanRow(row *sql.Row, p *models.PekerjaanAlumni) error {
+	return row.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange,
+		&p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt,
+		&p.IsDeleted, &p.DeletedAt, &p.DeletedBy, &p.Version)
+}
+
+// writePekerjaan menjalankan INSERT / UPDATE ... RETURNING lalu menulis event outbox di transaksi yang sama.
+// Baris tidak ditemukan (UPDATE 0 baris) dikembalikan sebagai sql.ErrNoRows tanpa event.
+func (r *PekerjaanRepository) writePekerjaan(ctx context.Context, query string, args ...interface{}) (*models.PekerjaanAlumni, error) {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var p models.PekerjaanAlumni
+	if err := scanPekerjaanRow(tx.QueryRowContext(ctx, query+" "+pekerjaanReturning, args...), &p); err != nil {
+		return nil, err
+	}
+	if err := appendOutbox(ctx, tx, models.OutboxAggregatePekerjaan, p.ID, models.OutboxOpUpsert, p); err != nil {
+		return nil, err
+	}
+	return &p, tx.Commit()
+}
+
+// deletePekerjaan menghapus baris yang cocok dan menulis event delete per id di transaksi yang sama
+func (r *PekerjaanRepository) deletePekerjaan(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query+" RETURNING id", args...)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := appendOutbox(ctx, tx, models.OutboxAggregatePekerjaan, id, models.OutboxOpDelete, nil); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), tx.Commit()
 }
 
-func (r *PekerjaanRepository) UpdatePekerjaan(id int, p *models.PekerjaanAlumni) (int64, error) {
-	result, err := r.DB.Exec(
-		`UPDATE pekerjaan_alumni SET nama_perusahaan = $1, posisi_jabatan = $2, bidang_industri = $3, lokasi_kerja = $4, gaji_range = $5, tanggal_mulai_kerja = $6, tanggal_selesai_kerja = $7, status_pekerjaan = $8, deskripsi_pekerjaan = $9, updated_at = $10 
-		 WHERE id = $11`,
-		p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja, p.GajiRange, p.TanggalMulaiKerja, p.TanggalSelesaiKerja, p.StatusPekerjaan, p.DeskripsiPekerjaan, time.Now(), id,
+// rowsOf mengubah hasil writePekerjaan menjadi jumlah baris seperti RowsAffected
+func rowsOf(err error) (int64, error) {
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *PekerjaanRepository) CreatePekerjaan(ctx context.Context, p *models.PekerjaanAlumni) (int, error) {
+	created, err := r.writePekerjaan(ctx,
+		`INSERT INTO pekerjaan_alumni (alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		p.AlumniID, p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja, p.GajiRange, p.TanggalMulaiKerja, p.TanggalSelesaiKerja, p.StatusPekerjaan, p.DeskripsiPekerjaan, time.Now(), time.Now(),
 	)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return created.ID, nil
+}
+
+// UpdatePekerjaan, SoftDeletePekerjaan, RestorePekerjaanByID dan HardDeletePekerjaanByID hanya mengenai baris
+// yang version-nya sama dengan version (0 = tanpa syarat); 0 baris = id tidak ada atau sudah diubah orang lain.
+func (r *PekerjaanRepository) UpdatePekerjaan(ctx context.Context, id int, p *models.PekerjaanAlumni, version int) (int64, error) {
+	_, err := r.writePekerjaan(ctx,
+		`UPDATE pekerjaan_alumni SET nama_perusahaan = $1, posisi_jabatan = $2, bidang_industri = $3, lokasi_kerja = $4, gaji_range = $5, tanggal_mulai_kerja = $6, tanggal_selesai_kerja = $7, status_pekerjaan = $8, deskripsi_pekerjaan = $9, updated_at = $10, version = version + 1
+		 WHERE id = $11 AND ($12 < 0 OR version = $12)`,
+		p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja, p.GajiRange, p.TanggalMulaiKerja, p.TanggalSelesaiKerja, p.StatusPekerjaan, p.DeskripsiPekerjaan, time.Now(), id, version,
+	)
+	return rowsOf(err)
 }
 
-func (r *PekerjaanRepository) SoftDeletePekerjaan(id int, deletedBy int) (int64, error) {
+func (r *PekerjaanRepository) SoftDeletePekerjaan(ctx context.Context, id int, deletedBy int, version int) (int64, error) {
 	now := time.Now()
 	query := `
         UPDATE pekerjaan_alumni
         SET is_delete = TRUE,
             deleted_at = $1,
-            deleted_by = $2
-        WHERE id = $3 AND is_delete = FALSE
+            deleted_by = $2,
+            version = version + 1
+        WHERE id = $3 AND is_delete = FALSE AND ($4 < 0 OR version = $4)
     `
-	result, err := r.DB.Exec(query, now, deletedBy, id)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected()
+	_, err := r.writePekerjaan(ctx, query, now, deletedBy, id, version)
+	return rowsOf(err)
 }
 
 
 // Untuk admin
-func (r *PekerjaanRepository) TrashAllPekerjaan() ([]models.PekerjaanAlumni, error) {
-    rows, err := r.DB.Query(`
+func (r *PekerjaanRepository) TrashAllPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+    rows, err := conn(ctx, r.DB).QueryContext(ctx, `
         SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri,
                lokasi_kerja, gaji_range, tanggal_mulai_kerja, tanggal_selesai_kerja,
                status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at,
-               is_delete, deleted_at, deleted_by
+               is_delete, deleted_at, deleted_by, version
         FROM pekerjaan_alumni
         WHERE is_delete = true
         ORDER BY deleted_at DESC
@@ -196,7 +269,7 @@ func (r *PekerjaanRepository) TrashAllPekerjaan() ([]models.PekerjaanAlumni, err
             &p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri,
             &p.LokasiKerja, &p.GajiRange, &p.TanggalMulaiKerja, &p.TanggalSelesaiKerja,
             &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt,
-            &p.IsDeleted, &p.DeletedAt, &p.DeletedBy,
+            &p.IsDeleted, &p.DeletedAt, &p.DeletedBy, &p.Version,
         ); err != nil {
             return nil, err
         }
@@ -207,11 +280,11 @@ func (r *PekerjaanRepository) TrashAllPekerjaan() ([]models.PekerjaanAlumni, err
 
 
 // Untuk user
-func (r *PekerjaanRepository) TrashPekerjaanByAlumniID(alumniID int) ([]models.PekerjaanAlumni, error) {
-	rows, err := r.DB.Query(`
+func (r *PekerjaanRepository) TrashPekerjaanByAlumniID(ctx context.Context, alumniID int) ([]models.PekerjaanAlumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `
 		SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range,
 		       tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan,
-		       created_at, updated_at, is_delete
+		       created_at, updated_at, is_delete, version
 		FROM pekerjaan_alumni
 		WHERE is_delete = TRUE AND alumni_id = $1
 		ORDER BY created_at DESC
@@ -229,7 +302,7 @@ func (r *PekerjaanRepository) TrashPekerjaanByAlumniID(alumniID int) ([]models.P
 			&p.BidangIndustri, &p.LokasiKerja, &p.GajiRange,
 			&p.TanggalMulaiKerja, &p.TanggalSelesaiKerja,
 			&p.StatusPekerjaan, &p.DeskripsiPekerjaan,
-			&p.CreatedAt, &p.UpdatedAt, &p.IsDeleted,
+			&p.CreatedAt, &p.UpdatedAt, &p.IsDeleted, &p.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -240,9 +313,9 @@ func (r *PekerjaanRepository) TrashPekerjaanByAlumniID(alumniID int) ([]models.P
 
 
 
-func (r *PekerjaanRepository) IsPekerjaanOwnedByUser(pekerjaanID, alumniID int) (bool, error) {
+func (r *PekerjaanRepository) IsPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
 	var count int
-	err := r.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
 		SELECT COUNT(*) 
 		FROM pekerjaan_alumni 
 		WHERE id = $1 AND alumni_id = $2
@@ -255,28 +328,27 @@ func (r *PekerjaanRepository) IsPekerjaanOwnedByUser(pekerjaanID, alumniID int)
 	return count > 0, nil
 }
 
-func (r *PekerjaanRepository) RestorePekerjaanByID(id int) error {
-	_, err := r.DB.Exec(`
+func (r *PekerjaanRepository) RestorePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	_, err := r.writePekerjaan(ctx, `
 		UPDATE pekerjaan_alumni
-		SET is_delete = FALSE, deleted_at = NULL, deleted_by = ''
-		WHERE id = $1
-	`, id)
-	return err
+		SET is_delete = FALSE, deleted_at = NULL, deleted_by = '', version = version + 1
+		WHERE id = $1 AND ($2 < 0 OR version = $2)
+	`, id, version)
+	return rowsOf(err)
 }
 
 
 
-func (r *PekerjaanRepository) HardDeletePekerjaanByID(id int) error {
-	_, err := r.DB.Exec(`
+func (r *PekerjaanRepository) HardDeletePekerjaanByID(ctx context.Context, id int, version int) (int64, error) {
+	return r.deletePekerjaan(ctx, `
 		DELETE FROM pekerjaan_alumni
-		WHERE id = $1 AND is_delete = TRUE
-	`, id)
-	return err
+		WHERE id = $1 AND is_delete = TRUE AND ($2 < 0 OR version = $2)
+	`, id, version)
 }
 
-func (r *PekerjaanRepository) IsTrashedPekerjaanOwnedByUser(pekerjaanID, alumniID int) (bool, error) {
+func (r *PekerjaanRepository) IsTrashedPekerjaanOwnedByUser(ctx context.Context, pekerjaanID, alumniID int) (bool, error) {
 	var count int
-	err := r.DB.QueryRow(`
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM pekerjaan_alumni
 		WHERE id = $1 AND alumni_id = $2 AND is_delete = TRUE
@@ -287,3 +359,10 @@ func (r *PekerjaanRepository) IsTrashedPekerjaanOwnedByUser(pekerjaanID, alumniI
 	return count > 0, nil
 }
 
+// PurgeTrash menghapus permanen pekerjaan yang sudah di-trash sebelum waktu tertentu
+func (r *PekerjaanRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	return r.deletePekerjaan(ctx, `
+		DELETE FROM pekerjaan_alumni
+		WHERE is_delete = TRUE AND deleted_at < $1
+	`, before)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type RefreshTokenRepositoryInterface interface {
+	StartSession(ctx context.Context, sess *models.Session, t *models.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	Rotate(ctx context.Context, oldID int, next *models.RefreshToken, meta models.SessionMeta) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, backend, userID string) error
+	RevokeOthersForUser(ctx context.Context, backend, userID, keepFamilyID string) error
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go_clean/app/models/postgresql"
+)
+
+// ErrRefreshTokenReused dikembalikan Rotate kalau token lama sudah pernah dipakai / dicabut
+var ErrRefreshTokenReused = errors.New("refresh token sudah dipakai")
+
+type RefreshTokenRepository struct {
+	DB *sql.DB
+}
+
+// StartSession membuat sesi baru beserta refresh token pertamanya dalam satu transaksi
+func (r *RefreshTokenRepository) StartSession(ctx context.Context, sess *models.Session, t *models.RefreshToken) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO sessions (id, backend, user_id, username, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, last_seen_at
+	`, sess.ID, sess.Backend, sess.UserID, sess.Username, sess.UserAgent, sess.IP, sess.ExpiresAt).Scan(&sess.CreatedAt, &sess.LastSeenAt)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (backend, user_id, username, role, email_verified, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, t.Backend, t.UserID, t.Username, t.Role, t.Verified, t.FamilyID, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT id, backend, user_id, username, role, email_verified, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`, hash).Scan(&t.ID, &t.Backend, &t.UserID, &t.Username, &t.Role, &t.Verified, &t.FamilyID, &t.TokenHash,
+		&t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Rotate menandai token lama sebagai terpakai dan menyimpan penggantinya dalam satu transaksi.
+// Kalau token lama ternyata sudah terpakai (race / replay) hasilnya ErrRefreshTokenReused.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID int, next *models.RefreshToken, meta models.SessionMeta) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, oldID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (backend, user_id, username, role, email_verified, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, next.Backend, next.UserID, next.Username, next.Role, next.Verified, next.FamilyID, next.TokenHash, next.ExpiresAt).Scan(&next.ID, &next.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// sesi diperpanjang mengikuti refresh token terbaru
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sessions SET expires_at = $2, last_seen_at = NOW(), ip = $3, user_agent = $4 WHERE id = $1
+	`, next.FamilyID, next.ExpiresAt, meta.IP, meta.UserAgent); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RevokeFamily mencabut satu sesi beserta semua refresh token di family-nya (logout / reuse detection)
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revoke(ctx, `family_id = $1`, `id = $1`, familyID)
+}
+
+// RevokeAllForUser mencabut semua sesi user (ganti password, logout semua perangkat, admin)
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, backend, userID string) error {
+	return r.revoke(ctx, `backend = $1 AND user_id = $2`, `backend = $1 AND user_id = $2`, backend, userID)
+}
+
+// RevokeOthersForUser mencabut semua sesi user kecuali keepFamilyID (sesi yang sedang dipakai)
+func (r *RefreshTokenRepository) RevokeOthersForUser(ctx context.Context, backend, userID, keepFamilyID string) error {
+	return r.revoke(ctx, `backend = $1 AND user_id = $2 AND family_id <> $3`, `backend = $1 AND user_id = $2 AND id <> $3`, backend, userID, keepFamilyID)
+}
+
+func (r *RefreshTokenRepository) revoke(ctx context.Context, tokenWhere, sessionWhere string, args ...interface{}) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = NOW() WHERE revoked_at IS NULL AND `+tokenWhere, args...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = NOW() WHERE revoked_at IS NULL AND `+sessionWhere, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
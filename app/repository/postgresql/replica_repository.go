@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// ReplicaRepository membaca seluruh alumni & pekerjaan dan menulis baris dengan id dari penyimpan lain,
+// dipakai pengecekan konsistensi untuk membandingkan & memperbaiki Postgres dari MongoDB.
+// Setiap tulisan tetap menulis event outbox seperti AlumniRepository / PekerjaanRepository.
+type ReplicaRepository struct {
+	DB *sql.DB
+}
+
+// SnapshotAlumni = semua alumni, urut id
+func (r *ReplicaRepository) SnapshotAlumni(ctx context.Context) ([]models.Alumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT id, nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at, version
+		FROM alumni ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Alumni{}
+	for rows.Next() {
+		var a models.Alumni
+		if err := rows.Scan(&a.ID, &a.NIM, &a.Nama, &a.Jurusan, &a.Angkatan, &a.TahunLulus, &a.Email, &a.NoTelepon, &a.Alamat, &a.CreatedAt, &a.UpdatedAt, &a.Version); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// SnapshotPekerjaan = semua pekerjaan termasuk yang ada di trash, urut id
+func (r *ReplicaRepository) SnapshotPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range,
+		tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at,
+		is_delete, deleted_at, deleted_by, version
+		FROM pekerjaan_alumni ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.PekerjaanAlumni{}
+	for rows.Next() {
+		var p models.PekerjaanAlumni
+		if err := rows.Scan(&p.ID, &p.AlumniID, &p.NamaPerusahaan, &p.PosisiJabatan, &p.BidangIndustri, &p.LokasiKerja, &p.GajiRange,
+			&p.TanggalMulaiKerja, &p.TanggalSelesaiKerja, &p.StatusPekerjaan, &p.DeskripsiPekerjaan, &p.CreatedAt, &p.UpdatedAt,
+			&p.IsDeleted, &p.DeletedAt, &p.DeletedBy, &p.Version); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// syncSequence menaikkan sequence SERIAL setelah INSERT dengan id eksplisit supaya INSERT berikutnya tidak bentrok
+func syncSequence(ctx context.Context, tx DBTX, table string) error {
+	_, err := tx.ExecContext(ctx, `SELECT setval(pg_get_serial_sequence('`+table+`', 'id'), GREATEST((SELECT MAX(id) FROM `+table+`), 1))`)
+	return err
+}
+
+func timeOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// UpsertAlumni menulis alumni dengan id yang sama dengan sumber. Id 0 (dokumen Mongo lama tanpa alumni_id)
+// dibuat sebagai baris baru dengan id dari sequence.
+func (r *ReplicaRepository) UpsertAlumni(ctx context.Context, alumni models.Alumni) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args := []interface{}{alumni.NIM, alumni.Nama, alumni.Jurusan, alumni.Angkatan, alumni.TahunLulus, alumni.Email,
+		alumni.NoTelepon, alumni.Alamat, timeOrNow(alumni.CreatedAt), timeOrNow(alumni.UpdatedAt)}
+	query := `INSERT INTO alumni (nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ` + alumniReturning
+	if alumni.ID > 0 {
+		args = append(args, alumni.ID)
+		query = `INSERT INTO alumni (nim, nama, jurusan, angkatan, tahun_lulus, email, no_telepon, alamat, created_at, updated_at, id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (id) DO UPDATE SET nim = EXCLUDED.nim, nama = EXCLUDED.nama, jurusan = EXCLUDED.jurusan,
+				angkatan = EXCLUDED.angkatan, tahun_lulus = EXCLUDED.tahun_lulus, email = EXCLUDED.email,
+				no_telepon = EXCLUDED.no_telepon, alamat = EXCLUDED.alamat, updated_at = EXCLUDED.updated_at,
+				version = alumni.version + 1 ` + alumniReturning
+	}
+
+	var a models.Alumni
+	if err := scanAlumniRow(tx.QueryRowContext(ctx, query, args...), &a); err != nil {
+		return err
+	}
+	if alumni.ID > 0 {
+		if err := syncSequence(ctx, tx, "alumni"); err != nil {
+			return err
+		}
+	}
+	if err := appendOutbox(ctx, tx, models.OutboxAggregateAlumni, a.ID, models.OutboxOpUpsert, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteAlumni ikut menghapus pekerjaan alumni tersebut (ON DELETE CASCADE)
+func (r *ReplicaRepository) DeleteAlumni(ctx context.Context, id int) error {
+	_, err := (&AlumniRepository{DB: r.DB}).DeleteAlumni(ctx, id, models.AnyVersion)
+	return err
+}
+
+// UpsertPekerjaan menulis semua kolom termasuk status trash, dengan id yang sama dengan sumber
+func (r *ReplicaRepository) UpsertPekerjaan(ctx context.Context, p models.PekerjaanAlumni) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var saved models.PekerjaanAlumni
+	err = scanPekerjaanRow(tx.QueryRowContext(ctx, `
+		INSERT INTO pekerjaan_alumni (id, alumni_id, nama_perusahaan, posisi_jabatan, bidang_industri, lokasi_kerja, gaji_range,
+			tanggal_mulai_kerja, tanggal_selesai_kerja, status_pekerjaan, deskripsi_pekerjaan, created_at, updated_at,
+			is_delete, deleted_at, deleted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (id) DO UPDATE SET alumni_id = EXCLUDED.alumni_id, nama_perusahaan = EXCLUDED.nama_perusahaan,
+			posisi_jabatan = EXCLUDED.posisi_jabatan, bidang_industri = EXCLUDED.bidang_industri,
+			lokasi_kerja = EXCLUDED.lokasi_kerja, gaji_range = EXCLUDED.gaji_range,
+			tanggal_mulai_kerja = EXCLUDED.tanggal_mulai_kerja, tanggal_selesai_kerja = EXCLUDED.tanggal_selesai_kerja,
+			status_pekerjaan = EXCLUDED.status_pekerjaan, deskripsi_pekerjaan = EXCLUDED.deskripsi_pekerjaan,
+			updated_at = EXCLUDED.updated_at, is_delete = EXCLUDED.is_delete, deleted_at = EXCLUDED.deleted_at,
+			deleted_by = EXCLUDED.deleted_by, version = pekerjaan_alumni.version + 1 `+pekerjaanReturning,
+		p.ID, p.AlumniID, p.NamaPerusahaan, p.PosisiJabatan, p.BidangIndustri, p.LokasiKerja, p.GajiRange,
+		p.TanggalMulaiKerja, p.TanggalSelesaiKerja, p.StatusPekerjaan, p.DeskripsiPekerjaan,
+		timeOrNow(p.CreatedAt), timeOrNow(p.UpdatedAt), p.IsDeleted, p.DeletedAt, p.DeletedBy,
+	), &saved)
+	if err != nil {
+		return err
+	}
+	if err := syncSequence(ctx, tx, "pekerjaan_alumni"); err != nil {
+		return err
+	}
+	if err := appendOutbox(ctx, tx, models.OutboxAggregatePekerjaan, saved.ID, models.OutboxOpUpsert, saved); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ReplicaRepository) DeletePekerjaan(ctx context.Context, id int) error {
+	_, err := (&PekerjaanRepository{DB: r.DB}).deletePekerjaan(ctx, "DELETE FROM pekerjaan_alumni WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type RoleRepositoryInterface interface {
+	RolePermissions(ctx context.Context, role string) ([]string, error)
+	List(ctx context.Context) ([]models.Role, error)
+	Get(ctx context.Context, name string) (*models.Role, error)
+	Exists(ctx context.Context, name string) (bool, error)
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, name string) (int64, error)
+	CountUsers(ctx context.Context, name string) (int, error)
+	EnsureSystemRole(ctx context.Context, name, description string, perms []string) (bool, error)
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"go_clean/app/models/postgresql"
+)
+
+type RoleRepository struct {
+	DB *sql.DB
+}
+
+// RolePermissions dipakai middleware.Require (lewat cache)
+func (r *RoleRepository) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := []string{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]models.Role, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `
+		SELECT name, description, is_system, created_at, updated_at
+		FROM roles
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.System, &role.CreatedAt, &role.UpdatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		if roles[i].Permissions, err = r.RolePermissions(ctx, roles[i].Name); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) Get(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT name, description, is_system, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`, name).Scan(&role.Name, &role.Description, &role.System, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if role.Permissions, err = r.RolePermissions(ctx, name); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) Exists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, name).Scan(&exists)
+	return exists, err
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role *models.Role) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO roles (name, description) VALUES ($1, $2)
+		RETURNING is_system, created_at, updated_at
+	`, role.Name, role.Description).Scan(&role.System, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := setRolePermissions(ctx, tx, role.Name, role.Permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update mengganti deskripsi dan seluruh permission role
+func (r *RoleRepository) Update(ctx context.Context, role *models.Role) error {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE roles SET description = $2, updated_at = NOW()
+		WHERE name = $1
+		RETURNING is_system, created_at, updated_at
+	`, role.Name, role.Description).Scan(&role.System, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role = $1`, role.Name); err != nil {
+		return err
+	}
+	if err := setRolePermissions(ctx, tx, role.Name, role.Permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete hanya untuk role non-system; role_permissions ikut terhapus (ON DELETE CASCADE)
+func (r *RoleRepository) Delete(ctx context.Context, name string) (int64, error) {
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `DELETE FROM roles WHERE name = $1 AND is_system = FALSE`, name)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CountUsers menghitung user Postgres yang masih memakai role
+func (r *RoleRepository) CountUsers(ctx context.Context, name string) (int, error) {
+	var n int
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role = $1`, name).Scan(&n)
+	return n, err
+}
+
+func setRolePermissions(ctx context.Context, tx DBTX, role string, perms []string) error {
+	for _, p := range perms {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO role_permissions (role, permission) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, role, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureSystemRole membuat role bawaan beserta permission default kalau belum ada.
+// Role yang sudah ada tidak diubah supaya permission hasil edit admin tetap.
+func (r *RoleRepository) EnsureSystemRole(ctx context.Context, name, description string, perms []string) (bool, error) {
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO roles (name, description, is_system) VALUES ($1, $2, TRUE)
+		ON CONFLICT (name) DO NOTHING
+	`, name, description)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := setRolePermissions(ctx, tx, name, perms); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+type SessionRepositoryInterface interface {
+	Get(ctx context.Context, id string) (*models.Session, error)
+	ListActive(ctx context.Context, backend, userID string) ([]models.Session, error)
+	SessionActivity(ctx context.Context, id string) (bool, time.Time, error)
+	TouchSession(ctx context.Context, id string, meta models.SessionMeta) error
+}
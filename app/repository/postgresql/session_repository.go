@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_clean/app/models/postgresql"
+)
+
+// SessionRepository membaca tabel sessions. Sesi dibuat lewat RefreshTokenRepository.StartSession
+// dan dicabut lewat RevokeFamily / RevokeAllForUser supaya refresh token ikut mati.
+type SessionRepository struct {
+	DB *sql.DB
+}
+
+const sessionColumns = `id, backend, user_id, username, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(...interface{}) error }) (*models.Session, error) {
+	var s models.Session
+	err := row.Scan(&s.ID, &s.Backend, &s.UserID, &s.Username, &s.UserAgent, &s.IP,
+		&s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SessionRepository) Get(ctx context.Context, id string) (*models.Session, error) {
+	return scanSession(conn(ctx, r.DB).QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id))
+}
+
+// ListActive mengembalikan sesi yang belum dicabut dan belum expired, terbaru dulu
+func (r *SessionRepository) ListActive(ctx context.Context, backend, userID string) ([]models.Session, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE backend = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, backend, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// SessionActivity dipakai Auth.Required. Sesi yang tidak ada dianggap dicabut.
+func (r *SessionRepository) SessionActivity(ctx context.Context, id string) (bool, time.Time, error) {
+	var revoked bool
+	var lastSeen time.Time
+	err := conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT revoked_at IS NOT NULL, last_seen_at FROM sessions WHERE id = $1
+	`, id).Scan(&revoked, &lastSeen)
+	if err == sql.ErrNoRows {
+		return true, time.Time{}, nil
+	}
+	return revoked, lastSeen, err
+}
+
+// TouchSession mencatat aktivitas terakhir beserta IP / user agent terbaru
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, meta models.SessionMeta) error {
+	_, err := conn(ctx, r.DB).ExecContext(ctx, `
+		UPDATE sessions SET last_seen_at = NOW(), ip = $2, user_agent = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, meta.IP, meta.UserAgent)
+	return err
+}
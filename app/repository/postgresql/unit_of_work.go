@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DBTX = method yang sama di *sql.DB dan *sql.Tx; query repository dijalankan lewat conn(ctx, r.DB)
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// UnitOfWork menjalankan beberapa operasi repository Postgres dalam satu transaksi. Transaksi dibawa
+// lewat context: repository apa pun yang dipanggil dengan ctx dari fn otomatis ikut transaksi yang sama,
+// termasuk yang biasanya membuka transaksi sendiri (misal AlumniRepository + event outbox-nya).
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+// Do commit kalau fn berhasil, rollback kalau fn mengembalikan error atau panic.
+// Do di dalam Do ikut transaksi luar (tidak ada nested transaction / savepoint).
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn mengembalikan transaksi UnitOfWork di ctx kalau ada, selain itu db
+func conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// Tx = transaksi yang dibuka repository. Di dalam UnitOfWork, Commit / Rollback diserahkan ke UnitOfWork.
+type Tx struct {
+	DBTX
+	tx *sql.Tx
+}
+
+// beginTx membuka transaksi baru, atau ikut transaksi UnitOfWork di ctx
+func beginTx(ctx context.Context, db *sql.DB) (*Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return &Tx{DBTX: tx}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{DBTX: tx, tx: tx}, nil
+}
+
+func (t *Tx) Commit() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Rollback()
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"go_clean/app/models/postgresql"
+)
+
+type UserRepositoryInterface interface {
+	GetUsersRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.User, error)
+	CountUsersRepo(ctx context.Context, search string) (int, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	MarkEmailVerified(ctx context.Context, id int) (int64, error)
+	UpdateRole(ctx context.Context, id int, role string) (int64, error)
+	SetDisabled(ctx context.Context, id int, disabled bool) (int64, error)
+	SetAlumniID(ctx context.Context, id int, alumniID *int) (int64, error)
+	DeleteUser(ctx context.Context, id int) (int64, error)
+	AccountState(ctx context.Context, backend, userID string) (models.AccountState, error)
+}
@@ -1,9 +1,11 @@
 package repository
 import (
+	"context"
 	"fmt"
 	"log"
 	"database/sql"
 	"errors"
+	"strconv"
 	"strings"	
 	"go_clean/app/models/postgresql"
 )
@@ -12,32 +14,23 @@ type UserRepository struct {
 	DB *sql.DB
 }
 
-func (r *AuthRepository) Create(username, email, passwordHash, role string) (*models.User, error) {
-	role = strings.ToLower(role)
-	if role != "admin" && role != "user" {
-		return nil, errors.New("role tidak valid")
-	}
-	var u models.User
-	err := r.DB.QueryRow(`
-		INSERT INTO users (username, email, password_hash, role)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, username, email, role
-	`, username, email, passwordHash, role).Scan(&u.ID, &u.Username, &u.Email, &u.Role)
-	if err != nil {
-		return nil, err
-	}
-	return &u, nil
-}
-func (r *UserRepository) GetUsersRepo(search, sortBy, order string, limit, offset int) ([]models.User, error) {
+// ErrInvalidRole: role tidak ada di tabel roles
+var ErrInvalidRole = errors.New("role tidak valid")
+
+// ErrAlumniNotFound: alumni_id yang mau ditautkan tidak ada di tabel alumni
+var ErrAlumniNotFound = errors.New("alumni tidak ditemukan")
+
+// GetUsersRepo: sortBy & order wajib sudah di-whitelist service karena masuk ke query apa adanya
+func (r *UserRepository) GetUsersRepo(ctx context.Context, search, sortBy, order string, limit, offset int) ([]models.User, error) {
 	query := fmt.Sprintf(`
-		SELECT id, username, email, created_at
+		SELECT id, username, email, role, alumni_id, email_verified, disabled_at IS NOT NULL
 		FROM users
 		WHERE username ILIKE $1 OR email ILIKE $1
 		ORDER BY %s %s
 		LIMIT $2 OFFSET $3
 	`, sortBy, order)
 
-	rows, err := r.DB.Query(query, "%"+search+"%", limit, offset)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, query, "%"+search+"%", limit, offset)
 	if err != nil {
 		log.Println("Query error:", err)
 		return nil, err
@@ -47,7 +40,7 @@ func (r *UserRepository) GetUsersRepo(search, sortBy, order string, limit, offse
 	var users []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email); err != nil {
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.AlumniID, &u.EmailVerified, &u.Disabled); err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -56,27 +49,112 @@ func (r *UserRepository) GetUsersRepo(search, sortBy, order string, limit, offse
 	return users, nil
 }
 
-func (r *UserRepository) CountUsersRepo(search string) (int, error) {
+func (r *UserRepository) CountUsersRepo(ctx context.Context, search string) (int, error) {
 	var total int
 	countQuery := `SELECT COUNT(*) FROM users WHERE username ILIKE $1 OR email ILIKE $1`
-	err := r.DB.QueryRow(countQuery, "%"+search+"%").Scan(&total)
+	err := conn(ctx, r.DB).QueryRowContext(ctx, countQuery, "%"+search+"%").Scan(&total)
 	if err != nil && err != sql.ErrNoRows {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
     var u models.User
-    err := r.DB.QueryRow(`
-        SELECT id, username, email, role, alumni_id
+    err := conn(ctx, r.DB).QueryRowContext(ctx, `
+        SELECT id, username, email, role, alumni_id, email_verified, disabled_at IS NOT NULL
         FROM users
         WHERE id = $1
     `, id).Scan(
-        &u.ID, &u.Username, &u.Email, &u.Role, &u.AlumniID,
+        &u.ID, &u.Username, &u.Email, &u.Role, &u.AlumniID, &u.EmailVerified, &u.Disabled,
     )
     if err != nil {
         return nil, err
     }
     return &u, nil
-}
\ No newline at end of file
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int) (int64, error) {
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE users SET email_verified = TRUE WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UpdateRole mengganti role user; role harus ada di tabel roles
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) (int64, error) {
+	role = models.NormalizeRole(strings.ToLower(role))
+	var roleExists bool
+	if err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, role).Scan(&roleExists); err != nil {
+		return 0, err
+	}
+	if !roleExists {
+		return 0, ErrInvalidRole
+	}
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetDisabled menonaktifkan / mengaktifkan kembali akun. Waktu nonaktif pertama dipertahankan.
+func (r *UserRepository) SetDisabled(ctx context.Context, id int, disabled bool) (int64, error) {
+	query := `UPDATE users SET disabled_at = NULL WHERE id = $1`
+	if disabled {
+		query = `UPDATE users SET disabled_at = COALESCE(disabled_at, NOW()) WHERE id = $1`
+	}
+	result, err := conn(ctx, r.DB).ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetAlumniID menautkan user ke data alumni; alumniID nil = lepas tautan
+func (r *UserRepository) SetAlumniID(ctx context.Context, id int, alumniID *int) (int64, error) {
+	if alumniID != nil {
+		var exists bool
+		if err := conn(ctx, r.DB).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM alumni WHERE id = $1)`, *alumniID).Scan(&exists); err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, ErrAlumniNotFound
+		}
+	}
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE users SET alumni_id = $1 WHERE id = $2`, alumniID, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id int) (int64, error) {
+	result, err := conn(ctx, r.DB).ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AccountState dipakai TokenService sebelum menerbitkan token, supaya role yang diganti admin dan
+// akun yang dinonaktifkan langsung berlaku saat refresh. Akun non-Postgres: Known false.
+func (r *UserRepository) AccountState(ctx context.Context, backend, userID string) (models.AccountState, error) {
+	if backend != models.BackendPostgres {
+		return models.AccountState{}, nil
+	}
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return models.AccountState{}, nil
+	}
+	st := models.AccountState{Known: true}
+	err = conn(ctx, r.DB).QueryRowContext(ctx, `
+		SELECT role, email_verified, disabled_at IS NOT NULL FROM users WHERE id = $1
+	`, id).Scan(&st.Role, &st.EmailVerified, &st.Disabled)
+	if err == sql.ErrNoRows {
+		// user sudah dihapus: perlakukan sama dengan nonaktif
+		return models.AccountState{Known: true, Disabled: true}, nil
+	}
+	return st, err
+}
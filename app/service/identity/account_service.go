@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+const emailChangePurpose = "email-change"
+
+// ProfileStore adalah AccountStore yang bisa dibaca per id dan mengganti email (endpoint /api/me)
+type ProfileStore interface {
+	AccountStore
+	FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error)
+	UpdateEmail(ctx context.Context, userID, email string) error
+}
+
+// AlumniFinder membaca data alumni yang ditautkan lewat alumni_id (repository.AlumniRepository)
+type AlumniFinder interface {
+	GetAlumniByID(ctx context.Context, id int) (*models.Alumni, error)
+}
+
+// OtherSessionRevoker mencabut sesi lain setelah password diganti, sesi yang dipakai tetap hidup
+type OtherSessionRevoker interface {
+	RevokeOthersForUser(ctx context.Context, backend, userID, keepFamilyID string) error
+}
+
+// AccountService: akun milik user yang sedang login, untuk akun Postgres maupun Mongo.
+// Ganti email & password wajib menyertakan password saat ini; akun SSO yang belum pernah
+// memasang password bisa memakai lupa password dulu.
+type AccountService struct {
+	Stores    []ProfileStore
+	Alumni    AlumniFinder
+	Sessions  OtherSessionRevoker
+	Mailer    utils.Mailer
+	PublicURL string
+	Secret    []byte
+	// EmailChangeTTL = masa berlaku link konfirmasi email baru
+	EmailChangeTTL time.Duration
+	Guard          *middleware.LoginGuard
+	Audit          *middleware.Auditor
+}
+
+func (s *AccountService) storeFor(backend string) ProfileStore {
+	for _, st := range s.Stores {
+		if st.Backend() == backend {
+			return st
+		}
+	}
+	return nil
+}
+
+func auditUserEntity(backend string) string {
+	if backend == models.BackendMongo {
+		return models.AuditEntityUserMongo
+	}
+	return models.AuditEntityUser
+}
+
+// current membaca akun pemilik token langsung dari store (bukan dari klaim yang bisa sudah usang)
+func (s *AccountService) current(c *fiber.Ctx) (ProfileStore, *models.Identity, error) {
+	claims := middleware.Claims(c)
+	store := s.storeFor(claims.Backend)
+	if store == nil {
+		return nil, nil, fiber.NewError(fiber.StatusForbidden, "akun ini tidak punya profil")
+	}
+	id, err := store.FindIdentityByID(c.UserContext(), claims.UserID)
+	if notFound(err) {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "akun tidak ditemukan")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, id, nil
+}
+
+// checkCurrentPassword ikut dihitung LoginGuard supaya password tidak bisa ditebak lewat endpoint ini.
+// false = respons penolakan sudah ditulis (atau err dari store).
+func (s *AccountService) checkCurrentPassword(c *fiber.Ctx, store ProfileStore, id *models.Identity, password string) (bool, error) {
+	if b := s.Guard.CheckLogin(id.Username, c.IP()); b != nil {
+		return false, b.Respond(c)
+	}
+	_, hash, err := store.FindIdentity(c.UserContext(), id.Username)
+	if err != nil {
+		return false, err
+	}
+	if password == "" || !utils.CheckPassword(password, hash) {
+		s.Guard.FailLogin(id.Username, c.IP())
+		return false, c.Status(403).JSON(fiber.Map{"error": "password saat ini salah"})
+	}
+	s.Guard.SucceedLogin(id.Username, c.IP())
+	return true, nil
+}
+
+func isEmail(s string) bool {
+	_, err := mail.ParseAddress(s)
+	return err == nil
+}
+
+// emailTaken: email sudah dipakai akun lain di store yang sama
+func emailTaken(ctx context.Context, store ProfileStore, id *models.Identity, email string) (bool, error) {
+	other, _, err := store.FindIdentity(ctx, email)
+	if notFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return other.ID != id.ID, nil
+}
+
+// GetMe godoc
+// @Summary Profil akun sendiri
+// @Tags Account
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Identity
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me [get]
+func (s *AccountService) GetMe(c *fiber.Ctx) error {
+	_, id, err := s.current(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(id)
+}
+
+// UpdateMe godoc
+// @Summary Ganti email akun sendiri
+// @Description Link konfirmasi dikirim ke email baru; email baru dipakai setelah link diklik.
+// @Tags Account
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateMeRequest true "Email baru & password saat ini"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /me [patch]
+func (s *AccountService) UpdateMe(c *fiber.Ctx) error {
+	var req models.UpdateMeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "email wajib"})
+	}
+	if !isEmail(req.Email) {
+		return c.Status(400).JSON(fiber.Map{"error": "format email tidak valid"})
+	}
+
+	store, id, err := s.current(c)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(req.Email, id.Email) {
+		return c.Status(400).JSON(fiber.Map{"error": "email sama dengan email saat ini"})
+	}
+	if ok, err := s.checkCurrentPassword(c, store, id, req.CurrentPassword); !ok {
+		return err
+	}
+	taken, err := emailTaken(c.UserContext(), store, id, req.Email)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if taken {
+		return c.Status(409).JSON(fiber.Map{"error": "email sudah dipakai"})
+	}
+
+	// email lama ikut ditandatangani, jadi link otomatis mati kalau email sudah berganti duluan
+	token := utils.SignLink(s.Secret, emailChangePurpose, time.Now().Add(s.EmailChangeTTL),
+		id.Backend, id.ID, strings.ToLower(id.Email), strings.ToLower(req.Email))
+	link := fmt.Sprintf("%s/api/verify-email-change?token=%s", s.PublicURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk memakai email ini di akun Alumni API (berlaku %d jam):\n%s\n\nAbaikan email ini jika kamu tidak meminta ganti email.\n",
+		id.Username, int(s.EmailChangeTTL.Hours()), link)
+	if err := s.Mailer.Send(req.Email, "Konfirmasi email baru", body); err != nil {
+		log.Printf("gagal kirim konfirmasi ganti email ke %s: %v", req.Email, err)
+		return c.Status(500).JSON(fiber.Map{"error": "gagal kirim email konfirmasi"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "link konfirmasi sudah dikirim ke email baru"})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Konfirmasi ganti email dari link
+// @Tags Account
+// @Produce json
+// @Param token query string true "Token dari email"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /verify-email-change [get]
+func (s *AccountService) ConfirmEmailChange(c *fiber.Ctx) error {
+	fields, err := utils.VerifyLink(s.Secret, emailChangePurpose, c.Query("token"), time.Now())
+	if err != nil || len(fields) != 4 {
+		msg := "link konfirmasi tidak valid"
+		if err == utils.ErrLinkExpired {
+			msg = "link konfirmasi kedaluwarsa, ulangi ganti email"
+		}
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+	backend, userID, oldEmail, newEmail := fields[0], fields[1], fields[2], fields[3]
+
+	store := s.storeFor(backend)
+	if store == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "link konfirmasi tidak valid"})
+	}
+	id, err := store.FindIdentityByID(c.UserContext(), userID)
+	if err != nil || !strings.EqualFold(id.Email, oldEmail) {
+		return c.Status(400).JSON(fiber.Map{"error": "link konfirmasi tidak valid"})
+	}
+	taken, err := emailTaken(c.UserContext(), store, id, newEmail)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if taken {
+		return c.Status(409).JSON(fiber.Map{"error": "email sudah dipakai"})
+	}
+	if err := store.UpdateEmail(c.UserContext(), userID, newEmail); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update email"})
+	}
+
+	after := *id
+	after.Email, after.EmailVerified = newEmail, true
+	s.Audit.Log(c, models.AuditUpdate, auditUserEntity(backend), userID, id, after)
+	return c.JSON(fiber.Map{"message": "email berhasil diganti"})
+}
+
+// ChangePassword godoc
+// @Summary Ganti password akun sendiri
+// @Description Sesi login lain dicabut, sesi yang dipakai request ini tetap aktif.
+// @Tags Account
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Password saat ini & password baru"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /me/password [post]
+func (s *AccountService) ChangePassword(c *fiber.Ctx) error {
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	store, id, err := s.current(c)
+	if err != nil {
+		return err
+	}
+	if ok, err := s.checkCurrentPassword(c, store, id, req.CurrentPassword); !ok {
+		return err
+	}
+
+	hash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
+	}
+	if err := store.UpdatePassword(c.UserContext(), id.ID, hash); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update password"})
+	}
+	if s.Sessions != nil {
+		if err := s.Sessions.RevokeOthersForUser(c.UserContext(), id.Backend, id.ID, middleware.Claims(c).FamilyID); err != nil {
+			log.Printf("gagal cabut sesi lain %s: %v", id.Subject(), err)
+		}
+	}
+	s.Audit.Log(c, models.AuditUpdate, auditUserEntity(id.Backend), id.ID, nil, fiber.Map{"password_changed": true})
+	return c.JSON(fiber.Map{"message": "password berhasil diganti"})
+}
+
+// GetMyAlumni godoc
+// @Summary Data alumni yang tertaut ke akun sendiri
+// @Description Berdasarkan alumni_id akun (users.alumni_id di Postgres, alumni_id di dokumen user Mongo)
+// @Tags Account
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Alumni
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me/alumni [get]
+func (s *AccountService) GetMyAlumni(c *fiber.Ctx) error {
+	_, id, err := s.current(c)
+	if err != nil {
+		return err
+	}
+	if id.AlumniID == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "akun belum ditautkan ke data alumni"})
+	}
+	alumni, err := s.Alumni.GetAlumniByID(c.UserContext(), *id.AlumniID)
+	if notFound(err) {
+		return c.Status(404).JSON(fiber.Map{"error": "data alumni " + strconv.Itoa(*id.AlumniID) + " tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(alumni)
+}
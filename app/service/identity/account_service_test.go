@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+type fakeProfileStore struct {
+	backend string
+	users   []*models.Identity
+	hashes  map[string]string
+}
+
+func (f *fakeProfileStore) Backend() string { return f.backend }
+
+func (f *fakeProfileStore) FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error) {
+	for _, u := range f.users {
+		if u.Username == identifier || u.Email == identifier {
+			return u, f.hashes[u.ID], nil
+		}
+	}
+	return nil, "", sql.ErrNoRows
+}
+
+func (f *fakeProfileStore) FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error) {
+	for _, u := range f.users {
+		if u.ID == userID {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeProfileStore) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	f.hashes[userID] = passwordHash
+	return nil
+}
+
+func (f *fakeProfileStore) UpdateEmail(ctx context.Context, userID, email string) error {
+	for _, u := range f.users {
+		if u.ID == userID {
+			u.Email = email
+		}
+	}
+	return nil
+}
+
+type fakeAlumniFinder map[int]*models.Alumni
+
+func (f fakeAlumniFinder) GetAlumniByID(ctx context.Context, id int) (*models.Alumni, error) {
+	a, ok := f[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return a, nil
+}
+
+type fakeOtherRevoker struct {
+	kept []string
+}
+
+func (f *fakeOtherRevoker) RevokeOthersForUser(ctx context.Context, backend, userID, keepFamilyID string) error {
+	f.kept = append(f.kept, keepFamilyID)
+	return nil
+}
+
+// newAccountApp memasang klaim seolah-olah sudah lewat Auth.Required
+func newAccountApp(svc *AccountService, backend, userID string) *fiber.App {
+	app := fiber.New()
+	app.Get("/verify-email-change", svc.ConfirmEmailChange)
+	me := app.Group("/me", func(c *fiber.Ctx) error {
+		c.Locals("claims", &models.JWTClaims{UserID: userID, Backend: backend, FamilyID: "fam-1"})
+		return c.Next()
+	})
+	me.Get("/", svc.GetMe)
+	me.Patch("/", svc.UpdateMe)
+	me.Post("/password", svc.ChangePassword)
+	me.Get("/alumni", svc.GetMyAlumni)
+	return app
+}
+
+func sendJSON(app *fiber.App, method, route, body string) int {
+	req := httptest.NewRequest(method, route, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp.StatusCode
+}
+
+func TestGetMeAndLinkedAlumni(t *testing.T) {
+	alumniID := 5
+	svc := &AccountService{
+		Stores: []ProfileStore{
+			&fakeProfileStore{backend: models.BackendPostgres, users: []*models.Identity{
+				{Backend: models.BackendPostgres, ID: "1", Username: "budi", Email: "budi@mail.com", AlumniID: &alumniID},
+			}},
+			&fakeProfileStore{backend: models.BackendMongo, users: []*models.Identity{
+				{Backend: models.BackendMongo, ID: "abc", Username: "siti", Email: "siti@mail.com"},
+			}},
+		},
+		Alumni: fakeAlumniFinder{5: {ID: 5, Nama: "Budi"}},
+	}
+	app := newAccountApp(svc, models.BackendPostgres, "1")
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/me", nil))
+	var me models.Identity
+	json.NewDecoder(resp.Body).Decode(&me)
+	if resp.StatusCode != 200 || me.Username != "budi" {
+		t.Fatalf("unexpected profile %v %+v", resp.StatusCode, me)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/me/alumni", nil))
+	var alumni models.Alumni
+	json.NewDecoder(resp.Body).Decode(&alumni)
+	if resp.StatusCode != 200 || alumni.Nama != "Budi" {
+		t.Fatalf("unexpected alumni %v %+v", resp.StatusCode, alumni)
+	}
+
+	// akun Mongo tanpa alumni_id
+	mongoApp := newAccountApp(svc, models.BackendMongo, "abc")
+	if resp, _ := mongoApp.Test(httptest.NewRequest("GET", "/me/alumni", nil)); resp.StatusCode != 404 {
+		t.Errorf("expected 404 for unlinked account, got %v", resp.StatusCode)
+	}
+}
+
+func TestChangePasswordRequiresCurrentPassword(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia123")
+	mongo := &fakeProfileStore{
+		backend: models.BackendMongo,
+		users:   []*models.Identity{{Backend: models.BackendMongo, ID: "abc", Username: "siti", Email: "siti@mail.com"}},
+		hashes:  map[string]string{"abc": hash},
+	}
+	revoker := &fakeOtherRevoker{}
+	app := newAccountApp(&AccountService{Stores: []ProfileStore{mongo}, Sessions: revoker}, models.BackendMongo, "abc")
+
+	if code := sendJSON(app, "POST", "/me/password", `{"current_password":"salah","new_password":"passwordbaru"}`); code != 403 {
+		t.Fatalf("expected 403 for wrong current password, got %v", code)
+	}
+	if code := sendJSON(app, "POST", "/me/password", `{"current_password":"rahasia123","new_password":"pendek"}`); code != 400 {
+		t.Fatalf("expected 400 for short password, got %v", code)
+	}
+	if code := sendJSON(app, "POST", "/me/password", `{"current_password":"rahasia123","new_password":"passwordbaru"}`); code != 200 {
+		t.Fatalf("expected 200, got %v", code)
+	}
+	if !utils.CheckPassword("passwordbaru", mongo.hashes["abc"]) {
+		t.Error("password tidak terupdate")
+	}
+	if len(revoker.kept) != 1 || revoker.kept[0] != "fam-1" {
+		t.Errorf("expected other sessions revoked keeping current one, got %v", revoker.kept)
+	}
+}
+
+func TestChangeEmailNeedsConfirmationLink(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia123")
+	mongo := &fakeProfileStore{
+		backend: models.BackendMongo,
+		users: []*models.Identity{
+			{Backend: models.BackendMongo, ID: "abc", Username: "siti", Email: "siti@mail.com"},
+			{Backend: models.BackendMongo, ID: "def", Username: "rina", Email: "rina@mail.com"},
+		},
+		hashes: map[string]string{"abc": hash},
+	}
+	mailer := &utils.OutboxMailer{Dir: t.TempDir()}
+	svc := &AccountService{
+		Stores: []ProfileStore{mongo}, Mailer: mailer,
+		PublicURL: "http://app.test", Secret: []byte("secret"), EmailChangeTTL: time.Hour,
+	}
+	app := newAccountApp(svc, models.BackendMongo, "abc")
+
+	if code := sendJSON(app, "PATCH", "/me", `{"email":"rina@mail.com","current_password":"rahasia123"}`); code != 409 {
+		t.Fatalf("expected 409 for taken email, got %v", code)
+	}
+	if code := sendJSON(app, "PATCH", "/me", `{"email":"siti.baru@mail.com","current_password":"rahasia123"}`); code != 202 {
+		t.Fatalf("expected 202, got %v", code)
+	}
+	if mongo.users[0].Email != "siti@mail.com" {
+		t.Fatal("email tidak boleh berubah sebelum link diklik")
+	}
+
+	msg, ok := mailer.Last()
+	if !ok || msg.To != "siti.baru@mail.com" {
+		t.Fatalf("expected confirmation mail to new address, got %+v", msg)
+	}
+	start := strings.Index(msg.Body, "token=")
+	raw := strings.Fields(msg.Body[start+len("token="):])[0]
+
+	if code := sendJSON(app, "GET", "/verify-email-change?token="+raw, ""); code != 200 {
+		t.Fatalf("expected 200 on confirm, got %v", code)
+	}
+	if mongo.users[0].Email != "siti.baru@mail.com" {
+		t.Errorf("email tidak terupdate: %v", mongo.users[0].Email)
+	}
+	// link lama mati karena email lama ikut ditandatangani
+	if code := sendJSON(app, "GET", "/verify-email-change?token="+raw, ""); code != 400 {
+		t.Errorf("expected 400 on reused link, got %v", code)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+// Store adalah sumber akun untuk login gabungan. Diimplementasikan oleh
+// repository.AuthRepository (Postgres) dan repository.UserMongoRepository (Mongo).
+type Store interface {
+	Backend() string
+	FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error)
+}
+
+type TokenIssuer interface {
+	IssueTokenPair(ctx context.Context, id models.Identity, meta models.SessionMeta) (*models.TokenPair, error)
+}
+
+type IdentityService struct {
+	Store  Store
+	Tokens TokenIssuer
+	// MFA opsional; kalau user wajib 2FA, Login mengembalikan challenge, bukan token
+	MFA   *MFAService
+	Guard *middleware.LoginGuard
+}
+
+type LoginResponse struct {
+	User         models.Identity `json:"user"`
+	Token        string          `json:"token"`
+	RefreshToken string          `json:"refresh_token"`
+	ExpiresIn    int64           `json:"expires_in"`
+}
+
+// Login godoc
+// @Summary Login (backend sesuai AUTH_BACKEND)
+// @Description Satu endpoint login untuk akun Postgres maupun Mongo. Token berisi klaim backend dan sub "<backend>:<id>".
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.LoginRequest true "Login Data"
+// @Success 200 {object} LoginResponse
+// @Success 202 {object} models.MFAChallenge
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /login [post]
+func (s *IdentityService) Login(c *fiber.Ctx) error {
+	var req models.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	req.Password = strings.TrimSpace(req.Password)
+	if req.Username == "" || req.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "username & password wajib"})
+	}
+
+	if b := s.Guard.CheckLogin(req.Username, c.IP()); b != nil {
+		return b.Respond(c)
+	}
+
+	id, hash, err := s.Store.FindIdentity(c.UserContext(), req.Username)
+	if err != nil {
+		s.Guard.FailLogin(req.Username, c.IP())
+		return c.Status(401).JSON(fiber.Map{"error": "username/password salah"})
+	}
+	if !utils.CheckPassword(req.Password, hash) {
+		s.Guard.FailLogin(req.Username, c.IP())
+		return c.Status(401).JSON(fiber.Map{"error": "username/password salah"})
+	}
+	s.Guard.SucceedLogin(req.Username, c.IP())
+	// hash lama (mis. bcrypt) diganti hash algoritma aktif selagi password plain tersedia
+	if accounts, ok := s.Store.(AccountStore); ok {
+		utils.RehashIfNeeded(req.Password, hash, func(newHash string) error {
+			return accounts.UpdatePassword(c.UserContext(), id.ID, newHash)
+		})
+	}
+
+	// status nonaktif baru dibuka setelah password benar
+	if id.Disabled {
+		return c.Status(403).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+	}
+
+	if s.MFA != nil {
+		challenge, err := s.MFA.Begin(c.UserContext(), *id)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal cek 2FA"})
+		}
+		if challenge != nil {
+			return c.Status(fiber.StatusAccepted).JSON(challenge)
+		}
+	}
+
+	pair, err := s.Tokens.IssueTokenPair(c.UserContext(), *id, middleware.SessionMeta(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
+	}
+	return c.JSON(LoginResponse{
+		User:         *id,
+		Token:        pair.Token,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+	})
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeStore struct {
+	backend string
+	users   map[string]*models.Identity
+	hashes  map[string]string
+}
+
+func (f *fakeStore) Backend() string { return f.backend }
+
+func (f *fakeStore) FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error) {
+	u, ok := f.users[identifier]
+	if !ok {
+		return nil, "", errors.New("not found")
+	}
+	return u, f.hashes[identifier], nil
+}
+
+type fakeIssuer struct {
+	issued models.Identity
+	err    error
+}
+
+func (f *fakeIssuer) IssueTokenPair(ctx context.Context, id models.Identity, meta models.SessionMeta) (*models.TokenPair, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.issued = id
+	return &models.TokenPair{Token: "access", RefreshToken: "refresh"}, nil
+}
+
+func newLoginApp(store Store, issuer TokenIssuer) *fiber.App {
+	app := fiber.New()
+	svc := &IdentityService{Store: store, Tokens: issuer}
+	app.Post("/login", svc.Login)
+	return app
+}
+
+func TestLoginUsesConfiguredBackend(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia")
+	store := &fakeStore{
+		backend: models.BackendMongo,
+		users:   map[string]*models.Identity{"budi": {Backend: models.BackendMongo, ID: "65f0c0ffee", Username: "budi", Role: "user"}},
+		hashes:  map[string]string{"budi": hash},
+	}
+	issuer := &fakeIssuer{}
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"username":"budi","password":"rahasia"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := newLoginApp(store, issuer).Test(req)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %v", resp.StatusCode)
+	}
+
+	var out LoginResponse
+	json.NewDecoder(resp.Body).Decode(&out)
+	if out.User.Backend != models.BackendMongo {
+		t.Errorf("expected backend mongo, got %v", out.User.Backend)
+	}
+	if issuer.issued.Subject() != "mongo:65f0c0ffee" {
+		t.Errorf("unexpected subject %v", issuer.issued.Subject())
+	}
+}
+
+func TestLoginWrongPasswordUnified(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia")
+	store := &fakeStore{
+		backend: models.BackendPostgres,
+		users:   map[string]*models.Identity{"admin": {Backend: models.BackendPostgres, ID: "1", Username: "admin"}},
+		hashes:  map[string]string{"admin": hash},
+	}
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"username":"admin","password":"salah"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := newLoginApp(store, &fakeIssuer{}).Test(req)
+	if resp.StatusCode != 401 {
+		t.Errorf("expected 401, got %v", resp.StatusCode)
+	}
+}
+
+func TestLoginRefusesDisabledAccount(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia")
+	store := &fakeStore{
+		backend: models.BackendPostgres,
+		users:   map[string]*models.Identity{"budi": {Backend: models.BackendPostgres, ID: "3", Username: "budi", Disabled: true}},
+		hashes:  map[string]string{"budi": hash},
+	}
+	issuer := &fakeIssuer{}
+	app := newLoginApp(store, issuer)
+
+	// password salah tetap 401, status nonaktif tidak dibocorkan
+	if code := sendJSON(app, "POST", "/login", `{"username":"budi","password":"salah"}`); code != 401 {
+		t.Fatalf("expected 401 for wrong password, got %v", code)
+	}
+	if code := sendJSON(app, "POST", "/login", `{"username":"budi","password":"rahasia"}`); code != 403 {
+		t.Fatalf("expected 403 for disabled account, got %v", code)
+	}
+	if issuer.issued.ID != "" {
+		t.Errorf("token tidak boleh diterbitkan, got %+v", issuer.issued)
+	}
+}
+
+func TestLoginRehashesLegacyBcrypt(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	store := &fakeProfileStore{
+		backend: models.BackendPostgres,
+		users:   []*models.Identity{{Backend: models.BackendPostgres, ID: "1", Username: "budi"}},
+		hashes:  map[string]string{"1": string(legacy)},
+	}
+	app := newLoginApp(store, &fakeIssuer{})
+
+	if code := sendJSON(app, "POST", "/login", `{"username":"budi","password":"rahasia123"}`); code != 200 {
+		t.Fatalf("expected 200, got %v", code)
+	}
+	if !strings.HasPrefix(store.hashes["1"], "$argon2id$") {
+		t.Fatalf("hash harus diganti argon2id, got %q", store.hashes["1"])
+	}
+	// login berikutnya memakai hash baru
+	if code := sendJSON(app, "POST", "/login", `{"username":"budi","password":"rahasia123"}`); code != 200 {
+		t.Fatalf("expected 200 with rehashed password, got %v", code)
+	}
+}
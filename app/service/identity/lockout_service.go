@@ -0,0 +1,102 @@
+package service
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/middleware"
+)
+
+// LockoutService: admin melihat & membuka kunci hasil proteksi brute-force login
+type LockoutService struct {
+	Guard *middleware.LoginGuard
+}
+
+type LockoutEntry struct {
+	Key string `json:"key"`
+	middleware.AttemptState
+	Locked bool `json:"locked"`
+}
+
+// ListLockouts godoc
+// @Summary Daftar percobaan login gagal & lockout (Admin Only)
+// @Description Kunci berbentuk user:<identifier>|<ip>, account:<identifier>, ip:<ip> atau mfa:<backend>:<id>. ?locked=true hanya yang sedang dikunci.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param locked query bool false "Hanya yang sedang dikunci"
+// @Success 200 {array} LockoutEntry
+// @Router /admin/lockouts [get]
+func (s *LockoutService) ListLockouts(c *fiber.Ctx) error {
+	onlyLocked := c.QueryBool("locked")
+	now := time.Now()
+
+	entries := []LockoutEntry{}
+	for key, st := range s.Guard.Store.All() {
+		locked := now.Before(st.LockedUntil) || now.Before(st.NextAttempt)
+		if onlyLocked && !locked {
+			continue
+		}
+		entries = append(entries, LockoutEntry{Key: key, AttemptState: st, Locked: locked})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return c.JSON(entries)
+}
+
+// ClearLockout godoc
+// @Summary Buka kunci login (Admin Only)
+// @Description kind=user membuka kunci identifier dari semua IP (termasuk kunci akun), atau dari satu IP kalau value berbentuk <identifier>|<ip>.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param kind path string true "user | ip | mfa"
+// @Param value path string true "identifier (opsional |<ip>), IP, atau <backend>:<id>"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/lockouts/{kind}/{value} [delete]
+func (s *LockoutService) ClearLockout(c *fiber.Ctx) error {
+	value, err := url.PathUnescape(c.Params("value"))
+	if err != nil || value == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "value tidak valid"})
+	}
+
+	var key string
+	switch c.Params("kind") {
+	case "user":
+		if identifier, ip, ok := strings.Cut(value, "|"); ok {
+			key = middleware.UserAttemptKey(identifier, ip)
+			break
+		}
+		return s.clearUser(c, value)
+	case "ip":
+		key = middleware.IPAttemptKey(value)
+	case "mfa":
+		key = middleware.MFAAttemptKey(value)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "kind harus user, ip atau mfa"})
+	}
+
+	if !s.Guard.Store.Delete(key) {
+		return c.Status(404).JSON(fiber.Map{"error": "tidak ada catatan untuk " + key})
+	}
+	return c.JSON(fiber.Map{"message": "lockout dibuka", "key": key})
+}
+
+// clearUser membuka kunci identifier dari semua IP beserta kunci akunnya
+func (s *LockoutService) clearUser(c *fiber.Ctx, identifier string) error {
+	prefix, account := middleware.UserAttemptPrefix(identifier), middleware.AccountAttemptKey(identifier)
+	keys := []string{}
+	for key := range s.Guard.Store.All() {
+		if (strings.HasPrefix(key, prefix) || key == account) && s.Guard.Store.Delete(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "tidak ada catatan untuk " + prefix + "*"})
+	}
+	sort.Strings(keys)
+	return c.JSON(fiber.Map{"message": "lockout dibuka", "keys": keys})
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+const (
+	mfaChallengePurpose = "mfa-challenge"
+	recoveryCodeCount   = 10
+)
+
+// MFAStore diimplementasikan repository.MFARepository
+type MFAStore interface {
+	Get(ctx context.Context, backend, userID string) (*models.UserMFA, error)
+	SavePending(ctx context.Context, backend, userID, encSecret string) (bool, error)
+	Enable(ctx context.Context, backend, userID string, step int64, codeHashes []string) error
+	MarkStepUsed(ctx context.Context, backend, userID string, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, backend, userID, codeHash string) (bool, error)
+	UseChallenge(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, backend, userID string) (int, error)
+	ReplaceRecoveryCodes(ctx context.Context, backend, userID string, codeHashes []string) error
+	Disable(ctx context.Context, backend, userID string) error
+}
+
+// MFAService menangani 2FA TOTP untuk akun Postgres maupun Mongo. Semua endpoint login
+// memanggil Begin setelah password benar; kalau hasilnya challenge, token baru diterbitkan di LoginMFA.
+type MFAService struct {
+	Repo   MFAStore
+	Tokens TokenIssuer
+	// Secret menandatangani mfa_token (challenge), EncKey mengenkripsi secret TOTP di DB
+	Secret []byte
+	EncKey []byte
+	Issuer string
+	TTL    time.Duration
+	// RequiredForAdmin: role admin tanpa 2FA harus enroll dulu sebelum dapat token
+	RequiredForAdmin bool
+	// Guard membatasi tebakan kode 2FA per akun (opsional)
+	Guard *middleware.LoginGuard
+}
+
+type MFALoginResponse struct {
+	LoginResponse
+	// RecoveryCodes hanya muncul sekali, saat enroll wajib diselesaikan lewat login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type MFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+func (s *MFAService) required(id models.Identity) bool {
+	return s.RequiredForAdmin && id.Role == models.RoleAdmin
+}
+
+func (s *MFAService) find(ctx context.Context, id models.Identity) (*models.UserMFA, error) {
+	m, err := s.Repo.Get(ctx, id.Backend, id.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+// Begin mengembalikan challenge kalau user punya 2FA aktif atau wajib 2FA; nil berarti login langsung dapat token
+func (s *MFAService) Begin(ctx context.Context, id models.Identity) (*models.MFAChallenge, error) {
+	m, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	enabled := m != nil && m.Enabled
+	if !enabled && !s.required(id) {
+		return nil, nil
+	}
+
+	alumniID := ""
+	if id.AlumniID != nil {
+		alumniID = strconv.Itoa(*id.AlumniID)
+	}
+	// nonce membuat tiap challenge unik (dua login di detik yang sama tidak berbagi mfa_token sekali pakai)
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	token := utils.SignLink(s.Secret, mfaChallengePurpose, time.Now().Add(s.TTL),
+		id.Backend, id.ID, id.Username, id.Email, id.Role, strconv.FormatBool(id.EmailVerified), alumniID, nonce)
+	return &models.MFAChallenge{
+		MFARequired:        true,
+		EnrollmentRequired: !enabled,
+		MFAToken:           token,
+		ExpiresIn:          int64(s.TTL.Seconds()),
+	}, nil
+}
+
+func (s *MFAService) challengeIdentity(token string) (*models.Identity, error) {
+	f, err := utils.VerifyLink(s.Secret, mfaChallengePurpose, strings.TrimSpace(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(f) != 8 {
+		return nil, utils.ErrLinkInvalid
+	}
+	id := &models.Identity{Backend: f[0], ID: f[1], Username: f[2], Email: f[3], Role: f[4], EmailVerified: f[5] == "true"}
+	if f[6] != "" {
+		if n, err := strconv.Atoi(f[6]); err == nil {
+			id.AlumniID = &n
+		}
+	}
+	return id, nil
+}
+
+// identity dari access token untuk endpoint /api/mfa
+func claimsIdentity(c *fiber.Ctx) models.Identity {
+	claims := middleware.Claims(c)
+	return models.Identity{Backend: claims.Backend, ID: claims.UserID, Username: claims.Username, Role: claims.Role}
+}
+
+// verifyCode mencocokkan kode TOTP (sekali pakai per time-step) atau recovery code.
+// Kode salah dicatat ke Guard; pemanggil cek Guard.Check(MFAAttemptKey) dulu.
+func (s *MFAService) verifyCode(c *fiber.Ctx, m *models.UserMFA, code string) (bool, error) {
+	ok, err := s.matchCode(c.UserContext(), m, code)
+	if err != nil {
+		return false, err
+	}
+	key := middleware.MFAAttemptKey(m.Backend + ":" + m.UserID)
+	if ok {
+		s.Guard.Succeed(key)
+	} else {
+		s.Guard.Fail(key, c.IP())
+	}
+	return ok, nil
+}
+
+func (s *MFAService) matchCode(ctx context.Context, m *models.UserMFA, code string) (bool, error) {
+	secret, err := utils.DecryptString(s.EncKey, m.Secret)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := utils.ValidateTOTP(secret, code, time.Now()); ok {
+		return s.Repo.MarkStepUsed(ctx, m.Backend, m.UserID, step)
+	}
+	return s.Repo.ConsumeRecoveryCode(ctx, m.Backend, m.UserID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+}
+
+// startEnrollment membuat secret baru (belum aktif sampai dikonfirmasi dengan kode)
+func (s *MFAService) startEnrollment(ctx context.Context, id models.Identity) (*models.MFAEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	enc, err := utils.EncryptString(s.EncKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.Repo.SavePending(ctx, id.Backend, id.ID, enc)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, fiber.NewError(fiber.StatusConflict, "2FA sudah aktif")
+	}
+	return &models.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPProvisioningURI(s.Issuer, id.Username, secret),
+	}, nil
+}
+
+// confirmEnrollment mengaktifkan secret pending dan mengembalikan recovery code (plain, sekali tampil)
+func (s *MFAService) confirmEnrollment(c *fiber.Ctx, m *models.UserMFA, code string) ([]string, error) {
+	secret, err := utils.DecryptString(s.EncKey, m.Secret)
+	if err != nil {
+		return nil, err
+	}
+	key := middleware.MFAAttemptKey(m.Backend + ":" + m.UserID)
+	if b := s.Guard.Check(key); b != nil {
+		return nil, fiber.NewError(fiber.StatusTooManyRequests, "terlalu banyak percobaan kode 2FA, coba lagi nanti")
+	}
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		s.Guard.Fail(key, c.IP())
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "kode 2FA salah")
+	}
+	s.Guard.Succeed(key)
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Enable(c.UserContext(), m.Backend, m.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+// errorResponse menjaga format {"error": ...} untuk fiber.Error dari helper di atas
+func errorResponse(c *fiber.Ctx, err error, fallback string) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return c.Status(fe.Code).JSON(fiber.Map{"error": fe.Message})
+	}
+	log.Printf("mfa: %v", err)
+	return c.Status(500).JSON(fiber.Map{"error": fallback})
+}
+
+// LoginMFA godoc
+// @Summary Login langkah kedua (kode 2FA)
+// @Description Menukar mfa_token dari login + kode TOTP / recovery code dengan access & refresh token.
+// @Description mfa_token hanya bisa ditukar sekali; setelah berhasil, login ulang untuk challenge baru.
+// @Description Untuk admin yang wajib enroll, kode pertama sekaligus mengaktifkan 2FA dan recovery code dikembalikan sekali.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFALoginRequest true "mfa_token & kode"
+// @Success 200 {object} MFALoginResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /login/mfa [post]
+func (s *MFAService) LoginMFA(c *fiber.Ctx) error {
+	var req models.MFALoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "kode wajib"})
+	}
+	id, err := s.challengeIdentity(req.MFAToken)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "mfa_token tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+
+	m, err := s.find(c.UserContext(), *id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if m == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "2FA belum di-enroll, panggil /api/login/mfa/enroll dulu"})
+	}
+
+	if b := s.Guard.Check(middleware.MFAAttemptKey(id.Subject())); b != nil {
+		return b.Respond(c)
+	}
+
+	var recovery []string
+	if m.Enabled {
+		ok, err := s.verifyCode(c, m, req.Code)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal verifikasi kode"})
+		}
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{"error": "kode 2FA salah"})
+		}
+	} else {
+		if recovery, err = s.confirmEnrollment(c, m, req.Code); err != nil {
+			return errorResponse(c, err, "gagal mengaktifkan 2FA")
+		}
+	}
+
+	// mfa_token sekali pakai: ditandai setelah kode benar, jadi salah ketik kode masih bisa diulang
+	fresh, err := s.Repo.UseChallenge(c.UserContext(), utils.HashToken(strings.TrimSpace(req.MFAToken)), time.Now().Add(s.TTL))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if !fresh {
+		return c.Status(401).JSON(fiber.Map{"error": "mfa_token sudah dipakai, silakan login ulang"})
+	}
+
+	pair, err := s.Tokens.IssueTokenPair(c.UserContext(), *id, middleware.SessionMeta(c))
+	if errors.Is(err, models.ErrAccountDisabled) {
+		// dinonaktifkan admin di antara langkah password dan kode 2FA
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
+	}
+	return c.JSON(MFALoginResponse{
+		LoginResponse: LoginResponse{
+			User:         *id,
+			Token:        pair.Token,
+			RefreshToken: pair.RefreshToken,
+			ExpiresIn:    pair.ExpiresIn,
+		},
+		RecoveryCodes: recovery,
+	})
+}
+
+// LoginMFAEnroll godoc
+// @Summary Enroll 2FA saat login (admin wajib 2FA)
+// @Description Hanya untuk challenge dengan enrollment_required=true. Lanjutkan dengan /login/mfa memakai kode dari authenticator.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFAChallengeRequest true "mfa_token"
+// @Success 200 {object} models.MFAEnrollment
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /login/mfa/enroll [post]
+func (s *MFAService) LoginMFAEnroll(c *fiber.Ctx) error {
+	var req models.MFAChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	id, err := s.challengeIdentity(req.MFAToken)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "mfa_token tidak valid atau kedaluwarsa, silakan login ulang"})
+	}
+	if !s.required(*id) {
+		return c.Status(403).JSON(fiber.Map{"error": "enroll 2FA dilakukan setelah login lewat /api/mfa/enroll"})
+	}
+	enrollment, err := s.startEnrollment(c.UserContext(), *id)
+	if err != nil {
+		return errorResponse(c, err, "gagal membuat secret 2FA")
+	}
+	return c.JSON(enrollment)
+}
+
+// Status godoc
+// @Summary Status 2FA akun sendiri
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} MFAStatus
+// @Router /mfa [get]
+func (s *MFAService) Status(c *fiber.Ctx) error {
+	id := claimsIdentity(c)
+	m, err := s.find(c.UserContext(), id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	status := MFAStatus{Required: s.required(id)}
+	if m != nil && m.Enabled {
+		status.Enabled = true
+		if status.RecoveryCodesRemaining, err = s.Repo.CountRecoveryCodes(c.UserContext(), id.Backend, id.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "db error"})
+		}
+	}
+	return c.JSON(status)
+}
+
+// Enroll godoc
+// @Summary Mulai enroll 2FA
+// @Description Mengembalikan secret & otpauth URI (untuk QR code). 2FA aktif setelah /mfa/confirm.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.MFAEnrollment
+// @Failure 409 {object} models.ErrorResponse
+// @Router /mfa/enroll [post]
+func (s *MFAService) Enroll(c *fiber.Ctx) error {
+	enrollment, err := s.startEnrollment(c.UserContext(), claimsIdentity(c))
+	if err != nil {
+		return errorResponse(c, err, "gagal membuat secret 2FA")
+	}
+	return c.JSON(enrollment)
+}
+
+// Confirm godoc
+// @Summary Aktifkan 2FA dengan kode pertama
+// @Description Recovery code hanya ditampilkan sekali di respons ini
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "Kode TOTP"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} models.ErrorResponse
+// @Router /mfa/confirm [post]
+func (s *MFAService) Confirm(c *fiber.Ctx) error {
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	m, err := s.find(c.UserContext(), claimsIdentity(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if m == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "panggil /api/mfa/enroll dulu"})
+	}
+	if m.Enabled {
+		return c.Status(409).JSON(fiber.Map{"error": "2FA sudah aktif"})
+	}
+	codes, err := s.confirmEnrollment(c, m, req.Code)
+	if err != nil {
+		return errorResponse(c, err, "gagal mengaktifkan 2FA")
+	}
+	return c.JSON(fiber.Map{"message": "2FA aktif, simpan recovery code di tempat aman", "recovery_codes": codes})
+}
+
+// activeWithCode mengambil 2FA aktif milik user dan memastikan kode di body benar
+func (s *MFAService) activeWithCode(c *fiber.Ctx, id models.Identity) (*models.UserMFA, error) {
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "payload tidak valid")
+	}
+	m, err := s.find(c.UserContext(), id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || !m.Enabled {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "2FA belum aktif")
+	}
+	if b := s.Guard.Check(middleware.MFAAttemptKey(id.Subject())); b != nil {
+		return nil, fiber.NewError(fiber.StatusTooManyRequests, "terlalu banyak percobaan kode 2FA, coba lagi nanti")
+	}
+	ok, err := s.verifyCode(c, m, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "kode 2FA salah")
+	}
+	return m, nil
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Buat ulang recovery code
+// @Description Semua recovery code lama tidak berlaku lagi
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "Kode TOTP"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} models.ErrorResponse
+// @Router /mfa/recovery-codes [post]
+func (s *MFAService) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	id := claimsIdentity(c)
+	if _, err := s.activeWithCode(c, id); err != nil {
+		return errorResponse(c, err, "gagal verifikasi kode")
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat recovery code"})
+	}
+	if err := s.Repo.ReplaceRecoveryCodes(c.UserContext(), id.Backend, id.ID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// Disable godoc
+// @Summary Nonaktifkan 2FA
+// @Description Ditolak untuk admin kalau kebijakan MFA_REQUIRED_FOR_ADMIN aktif
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "Kode TOTP / recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} models.ErrorResponse
+// @Router /mfa/disable [post]
+func (s *MFAService) Disable(c *fiber.Ctx) error {
+	id := claimsIdentity(c)
+	if s.required(id) {
+		return c.Status(403).JSON(fiber.Map{"error": "2FA wajib untuk role admin"})
+	}
+	if _, err := s.activeWithCode(c, id); err != nil {
+		return errorResponse(c, err, "gagal verifikasi kode")
+	}
+	if err := s.Repo.Disable(c.UserContext(), id.Backend, id.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(fiber.Map{"message": "2FA dinonaktifkan"})
+}
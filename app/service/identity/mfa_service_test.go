@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+type fakeMFAStore struct {
+	rows       map[string]*models.UserMFA
+	recovery   map[string]bool
+	challenges map[string]bool
+}
+
+func (f *fakeMFAStore) Get(ctx context.Context, backend, userID string) (*models.UserMFA, error) {
+	m, ok := f.rows[backend+":"+userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return m, nil
+}
+
+func (f *fakeMFAStore) SavePending(ctx context.Context, backend, userID, encSecret string) (bool, error) {
+	if m, ok := f.rows[backend+":"+userID]; ok && m.Enabled {
+		return false, nil
+	}
+	f.rows[backend+":"+userID] = &models.UserMFA{Backend: backend, UserID: userID, Secret: encSecret}
+	return true, nil
+}
+
+func (f *fakeMFAStore) Enable(ctx context.Context, backend, userID string, step int64, codeHashes []string) error {
+	m := f.rows[backend+":"+userID]
+	m.Enabled, m.LastUsedStep = true, step
+	return f.ReplaceRecoveryCodes(ctx, backend, userID, codeHashes)
+}
+
+func (f *fakeMFAStore) MarkStepUsed(ctx context.Context, backend, userID string, step int64) (bool, error) {
+	m := f.rows[backend+":"+userID]
+	if m.LastUsedStep >= step {
+		return false, nil
+	}
+	m.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeMFAStore) ConsumeRecoveryCode(ctx context.Context, backend, userID, codeHash string) (bool, error) {
+	if !f.recovery[codeHash] {
+		return false, nil
+	}
+	f.recovery[codeHash] = false
+	return true, nil
+}
+
+func (f *fakeMFAStore) UseChallenge(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error) {
+	if f.challenges[tokenHash] {
+		return false, nil
+	}
+	if f.challenges == nil {
+		f.challenges = map[string]bool{}
+	}
+	f.challenges[tokenHash] = true
+	return true, nil
+}
+
+func (f *fakeMFAStore) CountRecoveryCodes(ctx context.Context, backend, userID string) (int, error) {
+	n := 0
+	for _, unused := range f.recovery {
+		if unused {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeMFAStore) ReplaceRecoveryCodes(ctx context.Context, backend, userID string, codeHashes []string) error {
+	f.recovery = map[string]bool{}
+	for _, h := range codeHashes {
+		f.recovery[h] = true
+	}
+	return nil
+}
+
+func (f *fakeMFAStore) Disable(ctx context.Context, backend, userID string) error {
+	delete(f.rows, backend+":"+userID)
+	return nil
+}
+
+func postJSONInto(app *fiber.App, path, body string, out interface{}) int {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	json.NewDecoder(resp.Body).Decode(out)
+	return resp.StatusCode
+}
+
+func TestAdminMustEnrollMFABeforeGettingTokens(t *testing.T) {
+	hash, _ := utils.HashPassword("rahasia")
+	store := &fakeStore{
+		backend: models.BackendPostgres,
+		users:   map[string]*models.Identity{"admin": {Backend: models.BackendPostgres, ID: "1", Username: "admin", Role: "admin"}},
+		hashes:  map[string]string{"admin": hash},
+	}
+	issuer := &fakeIssuer{}
+	mfa := &MFAService{
+		Repo:             &fakeMFAStore{rows: map[string]*models.UserMFA{}},
+		Tokens:           issuer,
+		Secret:           []byte("0123456789abcdef0123456789abcdef"),
+		EncKey:           make([]byte, 32),
+		Issuer:           "Alumni API",
+		TTL:              5 * time.Minute,
+		RequiredForAdmin: true,
+	}
+	app := fiber.New()
+	app.Post("/login", (&IdentityService{Store: store, Tokens: issuer, MFA: mfa}).Login)
+	app.Post("/login/mfa", mfa.LoginMFA)
+	app.Post("/login/mfa/enroll", mfa.LoginMFAEnroll)
+
+	var challenge models.MFAChallenge
+	if code := postJSONInto(app, "/login", `{"username":"admin","password":"rahasia"}`, &challenge); code != 202 {
+		t.Fatalf("expected 202, got %v", code)
+	}
+	if !challenge.EnrollmentRequired || issuer.issued.ID != "" {
+		t.Fatalf("expected enrollment challenge without tokens, got %+v", challenge)
+	}
+
+	var enrollment models.MFAEnrollment
+	postJSONInto(app, "/login/mfa/enroll", `{"mfa_token":"`+challenge.MFAToken+`"}`, &enrollment)
+	if !strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/") {
+		t.Fatalf("unexpected enrollment %+v", enrollment)
+	}
+
+	if code := postJSON(app, "/login/mfa", `{"mfa_token":"`+challenge.MFAToken+`","code":"000000x"}`); code != 401 {
+		t.Errorf("expected wrong code to be rejected, got %v", code)
+	}
+
+	totp, _ := utils.TOTPCode(enrollment.Secret, utils.TOTPStep(time.Now()))
+	var out MFALoginResponse
+	if code := postJSONInto(app, "/login/mfa", `{"mfa_token":"`+challenge.MFAToken+`","code":"`+totp+`"}`, &out); code != 200 {
+		t.Fatalf("expected 200, got %v", code)
+	}
+	if out.Token != "access" || len(out.RecoveryCodes) != recoveryCodeCount || issuer.issued.Subject() != "postgres:1" {
+		t.Fatalf("unexpected response %+v", out)
+	}
+
+	// mfa_token yang sudah ditukar tidak bisa dipakai lagi, bahkan dengan recovery code yang masih valid
+	if code := postJSON(app, "/login/mfa", `{"mfa_token":"`+challenge.MFAToken+`","code":"`+strings.ToUpper(out.RecoveryCodes[1])+`"}`); code != 401 {
+		t.Errorf("expected used mfa_token to be rejected, got %v", code)
+	}
+
+	// kode yang sama tidak boleh dipakai ulang, recovery code hanya sekali
+	var next models.MFAChallenge
+	postJSONInto(app, "/login", `{"username":"admin","password":"rahasia"}`, &next)
+	if code := postJSON(app, "/login/mfa", `{"mfa_token":"`+next.MFAToken+`","code":"`+totp+`"}`); code != 401 {
+		t.Errorf("expected replayed code to be rejected, got %v", code)
+	}
+	body := `{"mfa_token":"` + next.MFAToken + `","code":"` + strings.ToUpper(out.RecoveryCodes[0]) + `"}`
+	if code := postJSON(app, "/login/mfa", body); code != 200 {
+		t.Errorf("expected recovery code to work, got %v", code)
+	}
+	postJSONInto(app, "/login", `{"username":"admin","password":"rahasia"}`, &next)
+	body = `{"mfa_token":"` + next.MFAToken + `","code":"` + strings.ToUpper(out.RecoveryCodes[0]) + `"}`
+	if code := postJSON(app, "/login/mfa", body); code != 401 {
+		t.Errorf("expected used recovery code to be rejected, got %v", code)
+	}
+}
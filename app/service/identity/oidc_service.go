@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+const oidcStateCookie = "oidc_state"
+
+var (
+	errOIDCNoAccount       = errors.New("akun belum terdaftar, hubungi admin")
+	errOIDCEmailUnverified = errors.New("IdP tidak mengirim email yang terverifikasi")
+	errOIDCLocalUnverified = errors.New("email sudah dipakai akun lokal yang belum diverifikasi; verifikasi dulu atau login dengan password")
+	errOIDCOtherBackend    = errors.New("akun SSO tertaut ke backend lain")
+)
+
+// OIDCStore menyimpan state login dan tautan akun IdP (Postgres: repository.OIDCRepository)
+type OIDCStore interface {
+	SaveState(ctx context.Context, st *models.OIDCLoginState) error
+	ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	FindLink(ctx context.Context, issuer, subject string) (*models.OIDCLink, error)
+	SaveLink(ctx context.Context, l *models.OIDCLink) error
+	TouchLink(ctx context.Context, issuer, subject, email string) error
+}
+
+// OIDCAccountStore adalah Store yang bisa mencari akun per id dan membuat akun baru (auto-provisioning)
+type OIDCAccountStore interface {
+	Store
+	FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error)
+	CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*models.Identity, error)
+}
+
+// OIDCService: login SSO lewat IdP kampus (authorization code + PKCE).
+// Akun IdP dipetakan ke akun lokal lewat tabel oidc_identities; login pertama menautkan lewat email
+// yang sudah diverifikasi IdP, atau membuat akun baru kalau AutoProvision aktif.
+type OIDCService struct {
+	Provider      *utils.OIDCClient
+	Store         OIDCStore
+	Accounts      OIDCAccountStore
+	Tokens        TokenIssuer
+	MFA           *MFAService
+	AutoProvision bool
+	DefaultRole   string
+	StateTTL      time.Duration
+}
+
+func notFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, mongo.ErrNoDocuments)
+}
+
+// OIDCLogin godoc
+// @Summary Mulai login SSO (OpenID Connect)
+// @Description Redirect ke halaman login IdP. Setelah login, IdP kembali ke /oidc/callback.
+// @Tags Auth
+// @Success 302
+// @Failure 502 {object} models.ErrorResponse
+// @Router /oidc/login [get]
+func (s *OIDCService) OIDCLogin(c *fiber.Ctx) error {
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat state"})
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat state"})
+	}
+	verifier, challenge, err := utils.NewPKCE()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat state"})
+	}
+
+	authURL, err := s.Provider.AuthCodeURL(c.UserContext(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("OIDC: %v", err)
+		return c.Status(502).JSON(fiber.Map{"error": "IdP tidak bisa dihubungi"})
+	}
+	expires := time.Now().Add(s.StateTTL)
+	if err := s.Store.SaveState(c.UserContext(), &models.OIDCLoginState{
+		StateHash: utils.HashToken(state),
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: expires,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+
+	// state juga disimpan di cookie browser supaya callback tidak bisa dipakai untuk login CSRF
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/oidc",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   strings.HasPrefix(s.Provider.RedirectURL, "https://"),
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary Callback login SSO
+// @Description Menukar code dari IdP, memetakan user IdP ke akun lokal, lalu mengembalikan token seperti /login.
+// @Tags Auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} LoginResponse
+// @Success 202 {object} models.MFAChallenge
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /oidc/callback [get]
+func (s *OIDCService) OIDCCallback(c *fiber.Ctx) error {
+	if e := c.Query("error"); e != "" {
+		return c.Status(401).JSON(fiber.Map{"error": "login SSO dibatalkan: " + e})
+	}
+	state, cookie := c.Query("state"), c.Cookies(oidcStateCookie)
+	c.ClearCookie(oidcStateCookie)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookie)) != 1 {
+		return c.Status(400).JSON(fiber.Map{"error": "state tidak valid"})
+	}
+	st, err := s.Store.ConsumeState(c.UserContext(), utils.HashToken(state))
+	if err != nil {
+		if notFound(err) {
+			return c.Status(400).JSON(fiber.Map{"error": "sesi login SSO kedaluwarsa, ulangi login"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+
+	claims, err := s.Provider.Exchange(c.UserContext(), c.Query("code"), st.Verifier, st.Nonce)
+	if err != nil {
+		log.Printf("OIDC: %v", err)
+		return c.Status(401).JSON(fiber.Map{"error": "login SSO gagal"})
+	}
+
+	id, err := s.resolve(c.UserContext(), claims)
+	switch {
+	case errors.Is(err, errOIDCNoAccount), errors.Is(err, errOIDCEmailUnverified):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, errOIDCLocalUnverified), errors.Is(err, errOIDCOtherBackend):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("OIDC: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "gagal memetakan akun SSO"})
+	case id.Disabled:
+		return c.Status(403).JSON(fiber.Map{"error": models.ErrAccountDisabled.Error()})
+	}
+
+	if s.MFA != nil {
+		challenge, err := s.MFA.Begin(c.UserContext(), *id)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal cek 2FA"})
+		}
+		if challenge != nil {
+			return c.Status(fiber.StatusAccepted).JSON(challenge)
+		}
+	}
+
+	pair, err := s.Tokens.IssueTokenPair(c.UserContext(), *id, middleware.SessionMeta(c))
+	if errors.Is(err, models.ErrAccountDisabled) {
+		// status akun dibaca ulang saat token diterbitkan, bisa sudah dinonaktifkan admin
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
+	}
+	return c.JSON(LoginResponse{
+		User:         *id,
+		Token:        pair.Token,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+	})
+}
+
+// resolve mencari akun lokal untuk user IdP: tautan yang sudah ada → email terverifikasi → auto-provision
+func (s *OIDCService) resolve(ctx context.Context, claims *utils.OIDCClaims) (*models.Identity, error) {
+	link, err := s.Store.FindLink(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		if link.Backend != s.Accounts.Backend() {
+			return nil, errOIDCOtherBackend
+		}
+		id, err := s.Accounts.FindIdentityByID(ctx, link.UserID)
+		if notFound(err) {
+			return nil, errOIDCNoAccount
+		}
+		if err != nil {
+			return nil, err
+		}
+		_ = s.Store.TouchLink(ctx, claims.Issuer, claims.Subject, claims.Email)
+		return id, nil
+	}
+	if !notFound(err) {
+		return nil, err
+	}
+
+	// akun baru di sisi kita hanya ditautkan / dibuat berdasarkan email yang dijamin IdP
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errOIDCEmailUnverified
+	}
+	id, _, err := s.Accounts.FindIdentity(ctx, claims.Email)
+	switch {
+	case err == nil && strings.EqualFold(id.Email, claims.Email):
+		// akun self-register yang belum verifikasi bisa saja dibuat orang lain dengan email ini
+		if !id.EmailVerified {
+			return nil, errOIDCLocalUnverified
+		}
+	case err != nil && !notFound(err):
+		return nil, err
+	case !s.AutoProvision:
+		return nil, errOIDCNoAccount
+	default:
+		if id, err = s.provision(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Store.SaveLink(ctx, &models.OIDCLink{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Backend: id.Backend,
+		UserID:  id.ID,
+		Email:   claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// provision membuat akun lokal. Password diisi acak (tidak diketahui siapa pun);
+// user tetap bisa memasang password sendiri lewat lupa password.
+func (s *OIDCService) provision(ctx context.Context, claims *utils.OIDCClaims) (*models.Identity, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	username := base
+	for i := 0; ; i++ {
+		_, _, err := s.Accounts.FindIdentity(ctx, username)
+		if notFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i == 5 {
+			return nil, errors.New("tidak menemukan username yang tersedia")
+		}
+		suffix := make([]byte, 2)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := utils.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+	return s.Accounts.CreateIdentity(ctx, username, claims.Email, hash, s.DefaultRole)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+	"go_clean/utils/oidcmock"
+)
+
+type fakeOIDCStore struct {
+	states map[string]*models.OIDCLoginState
+	links  map[string]*models.OIDCLink
+}
+
+func (f *fakeOIDCStore) SaveState(ctx context.Context, st *models.OIDCLoginState) error {
+	f.states[st.StateHash] = st
+	return nil
+}
+
+func (f *fakeOIDCStore) ConsumeState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	st, ok := f.states[stateHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(f.states, stateHash)
+	return st, nil
+}
+
+func (f *fakeOIDCStore) FindLink(ctx context.Context, issuer, subject string) (*models.OIDCLink, error) {
+	l, ok := f.links[issuer+"|"+subject]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return l, nil
+}
+
+func (f *fakeOIDCStore) SaveLink(ctx context.Context, l *models.OIDCLink) error {
+	f.links[l.Issuer+"|"+l.Subject] = l
+	return nil
+}
+
+func (f *fakeOIDCStore) TouchLink(ctx context.Context, issuer, subject, email string) error {
+	return nil
+}
+
+type fakeOIDCAccounts struct {
+	users []*models.Identity
+}
+
+func (f *fakeOIDCAccounts) Backend() string { return models.BackendPostgres }
+
+func (f *fakeOIDCAccounts) FindIdentity(ctx context.Context, identifier string) (*models.Identity, string, error) {
+	for _, u := range f.users {
+		if u.Username == identifier || u.Email == identifier {
+			return u, "", nil
+		}
+	}
+	return nil, "", sql.ErrNoRows
+}
+
+func (f *fakeOIDCAccounts) FindIdentityByID(ctx context.Context, userID string) (*models.Identity, error) {
+	for _, u := range f.users {
+		if u.ID == userID {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeOIDCAccounts) CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*models.Identity, error) {
+	u := &models.Identity{Backend: models.BackendPostgres, ID: "100", Username: username, Email: email, Role: role, EmailVerified: true}
+	f.users = append(f.users, u)
+	return u, nil
+}
+
+// oidcLogin menjalankan /login → IdP tiruan → /callback dan mengembalikan respons callback
+func oidcLogin(t *testing.T, app *fiber.App) *http.Response {
+	t.Helper()
+	resp, _ := app.Test(httptest.NewRequest("GET", "/api/oidc/login", nil))
+	if resp.StatusCode != fiber.StatusFound {
+		t.Fatalf("login: expected 302, got %v", resp.StatusCode)
+	}
+	var stateCookie *http.Cookie
+	for _, ck := range resp.Cookies() {
+		if ck.Name == oidcStateCookie {
+			stateCookie = ck
+		}
+	}
+	if stateCookie == nil {
+		t.Fatal("cookie state tidak diset")
+	}
+
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	idpResp, err := noFollow.Get(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idpResp.Body.Close()
+	back, _ := url.Parse(idpResp.Header.Get("Location"))
+
+	req := httptest.NewRequest("GET", "/api/oidc/callback?"+back.RawQuery, nil)
+	req.AddCookie(stateCookie)
+	resp, _ = app.Test(req)
+	return resp
+}
+
+func newOIDCTestApp(t *testing.T, accounts *fakeOIDCAccounts, autoProvision bool) (*fiber.App, *fakeIssuer) {
+	t.Helper()
+	idp, err := oidcmock.New("", "alumni-api", oidcmock.User{
+		Subject: "sub-1", Email: "budi@kampus.ac.id", EmailVerified: true, PreferredUsername: "budi.s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(idp.Handler())
+	t.Cleanup(srv.Close)
+	idp.Issuer = srv.URL
+
+	issuer := &fakeIssuer{}
+	svc := &OIDCService{
+		Provider:      utils.NewOIDCClient(srv.URL, "alumni-api", "", "http://localhost:3000/api/oidc/callback", []string{"openid", "email"}),
+		Store:         &fakeOIDCStore{states: map[string]*models.OIDCLoginState{}, links: map[string]*models.OIDCLink{}},
+		Accounts:      accounts,
+		Tokens:        issuer,
+		AutoProvision: autoProvision,
+		DefaultRole:   "alumni",
+		StateTTL:      10 * time.Minute,
+	}
+	app := fiber.New()
+	app.Get("/api/oidc/login", svc.OIDCLogin)
+	app.Get("/api/oidc/callback", svc.OIDCCallback)
+	return app, issuer
+}
+
+func TestOIDCLinksExistingAccountByEmail(t *testing.T) {
+	accounts := &fakeOIDCAccounts{users: []*models.Identity{
+		{Backend: models.BackendPostgres, ID: "7", Username: "budi", Email: "budi@kampus.ac.id", Role: "alumni", EmailVerified: true},
+	}}
+	app, issuer := newOIDCTestApp(t, accounts, false)
+
+	resp := oidcLogin(t, app)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %v", resp.StatusCode)
+	}
+	var out LoginResponse
+	json.NewDecoder(resp.Body).Decode(&out)
+	if out.Token != "access" || issuer.issued.ID != "7" {
+		t.Errorf("token harus diterbitkan untuk akun 7, got %+v", issuer.issued)
+	}
+
+	// login kedua lewat tautan yang sudah tersimpan
+	accounts.users[0].Email = "email-lama@kampus.ac.id"
+	if resp := oidcLogin(t, app); resp.StatusCode != 200 {
+		t.Fatalf("login lewat tautan: expected 200, got %v", resp.StatusCode)
+	}
+}
+
+func TestOIDCUnknownUserWithoutAutoProvision(t *testing.T) {
+	app, _ := newOIDCTestApp(t, &fakeOIDCAccounts{}, false)
+	if resp := oidcLogin(t, app); resp.StatusCode != 403 {
+		t.Fatalf("expected 403, got %v", resp.StatusCode)
+	}
+}
+
+func TestOIDCAutoProvision(t *testing.T) {
+	accounts := &fakeOIDCAccounts{}
+	app, issuer := newOIDCTestApp(t, accounts, true)
+	if resp := oidcLogin(t, app); resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %v", resp.StatusCode)
+	}
+	if len(accounts.users) != 1 || accounts.users[0].Username != "budi.s" || accounts.users[0].Role != "alumni" {
+		t.Fatalf("akun tidak dibuat dengan benar: %+v", accounts.users)
+	}
+	if issuer.issued.ID != "100" {
+		t.Errorf("token harus untuk akun baru, got %+v", issuer.issued)
+	}
+}
+
+func TestOIDCRefusesUnverifiedLocalAccount(t *testing.T) {
+	accounts := &fakeOIDCAccounts{users: []*models.Identity{
+		{Backend: models.BackendPostgres, ID: "7", Username: "budi", Email: "budi@kampus.ac.id", EmailVerified: false},
+	}}
+	app, _ := newOIDCTestApp(t, accounts, true)
+	if resp := oidcLogin(t, app); resp.StatusCode != 409 {
+		t.Fatalf("expected 409, got %v", resp.StatusCode)
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	app, _ := newOIDCTestApp(t, &fakeOIDCAccounts{}, false)
+	req := httptest.NewRequest("GET", "/api/oidc/callback?code=x&state=abc", nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "lain"})
+	resp, _ := app.Test(req)
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected 400, got %v", resp.StatusCode)
+	}
+}
+
+func TestOIDCRefusesDisabledAccount(t *testing.T) {
+	accounts := &fakeOIDCAccounts{users: []*models.Identity{
+		{Backend: models.BackendPostgres, ID: "7", Username: "budi", Email: "budi@kampus.ac.id", EmailVerified: true, Disabled: true},
+	}}
+	app, issuer := newOIDCTestApp(t, accounts, false)
+	if resp := oidcLogin(t, app); resp.StatusCode != 403 {
+		t.Fatalf("expected 403, got %v", resp.StatusCode)
+	}
+	if issuer.issued.ID != "" {
+		t.Errorf("token tidak boleh diterbitkan, got %+v", issuer.issued)
+	}
+}
+
+func TestOIDCRefusesAccountDisabledAtTokenIssue(t *testing.T) {
+	accounts := &fakeOIDCAccounts{users: []*models.Identity{
+		{Backend: models.BackendPostgres, ID: "7", Username: "budi", Email: "budi@kampus.ac.id", EmailVerified: true},
+	}}
+	app, issuer := newOIDCTestApp(t, accounts, false)
+	// TokenService membaca status akun terkini dan menolak akun yang sudah dinonaktifkan
+	issuer.err = models.ErrAccountDisabled
+
+	resp := oidcLogin(t, app)
+	if resp.StatusCode != 403 {
+		t.Fatalf("expected 403, got %v", resp.StatusCode)
+	}
+	var out map[string]string
+	json.NewDecoder(resp.Body).Decode(&out)
+	if out["error"] != models.ErrAccountDisabled.Error() {
+		t.Errorf("expected disabled message, got %q", out["error"])
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+// AccountStore adalah Store yang juga bisa mengganti password (Postgres & Mongo)
+type AccountStore interface {
+	Store
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+}
+
+type ResetTokenStore interface {
+	Create(ctx context.Context, t *models.PasswordResetToken) error
+	Consume(ctx context.Context, hash string) (*models.PasswordResetToken, error)
+}
+
+// SessionRevoker mencabut semua refresh token user setelah password berubah
+type SessionRevoker interface {
+	RevokeAllForUser(ctx context.Context, backend, userID string) error
+}
+
+type PasswordService struct {
+	// Stores dicari berurutan saat forgot password; token mencatat backend asal akun
+	Stores    []AccountStore
+	Tokens    ResetTokenStore
+	Sessions  SessionRevoker
+	Mailer    utils.Mailer
+	PublicURL string
+	TTL       time.Duration
+}
+
+func (s *PasswordService) storeFor(backend string) AccountStore {
+	for _, st := range s.Stores {
+		if st.Backend() == backend {
+			return st
+		}
+	}
+	return nil
+}
+
+// ForgotPassword godoc
+// @Summary Minta link reset password
+// @Description Mengirim link reset ke email jika akun ada. Respons selalu sama supaya email terdaftar tidak bisa ditebak.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /password/forgot [post]
+func (s *PasswordService) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "email wajib"})
+	}
+
+	genericResp := fiber.Map{"message": "jika email terdaftar, link reset password sudah dikirim"}
+
+	var id *models.Identity
+	for _, st := range s.Stores {
+		found, _, err := st.FindIdentity(c.UserContext(), req.Email)
+		if err == nil && strings.EqualFold(found.Email, req.Email) {
+			id = found
+			break
+		}
+	}
+	if id == nil {
+		return c.JSON(genericResp)
+	}
+
+	if err := s.SendResetLink(c.UserContext(), *id); err != nil {
+		if !errors.Is(err, errResetMail) {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal membuat token reset"})
+		}
+		// respons tetap generik, kegagalan kirim cukup dicatat
+		log.Printf("gagal kirim email reset ke %s: %v", id.Email, err)
+	}
+	return c.JSON(genericResp)
+}
+
+// errResetMail: token sudah tersimpan tapi email gagal dikirim
+var errResetMail = errors.New("gagal kirim email reset")
+
+// SendResetLink membuat token reset dan mengirim link ke email akun.
+// Dipakai lupa password dan reset paksa oleh admin (/api/admin/users/:id/reset-password).
+func (s *PasswordService) SendResetLink(ctx context.Context, id models.Identity) error {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.Tokens.Create(ctx, &models.PasswordResetToken{
+		Backend:   id.Backend,
+		UserID:    id.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.PublicURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk mengatur ulang password (berlaku %d menit):\n%s\n\nAbaikan email ini jika kamu tidak meminta reset password.\n",
+		id.Username, int(s.TTL.Minutes()), link)
+	if err := s.Mailer.Send(id.Email, "Reset password", body); err != nil {
+		return fmt.Errorf("%w: %v", errResetMail, err)
+	}
+	return nil
+}
+
+// ResetPassword godoc
+// @Summary Reset password dengan token dari email
+// @Description Token hanya bisa dipakai sekali. Semua sesi login user dicabut setelah reset.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Token & password baru"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /password/reset [post]
+func (s *PasswordService) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "token & new_password wajib"})
+	}
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	t, err := s.Tokens.Consume(c.UserContext(), utils.HashToken(req.Token))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "token tidak valid atau kedaluwarsa"})
+	}
+
+	store := s.storeFor(t.Backend)
+	if store == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "backend akun tidak tersedia"})
+	}
+	hash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
+	}
+	if err := store.UpdatePassword(c.UserContext(), t.UserID, hash); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update password"})
+	}
+	if s.Sessions != nil {
+		if err := s.Sessions.RevokeAllForUser(c.UserContext(), t.Backend, t.UserID); err != nil {
+			log.Printf("gagal cabut sesi %s:%s: %v", t.Backend, t.UserID, err)
+		}
+	}
+	return c.JSON(fiber.Map{"message": "password berhasil direset, silakan login ulang"})
+}
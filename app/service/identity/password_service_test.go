@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+type fakeAccountStore struct {
+	fakeStore
+	updated map[string]string
+}
+
+func (f *fakeAccountStore) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	f.updated[userID] = passwordHash
+	return nil
+}
+
+type fakeResetTokens struct {
+	tokens map[string]*models.PasswordResetToken
+}
+
+func (f *fakeResetTokens) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	f.tokens[t.TokenHash] = t
+	return nil
+}
+
+func (f *fakeResetTokens) Consume(ctx context.Context, hash string) (*models.PasswordResetToken, error) {
+	t, ok := f.tokens[hash]
+	if !ok || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, sql.ErrNoRows
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return t, nil
+}
+
+type fakeRevoker struct {
+	revoked []string
+}
+
+func (f *fakeRevoker) RevokeAllForUser(ctx context.Context, backend, userID string) error {
+	f.revoked = append(f.revoked, backend+":"+userID)
+	return nil
+}
+
+func postJSON(app *fiber.App, route, body string) int {
+	req := httptest.NewRequest("POST", route, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp.StatusCode
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	mongoStore := &fakeAccountStore{
+		fakeStore: fakeStore{
+			backend: models.BackendMongo,
+			users:   map[string]*models.Identity{"siti@mail.com": {Backend: models.BackendMongo, ID: "abc123", Username: "siti", Email: "siti@mail.com"}},
+		},
+		updated: map[string]string{},
+	}
+	pgStore := &fakeAccountStore{fakeStore: fakeStore{backend: models.BackendPostgres, users: map[string]*models.Identity{}}, updated: map[string]string{}}
+	mailer := &utils.OutboxMailer{Dir: t.TempDir()}
+	revoker := &fakeRevoker{}
+
+	svc := &PasswordService{
+		Stores:    []AccountStore{pgStore, mongoStore},
+		Tokens:    &fakeResetTokens{tokens: map[string]*models.PasswordResetToken{}},
+		Sessions:  revoker,
+		Mailer:    mailer,
+		PublicURL: "http://app.test",
+		TTL:       time.Minute,
+	}
+	app := fiber.New()
+	app.Post("/forgot", svc.ForgotPassword)
+	app.Post("/reset", svc.ResetPassword)
+
+	if code := postJSON(app, "/forgot", `{"email":"siti@mail.com"}`); code != 200 {
+		t.Fatalf("expected 200, got %v", code)
+	}
+	msg, ok := mailer.Last()
+	if !ok || msg.To != "siti@mail.com" {
+		t.Fatalf("expected reset email to siti@mail.com, got %+v", msg)
+	}
+
+	start := strings.Index(msg.Body, "token=")
+	raw := strings.Fields(msg.Body[start+len("token="):])[0]
+	token, _ := url.QueryUnescape(raw)
+
+	if code := postJSON(app, "/reset", `{"token":"`+token+`","new_password":"passwordbaru"}`); code != 200 {
+		t.Fatalf("expected 200 on reset, got %v", code)
+	}
+	if !utils.CheckPassword("passwordbaru", mongoStore.updated["abc123"]) {
+		t.Errorf("password mongo user tidak terupdate")
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != "mongo:abc123" {
+		t.Errorf("expected sessions revoked, got %v", revoker.revoked)
+	}
+
+	// token hanya sekali pakai
+	if code := postJSON(app, "/reset", `{"token":"`+token+`","new_password":"passwordlain"}`); code != 400 {
+		t.Errorf("expected 400 on reused token, got %v", code)
+	}
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	mailer := &utils.OutboxMailer{Dir: t.TempDir()}
+	svc := &PasswordService{
+		Stores: []AccountStore{&fakeAccountStore{fakeStore: fakeStore{backend: models.BackendPostgres, users: map[string]*models.Identity{}}}},
+		Tokens: &fakeResetTokens{tokens: map[string]*models.PasswordResetToken{}},
+		Mailer: mailer,
+		TTL:    time.Minute,
+	}
+	app := fiber.New()
+	app.Post("/forgot", svc.ForgotPassword)
+
+	if code := postJSON(app, "/forgot", `{"email":"ghost@mail.com"}`); code != 200 {
+		t.Errorf("expected generic 200, got %v", code)
+	}
+	if _, ok := mailer.Last(); ok {
+		t.Errorf("no email should be sent for unknown account")
+	}
+}
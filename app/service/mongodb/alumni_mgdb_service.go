@@ -54,6 +54,7 @@ func (s *AlumniMongoService) GetAll(ctx context.Context) ([]models.AlumniMongo,
 // @Produce json
 // @Param id path string true "Alumni ID"
 // @Success 200 {object} models.AlumniMongo
+// @Header 200 {string} ETag "Versi dokumen, kirim sebagai If-Match saat update / hapus"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /alumni-mongo/{id} [get]
@@ -63,21 +64,24 @@ func (s *AlumniMongoService) GetByID(ctx context.Context, id string) (*models.Al
 
 // Update godoc
 // @Summary Update data alumni
-// @Description Mengubah data alumni berdasarkan ID
+// @Description Mengubah data alumni berdasarkan ID. If-Match yang sudah basi dibalas 412 berisi data terbaru.
 // @Tags Alumni-Mongo
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Alumni ID"
+// @Param If-Match header string false "ETag dari GET /alumni-mongo/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Param request body models.AlumniMongo true "Data alumni baru"
 // @Success 200 {object} models.AlumniMongo
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.AlumniMongo
+// @Failure 428 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /alumni-mongo/{id} [put]
-func (s *AlumniMongoService) Update(ctx context.Context, id string, data *models.AlumniMongo) (*models.AlumniMongo, error) {
+func (s *AlumniMongoService) Update(ctx context.Context, id string, data *models.AlumniMongo, version int) (*models.AlumniMongo, error) {
 	data.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, id, data)
+	return s.repo.Update(ctx, id, data, version)
 }
 
 // Delete godoc
@@ -87,10 +91,13 @@ func (s *AlumniMongoService) Update(ctx context.Context, id string, data *models
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Alumni ID"
+// @Param If-Match header string false "ETag dari GET /alumni-mongo/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 204 "No Content"
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.AlumniMongo
+// @Failure 428 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /alumni-mongo/{id} [delete]
-func (s *AlumniMongoService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *AlumniMongoService) Delete(ctx context.Context, id string, version int) error {
+	return s.repo.Delete(ctx, id, version)
 }
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go_clean/app/repository/mongodb"
 )
 
@@ -59,7 +61,7 @@ func TestDeleteAlumni(t *testing.T) {
 	data := &models.AlumniMongo{Nama: "Delete Test"}
 	created, _ := svc.Create(ctx, data)
 
-	err := svc.Delete(ctx, created.ID.Hex())
+	err := svc.Delete(ctx, created.ID.Hex(), pgModel.AnyVersion)
 	if err != nil {
 		t.Errorf("unexpected error on delete: %v", err)
 	}
@@ -69,3 +71,36 @@ func TestDeleteAlumni(t *testing.T) {
 		t.Errorf("expected error after delete, got nil")
 	}
 }
+
+func TestUpdateAlumniVersion(t *testing.T) {
+	mockRepo := repository.NewMockAlumniMongoRepository()
+	svc := NewAlumniMongoService(mockRepo)
+	ctx := context.Background()
+
+	created, _ := svc.Create(ctx, &models.AlumniMongo{Nama: "Versi"})
+	if created.Version != 1 {
+		t.Fatalf("expected version 1 after create, got %d", created.Version)
+	}
+
+	updated, err := svc.Update(ctx, created.ID.Hex(), &models.AlumniMongo{Nama: "Versi 2"}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("expected version 2, got %d", updated.Version)
+	}
+
+	// If-Match lama (versi 1) harus ditolak, data tidak berubah
+	_, err = svc.Update(ctx, created.ID.Hex(), &models.AlumniMongo{Nama: "Basi"}, 1)
+	if !errors.Is(err, repository.ErrNotMatched) {
+		t.Errorf("expected ErrNotMatched, got %v", err)
+	}
+	if err := svc.Delete(ctx, created.ID.Hex(), 1); !errors.Is(err, repository.ErrNotMatched) {
+		t.Errorf("expected ErrNotMatched on stale delete, got %v", err)
+	}
+
+	current, _ := svc.GetByID(ctx, created.ID.Hex())
+	if current.Nama != "Versi 2" {
+		t.Errorf("expected Versi 2, got %v", current.Nama)
+	}
+}
@@ -1,18 +1,41 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
+	"go_clean/middleware"
 	// "go_clean/app/repository/mongodb"
 	"go_clean/utils"
 )
 
 type AuthMongoRepo interface {
-    FindByUsernameOrEmail(identifier string) (*models.LoginMongo, error)
+    FindByUsernameOrEmail(ctx context.Context, identifier string) (*models.LoginMongo, error)
+}
+
+// PasswordUpdater opsional di repo: dipakai untuk rehash password lama setelah login berhasil
+type PasswordUpdater interface {
+    UpdatePassword(ctx context.Context, userID, passwordHash string) error
+}
+
+// TokenIssuer menerbitkan pasangan access + refresh token (refresh token disimpan di Postgres)
+type TokenIssuer interface {
+    IssueTokenPair(ctx context.Context, id pgModel.Identity, meta pgModel.SessionMeta) (*pgModel.TokenPair, error)
+}
+
+// MFAGate memutuskan apakah login perlu langkah 2FA (challenge nil = tidak perlu)
+type MFAGate interface {
+    Begin(ctx context.Context, id pgModel.Identity) (*pgModel.MFAChallenge, error)
 }
 
 type AuthMongoService struct {
-    Repo AuthMongoRepo
+    Repo   AuthMongoRepo
+    Tokens TokenIssuer
+    MFA    MFAGate
+    Guard  *middleware.LoginGuard
 }
 
 
@@ -25,7 +48,10 @@ type AuthMongoService struct {
 // @Produce json
 // @Param request body models.LoginRequest true "Login Data"
 // @Success 200 {object} models.LoginResponse
+// @Success 202 {object} pgModel.MFAChallenge
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Router /login-mongo [post]
 func (s *AuthMongoService) Login(c *fiber.Ctx) error {
     var req models.LoginRequest
@@ -34,24 +60,54 @@ func (s *AuthMongoService) Login(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "invalid payload"})
     }
 
-    user, err := s.Repo.FindByUsernameOrEmail(req.Username)
+    if b := s.Guard.CheckLogin(req.Username, c.IP()); b != nil {
+        return b.Respond(c)
+    }
+
+    user, err := s.Repo.FindByUsernameOrEmail(c.UserContext(), req.Username)
     if err != nil {
+        s.Guard.FailLogin(req.Username, c.IP())
         return c.Status(401).JSON(fiber.Map{"error": "username/password salah"})
     }
 
     // cek plain password vs hash
     if !utils.CheckPassword(req.Password, user.PasswordHash) {
+        s.Guard.FailLogin(req.Username, c.IP())
         return c.Status(401).JSON(fiber.Map{"error": "username/password salah"})
     }
+    s.Guard.SucceedLogin(req.Username, c.IP())
+    if updater, ok := s.Repo.(PasswordUpdater); ok {
+        utils.RehashIfNeeded(req.Password, user.PasswordHash, func(newHash string) error {
+            return updater.UpdatePassword(c.UserContext(), user.Identity().ID, newHash)
+        })
+    }
+
+    // status nonaktif baru dibuka setelah password benar
+    if user.Disabled {
+        return c.Status(403).JSON(fiber.Map{"error": pgModel.ErrAccountDisabled.Error()})
+    }
 
-    token, err := utils.GenerateTokenMongo(*user)
+    if s.MFA != nil {
+        challenge, err := s.MFA.Begin(c.UserContext(), user.Identity())
+        if err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "gagal cek 2FA"})
+        }
+        if challenge != nil {
+            return c.Status(fiber.StatusAccepted).JSON(challenge)
+        }
+    }
+
+    pair, err := s.Tokens.IssueTokenPair(c.UserContext(), user.Identity(), middleware.SessionMeta(c))
+    if errors.Is(err, pgModel.ErrAccountDisabled) {
+        return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+    }
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "gagal membuat token"})
     }
-
     return c.JSON(fiber.Map{
-        "user":  user,
-        "token": token,
+        "user":          user,
+        "token":         pair.Token,
+        "refresh_token": pair.RefreshToken,
     })
 }
 
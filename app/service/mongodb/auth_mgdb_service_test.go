@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
     "testing"
     "go_clean/app/models/mongodb"
+    pgModel "go_clean/app/models/postgresql"
     "go_clean/app/repository/mongodb"
     "go_clean/utils"
     "net/http/httptest"
@@ -10,6 +12,7 @@ import (
     "encoding/json"
     "strings"
     "github.com/gofiber/fiber/v2"
+    "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 
@@ -22,7 +25,7 @@ func newTestRequest(app *fiber.App, route string, body interface{}) (*http.Respo
 
 func TestLoginSuccess(t *testing.T) {
     mockRepo := repository.NewMockUserMongoRepository()
-    svc := &AuthMongoService{Repo: mockRepo}
+    svc := &AuthMongoService{Repo: mockRepo, Tokens: &fakeTokenIssuer{}}
 
     hash, _ := utils.HashPassword("123456")
 
@@ -33,12 +36,6 @@ func TestLoginSuccess(t *testing.T) {
         Role:         "admin",
     })
 
-    // mock token generator
-    utils.MockGenerateTokenMongo = func(u models.LoginMongo) (string, error) {
-        return "mock-token", nil
-    }
-    defer func() { utils.MockGenerateTokenMongo = nil }()
-
     app := fiber.New()
     app.Post("/login-mongo", svc.Login)
 
@@ -95,7 +92,8 @@ func TestLoginUserNotFound(t *testing.T) {
 
 func TestLoginTokenError(t *testing.T) {
     mockRepo := repository.NewMockUserMongoRepository()
-    svc := &AuthMongoService{Repo: mockRepo}
+    // token generator bermasalah
+    svc := &AuthMongoService{Repo: mockRepo, Tokens: &fakeTokenIssuer{err: fiber.ErrInternalServerError}}
 
     hash, _ := utils.HashPassword("123456")
 
@@ -104,12 +102,6 @@ func TestLoginTokenError(t *testing.T) {
         PasswordHash: hash,
     })
 
-    // token generator bermasalah
-    utils.MockGenerateTokenMongo = func(u models.LoginMongo) (string, error) {
-        return "", fiber.ErrInternalServerError
-    }
-    defer func() { utils.MockGenerateTokenMongo = nil }()
-
     app := fiber.New()
     app.Post("/login-mongo", svc.Login)
 
@@ -121,3 +113,79 @@ func TestLoginTokenError(t *testing.T) {
         t.Errorf("expected 500 token error, got %v", resp.StatusCode)
     }
 }
+
+type fakeTokenIssuer struct {
+    userID string
+    err    error
+}
+
+func (f *fakeTokenIssuer) IssueTokenPair(ctx context.Context, id pgModel.Identity, meta pgModel.SessionMeta) (*pgModel.TokenPair, error) {
+    if f.err != nil {
+        return nil, f.err
+    }
+    f.userID = id.ID
+    return &pgModel.TokenPair{Token: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+func TestLoginReturnsRefreshToken(t *testing.T) {
+    mockRepo := repository.NewMockUserMongoRepository()
+    issuer := &fakeTokenIssuer{}
+    svc := &AuthMongoService{Repo: mockRepo, Tokens: issuer}
+
+    hash, _ := utils.HashPassword("123456")
+    user := &models.LoginMongo{
+        ID:           primitive.NewObjectID(),
+        Username:     "admin",
+        PasswordHash: hash,
+        Role:         "admin",
+    }
+    mockRepo.InsertUser(user)
+
+    app := fiber.New()
+    app.Post("/login-mongo", svc.Login)
+
+    resp, _ := newTestRequest(app, "/login-mongo", models.LoginRequest{Username: "admin", Password: "123456"})
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("expected 200, got %v", resp.StatusCode)
+    }
+
+    var out map[string]interface{}
+    json.NewDecoder(resp.Body).Decode(&out)
+    if out["refresh_token"] != "refresh" {
+        t.Errorf("expected refresh_token in response, got %v", out["refresh_token"])
+    }
+    if issuer.userID != user.ID.Hex() {
+        t.Errorf("expected subject %v, got %v", user.ID.Hex(), issuer.userID)
+    }
+}
+
+func TestLoginDisabledAccount(t *testing.T) {
+    mockRepo := repository.NewMockUserMongoRepository()
+    issuer := &fakeTokenIssuer{}
+    svc := &AuthMongoService{Repo: mockRepo, Tokens: issuer}
+
+    hash, _ := utils.HashPassword("123456")
+    mockRepo.InsertUser(&models.LoginMongo{
+        ID:           primitive.NewObjectID(),
+        Username:     "admin",
+        PasswordHash: hash,
+        Role:         "admin",
+        Disabled:     true,
+    })
+
+    app := fiber.New()
+    app.Post("/login-mongo", svc.Login)
+
+    resp, _ := newTestRequest(app, "/login-mongo", models.LoginRequest{Username: "admin", Password: "123456"})
+    if resp.StatusCode != http.StatusForbidden {
+        t.Fatalf("expected 403 for disabled account, got %v", resp.StatusCode)
+    }
+    var out map[string]interface{}
+    json.NewDecoder(resp.Body).Decode(&out)
+    if out["error"] != pgModel.ErrAccountDisabled.Error() {
+        t.Errorf("expected disabled message, got %v", out["error"])
+    }
+    if issuer.userID != "" {
+        t.Errorf("token must not be issued for a disabled account")
+    }
+}
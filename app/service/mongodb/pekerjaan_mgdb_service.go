@@ -54,6 +54,7 @@ func (s *PekerjaanMongoService) GetAll(ctx context.Context) ([]models.PekerjaanM
 // @Produce json
 // @Param id path string true "Pekerjaan ID"
 // @Success 200 {object} models.PekerjaanMongo
+// @Header 200 {string} ETag "Versi dokumen, kirim sebagai If-Match saat update / hapus"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /pekerjaan-mongo/{id} [get]
@@ -77,21 +78,24 @@ func (s *PekerjaanMongoService) GetByAlumniID(ctx context.Context, alumniID int)
 
 // Update godoc
 // @Summary Update data pekerjaan
-// @Description Mengubah data pekerjaan berdasarkan ID
+// @Description Mengubah data pekerjaan berdasarkan ID. If-Match yang sudah basi dibalas 412 berisi data terbaru.
 // @Tags Pekerjaan-Mongo
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Pekerjaan ID"
+// @Param If-Match header string false "ETag dari GET /pekerjaan-mongo/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Param request body models.PekerjaanMongo true "Data pekerjaan baru"
 // @Success 200 {object} models.PekerjaanMongo
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanMongo
+// @Failure 428 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /pekerjaan-mongo/{id} [put]
-func (s *PekerjaanMongoService) Update(ctx context.Context, id string, p *models.PekerjaanMongo) (*models.PekerjaanMongo, error) {
+func (s *PekerjaanMongoService) Update(ctx context.Context, id string, p *models.PekerjaanMongo, version int) (*models.PekerjaanMongo, error) {
 	p.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, id, p)
+	return s.repo.Update(ctx, id, p, version)
 }
 
 // Delete godoc
@@ -101,10 +105,13 @@ func (s *PekerjaanMongoService) Update(ctx context.Context, id string, p *models
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Pekerjaan ID"
+// @Param If-Match header string false "ETag dari GET /pekerjaan-mongo/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 204 "No Content"
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanMongo
+// @Failure 428 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /pekerjaan-mongo/{id} [delete]
-func (s *PekerjaanMongoService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *PekerjaanMongoService) Delete(ctx context.Context, id string, version int) error {
+	return s.repo.Delete(ctx, id, version)
 }
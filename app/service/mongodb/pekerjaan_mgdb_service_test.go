@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go_clean/app/repository/mongodb"
 )
 
@@ -82,7 +83,7 @@ func TestUpdatePekerjaan(t *testing.T) {
 		UpdatedAt:     time.Now(),
 	}
 
-	result, err := svc.Update(ctx, created.ID.Hex(), updated)
+	result, err := svc.Update(ctx, created.ID.Hex(), updated, pgModel.AnyVersion)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -100,7 +101,7 @@ func TestDeletePekerjaan(t *testing.T) {
 	p := &models.PekerjaanMongo{NamaPerusahaan: "Delete Me"}
 	created, _ := svc.Create(ctx, p)
 
-	err := svc.Delete(ctx, created.ID.Hex())
+	err := svc.Delete(ctx, created.ID.Hex(), pgModel.AnyVersion)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
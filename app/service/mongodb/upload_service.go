@@ -5,7 +5,9 @@ import (
     "os"
     "path/filepath"
     "go_clean/app/models/mongodb"
+    pgModel "go_clean/app/models/postgresql"
     "go_clean/app/repository/mongodb"
+    "go_clean/middleware"
 
     "github.com/gofiber/fiber/v2"
     "github.com/google/uuid"
@@ -21,15 +23,26 @@ type FileService interface {
 type fileService struct {
     repo       repository.FileRepository
     uploadPath string
+    audit      *middleware.Auditor
 }
 
-func NewFileService(repo repository.FileRepository, uploadPath string) FileService {
+func NewFileService(repo repository.FileRepository, uploadPath string, audit *middleware.Auditor) FileService {
     return &fileService{
         repo:       repo,
         uploadPath: uploadPath,
+        audit:      audit,
     }
 }
 
+// canAccess: pengunggah selalu boleh, user lain butuh permission (files:read_all / files:delete_all)
+func canAccess(c *fiber.Ctx, file *models.File, perm string) bool {
+    subject := middleware.Claims(c).Subject
+    if subject != "" && file.UploadedBy == subject {
+        return true
+    }
+    return middleware.HasPermission(c, perm)
+}
+
 // UploadFile godoc
 // @Summary Upload file (PDF / Image)
 // @Description Mengupload file ke server dan menyimpan metadata ke database (MongoDB)
@@ -100,6 +113,7 @@ func (s *fileService) UploadFile(c *fiber.Ctx) error {
         FilePath:     filePath,
         FileSize:     fileHeader.Size,
         FileType:     contentType,
+        UploadedBy:   middleware.Claims(c).Subject,
     }
 
     if err := s.repo.Create(fileModel); err != nil {
@@ -110,6 +124,7 @@ func (s *fileService) UploadFile(c *fiber.Ctx) error {
             "error":   err.Error(),
         })
     }
+    s.audit.Log(c, pgModel.AuditCreate, pgModel.AuditEntityFile, fileModel.ID.Hex(), nil, fileModel)
 
     return c.Status(fiber.StatusCreated).JSON(fiber.Map{
         "success": true,
@@ -121,6 +136,7 @@ func (s *fileService) UploadFile(c *fiber.Ctx) error {
 
 // GetAllFiles godoc
 // @Summary Mendapatkan semua file yang sudah diupload
+// @Description Dengan izin files:read_all semua file, selain itu hanya file milik sendiri
 // @Tags FileUpload
 // @Security BearerAuth
 // @Produce json
@@ -128,7 +144,13 @@ func (s *fileService) UploadFile(c *fiber.Ctx) error {
 // @Failure 500 {object} models.ErrorResponse
 // @Router /files/ [get]
 func (s *fileService) GetAllFiles(c *fiber.Ctx) error {
-    files, err := s.repo.FindAll()
+    var files []models.File
+    var err error
+    if middleware.HasPermission(c, pgModel.PermFilesReadAll) {
+        files, err = s.repo.FindAll()
+    } else {
+        files, err = s.repo.FindByUploader(middleware.Claims(c).Subject)
+    }
     if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "success": false,
@@ -152,6 +174,7 @@ func (s *fileService) GetAllFiles(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "File ID"
 // @Success 200 {object} models.FileResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /files/{id} [get]
 func (s *fileService) GetFileByID(c *fiber.Ctx) error {
@@ -165,6 +188,12 @@ func (s *fileService) GetFileByID(c *fiber.Ctx) error {
             "error":   err.Error(),
         })
     }
+    if !canAccess(c, file, pgModel.PermFilesReadAll) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "success": false,
+            "message": "Tidak punya izin melihat file ini",
+        })
+    }
 
     return c.JSON(fiber.Map{
         "success": true,
@@ -180,6 +209,7 @@ func (s *fileService) GetFileByID(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "File ID"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /files/{id} [delete]
@@ -194,6 +224,12 @@ func (s *fileService) DeleteFile(c *fiber.Ctx) error {
             "error":   err.Error(),
         })
     }
+    if !canAccess(c, file, pgModel.PermFilesDeleteAll) {
+        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+            "success": false,
+            "message": "Tidak punya izin menghapus file ini",
+        })
+    }
 
     if err := os.Remove(file.FilePath); err != nil {
         fmt.Println("Warning: Failed to delete file:", err)
@@ -206,6 +242,7 @@ func (s *fileService) DeleteFile(c *fiber.Ctx) error {
             "error":   err.Error(),
         })
     }
+    s.audit.Log(c, pgModel.AuditDelete, pgModel.AuditEntityFile, id, file, nil)
 
     return c.JSON(fiber.Map{
         "success": true,
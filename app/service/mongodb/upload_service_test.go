@@ -51,7 +51,7 @@ func TestUploadFile_Success(t *testing.T) {
 	uploadPath := "./test_uploads"
 	defer os.RemoveAll(uploadPath)
 
-	service := NewFileService(mockRepo, uploadPath)
+	service := NewFileService(mockRepo, uploadPath, nil)
 
 	app.Post("/upload", service.UploadFile)
 
@@ -67,7 +67,7 @@ func TestUploadFile_Success(t *testing.T) {
 func TestUploadFile_NoFile(t *testing.T) {
 	app := fiber.New()
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, "./test_uploads")
+	service := NewFileService(mockRepo, "./test_uploads", nil)
 
 	app.Post("/upload", service.UploadFile)
 
@@ -83,7 +83,7 @@ func TestUploadFile_NoFile(t *testing.T) {
 func TestUploadFile_InvalidType(t *testing.T) {
 	app := fiber.New()
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, "./test_uploads")
+	service := NewFileService(mockRepo, "./test_uploads", nil)
 
 	app.Post("/upload", service.UploadFile)
 
@@ -102,7 +102,7 @@ func TestUploadFile_TooLarge(t *testing.T) {
 	})
 
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, "./test_uploads")
+	service := NewFileService(mockRepo, "./test_uploads", nil)
 
 	// Middleware: override ukuran file SETELAH Fiber parsing request
 	app.Use(func(c *fiber.Ctx) error {
@@ -133,7 +133,7 @@ func TestUploadFile_DBError(t *testing.T) {
 	mockRepo := repository.NewMockFileRepository()
 	mockRepo.ForceError = true
 
-	service := NewFileService(mockRepo, "./test_uploads")
+	service := NewFileService(mockRepo, "./test_uploads", nil)
 
 	app.Post("/upload", service.UploadFile)
 
@@ -5,26 +5,32 @@ import (
 	"fmt"
 	"go_clean/app/models/postgresql"
 	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+	"go_clean/middleware"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// AlumniService tidak bergantung backend: Repo adalah repository Postgres atau driver Mongo sesuai DATA_BACKEND.
+// Concurrency menentukan apakah PUT / DELETE wajib membawa If-Match.
 type AlumniService struct {
-	Repo *repository.AlumniRepository
+	Repo        repository.AlumniRepositoryInterface
+	Concurrency config.ConcurrencyConfig
+	Audit       *middleware.Auditor
 }
 
 // GetAllAlumni godoc
-// @Summary Ambil semua data alumni (PostgreSQL)
-// @Description Menampilkan semua alumni dari database PostgreSQL
-// @Tags Alumni-PostgresSQL
+// @Summary Ambil semua data alumni
+// @Description Menampilkan semua alumni (PostgreSQL atau MongoDB sesuai DATA_BACKEND)
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} models.Alumni
 // @Failure 500 {object} models.ErrorResponse
 // @Router /alumni [get]
 func (s *AlumniService) GetAllAlumni(c *fiber.Ctx) error {
-	alumni, err := s.Repo.GetAllAlumni()
+	alumni, err := s.Repo.GetAllAlumni(c.UserContext())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -41,7 +47,7 @@ func (s *AlumniService) GetAllAlumni(c *fiber.Ctx) error {
 // GetAlumniList godoc
 // @Summary Dapatkan alumni dengan pagination, sorting & searching
 // @Description Pagination + sorting + search alumni berdasarkan nama atau NIM
-// @Tags Alumni-PostgresSQL
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Param search query string false "cari nama atau nim"
@@ -58,7 +64,7 @@ func (s *AlumniService) GetAlumniList(c *fiber.Ctx) error {
 		sortable[v] = true
 	}
 	params := getListParams(c, sortable)
-	items, err := repository.ListAlumniRepo(params.Search, params.SortBy, params.Order, params.Limit, params.Offset)
+	items, err := s.Repo.ListAlumniRepo(c.UserContext(), params.Search, params.SortBy, params.Order, params.Limit, params.Offset)
 	if err != nil {
 		fmt.Printf("ListAlumniRepo error: %v\n", err)
 		return c.Status(500).JSON(fiber.Map{
@@ -66,7 +72,7 @@ func (s *AlumniService) GetAlumniList(c *fiber.Ctx) error {
 		})
 	}
 
-	total, err := repository.CountAlumniRepo(params.Search)
+	total, err := s.Repo.CountAlumniRepo(c.UserContext(), params.Search)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to count alumni"})
 	}
@@ -88,12 +94,13 @@ func (s *AlumniService) GetAlumniList(c *fiber.Ctx) error {
 
 // GetAlumniByID godoc
 // @Summary Ambil alumni berdasarkan ID
-// @Description Mengambil satu alumni dari PostgreSQL berdasarkan ID
-// @Tags Alumni-PostgresSQL
+// @Description Mengambil satu alumni berdasarkan ID
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Param id path int true "ID Alumni"
 // @Success 200 {object} models.Alumni
+// @Header 200 {string} ETag "Versi data, kirim sebagai If-Match saat update / hapus"
 // @Failure 404 {object} models.ErrorResponse
 // @Router /alumni/{id} [get]
 func (s *AlumniService) GetAlumniByID(c *fiber.Ctx) error {
@@ -105,7 +112,7 @@ func (s *AlumniService) GetAlumniByID(c *fiber.Ctx) error {
 		})
 	}
 
-	alumni, err := s.Repo.GetAlumniByID(id)
+	alumni, err := s.Repo.GetAlumniByID(c.UserContext(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -118,6 +125,7 @@ func (s *AlumniService) GetAlumniByID(c *fiber.Ctx) error {
 			"message": "Gagal mengambil data alumni: " + err.Error(),
 		})
 	}
+	middleware.SetETag(c, alumni.Version)
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Data alumni berhasil diambil",
@@ -128,7 +136,7 @@ func (s *AlumniService) GetAlumniByID(c *fiber.Ctx) error {
 // GetAlumniByAngkatan godoc
 // @Summary Ambil alumni berdasarkan angkatan
 // @Description Mengambil jumlah alumni berdasarkan angkatan tertentu
-// @Tags Alumni-PostgresSQL
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Param angkatan path int true "Tahun angkatan"
@@ -144,7 +152,7 @@ func (s *AlumniService) GetAlumniByAngkatan(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := s.Repo.GetAlumniByAngkatan(angkatan)
+	result, err := s.Repo.GetAlumniByAngkatan(c.UserContext(), angkatan)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -161,8 +169,8 @@ func (s *AlumniService) GetAlumniByAngkatan(c *fiber.Ctx) error {
 
 // GetAlumniAndPekerjaan godoc
 // @Summary Ambil alumni beserta data pekerjaan
-// @Description Join data alumni & pekerjaan dari PostgreSQL
-// @Tags Alumni-PostgresSQL
+// @Description Join data alumni & pekerjaan
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Param nim path int true "NIM Alumni"
@@ -186,7 +194,7 @@ func (s *AlumniService) GetAlumniAndPekerjaan(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := s.Repo.GetAlumniAndPekerjaan(id)
+	result, err := s.Repo.GetAlumniAndPekerjaan(c.UserContext(), id)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -203,8 +211,8 @@ func (s *AlumniService) GetAlumniAndPekerjaan(c *fiber.Ctx) error {
 
 // CreateAlumni godoc
 // @Summary Tambah alumni baru
-// @Description Insert data alumni ke database PostgreSQL
-// @Tags Alumni-PostgresSQL
+// @Description Insert data alumni ke database sesuai DATA_BACKEND
+// @Tags Alumni
 // @Security BearerAuth
 // @Accept json
 // @Produce json
@@ -229,7 +237,7 @@ func (s *AlumniService) CreateAlumni(c *fiber.Ctx) error {
 		})
 	}
 
-	newID, err := s.Repo.CreateAlumni(&alumni)
+	newID, err := s.Repo.CreateAlumni(c.UserContext(), &alumni)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -237,7 +245,8 @@ func (s *AlumniService) CreateAlumni(c *fiber.Ctx) error {
 		})
 	}
 
-	newAlumni, _ := s.Repo.GetAlumniByID(newID)
+	newAlumni, _ := s.Repo.GetAlumniByID(c.UserContext(), newID)
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityAlumni, strconv.Itoa(newID), nil, newAlumni)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Alumni berhasil ditambahkan",
@@ -247,16 +256,20 @@ func (s *AlumniService) CreateAlumni(c *fiber.Ctx) error {
 
 // UpdateAlumni godoc
 // @Summary Update alumni
-// @Description Mengubah data alumni berdasarkan ID
-// @Tags Alumni-PostgresSQL
+// @Description Mengubah data alumni berdasarkan ID. If-Match berisi ETag dari GET; kalau data sudah diubah
+// @Description pengguna lain dibalas 412 berisi data terbaru.
+// @Tags Alumni
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path int true "ID Alumni"
+// @Param If-Match header string false "ETag dari GET /alumni/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Param request body models.Alumni true "Data alumni baru"
 // @Success 200 {object} models.Alumni
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.Alumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /alumni/{id} [put]
 func (s *AlumniService) UpdateAlumni(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -275,7 +288,15 @@ func (s *AlumniService) UpdateAlumni(c *fiber.Ctx) error {
 		})
 	}
 
-	rowsAffected, err := s.Repo.UpdateAlumni(id, &alumni)
+	version, err := middleware.IfMatch(c, s.Concurrency)
+	if err != nil {
+		return ifMatchFailed(c, err)
+	}
+
+	// nilai lama untuk audit log
+	before, _ := s.Repo.GetAlumniByID(c.UserContext(), id)
+
+	rowsAffected, err := s.Repo.UpdateAlumni(c.UserContext(), id, &alumni, version)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -283,13 +304,21 @@ func (s *AlumniService) UpdateAlumni(c *fiber.Ctx) error {
 		})
 	}
 	if rowsAffected == 0 {
+		// 0 baris: id tidak ada, atau version di If-Match sudah basi
+		if current, err := s.Repo.GetAlumniByID(c.UserContext(), id); err == nil && staleVersion(version, current.Version) {
+			return versionConflict(c, current.Version, current)
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"message": "Alumni tidak ditemukan untuk diupdate",
 		})
 	}
 
-	updatedAlumni, _ := s.Repo.GetAlumniByID(id)
+	updatedAlumni, _ := s.Repo.GetAlumniByID(c.UserContext(), id)
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityAlumni, strconv.Itoa(id), before, updatedAlumni)
+	if updatedAlumni != nil {
+		middleware.SetETag(c, updatedAlumni.Version)
+	}
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Alumni berhasil diupdate",
@@ -299,13 +328,16 @@ func (s *AlumniService) UpdateAlumni(c *fiber.Ctx) error {
 
 // DeleteAlumni godoc
 // @Summary Hapus alumni
-// @Description Menghapus data alumni berdasarkan ID
-// @Tags Alumni-PostgresSQL
+// @Description Menghapus data alumni berdasarkan ID. Kalau If-Match sudah basi dibalas 412 berisi data terbaru.
+// @Tags Alumni
 // @Security BearerAuth
 // @Produce json
 // @Param id path int true "ID Alumni"
+// @Param If-Match header string false "ETag dari GET /alumni/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 204 "No Content"
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.Alumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /alumni/{id} [delete]
 func (s *AlumniService) DeleteAlumni(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -316,7 +348,14 @@ func (s *AlumniService) DeleteAlumni(c *fiber.Ctx) error {
 		})
 	}
 
-	rowsAffected, err := s.Repo.DeleteAlumni(id)
+	version, err := middleware.IfMatch(c, s.Concurrency)
+	if err != nil {
+		return ifMatchFailed(c, err)
+	}
+
+	before, _ := s.Repo.GetAlumniByID(c.UserContext(), id)
+
+	rowsAffected, err := s.Repo.DeleteAlumni(c.UserContext(), id, version)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -324,12 +363,16 @@ func (s *AlumniService) DeleteAlumni(c *fiber.Ctx) error {
 		})
 	}
 	if rowsAffected == 0 {
+		if current, err := s.Repo.GetAlumniByID(c.UserContext(), id); err == nil && staleVersion(version, current.Version) {
+			return versionConflict(c, current.Version, current)
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"message": "Alumni tidak ditemukan untuk dihapus",
 		})
 	}
 
+	s.Audit.Log(c, models.AuditDelete, models.AuditEntityAlumni, strconv.Itoa(id), before, nil)
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Alumni berhasil dihapus",
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+)
+
+func newAlumniApp(svc *AlumniService) *fiber.App {
+	app := fiber.New()
+	app.Get("/alumni", svc.GetAllAlumni)
+	app.Get("/alumni-pag", svc.GetAlumniList)
+	app.Get("/alumni/angkatan/:angkatan", svc.GetAlumniByAngkatan)
+	app.Get("/alumni/detail/:nim", svc.GetAlumniAndPekerjaan)
+	app.Get("/alumni/:id", svc.GetAlumniByID)
+	app.Post("/alumni", svc.CreateAlumni)
+	app.Put("/alumni/:id", svc.UpdateAlumni)
+	app.Delete("/alumni/:id", svc.DeleteAlumni)
+	return app
+}
+
+func TestAlumniCRUD(t *testing.T) {
+	repo := repository.NewMockAlumniRepository()
+	app := newAlumniApp(&AlumniService{Repo: repo})
+
+	if code := call(t, app, "POST", "/alumni", `{"nim":"123","nama":"Budi"}`, nil); code != 400 {
+		t.Fatalf("field wajib kosong: status %d, want 400", code)
+	}
+
+	var created struct {
+		Data models.Alumni `json:"data"`
+	}
+	body := `{"nim":"123","nama":"Budi","jurusan":"TI","angkatan":2019,"tahun_lulus":2023,"email":"budi@example.com"}`
+	if code := call(t, app, "POST", "/alumni", body, &created); code != 201 {
+		t.Fatalf("create: status %d, want 201", code)
+	}
+	if created.Data.ID == 0 || created.Data.Nama != "Budi" {
+		t.Fatalf("create mengembalikan %+v", created.Data)
+	}
+
+	var got struct {
+		Data models.Alumni `json:"data"`
+	}
+	if code := call(t, app, "GET", "/alumni/1", "", &got); code != 200 || got.Data.NIM != "123" {
+		t.Fatalf("get: status %d, data %+v", code, got.Data)
+	}
+	if code := call(t, app, "GET", "/alumni/99", "", nil); code != 404 {
+		t.Fatalf("get id tidak ada: status %d, want 404", code)
+	}
+
+	update := `{"nama":"Budi Santoso","jurusan":"TI","angkatan":2019,"tahun_lulus":2023,"email":"budi@example.com"}`
+	if code := call(t, app, "PUT", "/alumni/1", update, &got); code != 200 || got.Data.Nama != "Budi Santoso" {
+		t.Fatalf("update: status %d, data %+v", code, got.Data)
+	}
+	if code := call(t, app, "PUT", "/alumni/99", update, nil); code != 404 {
+		t.Fatalf("update id tidak ada: status %d, want 404", code)
+	}
+
+	if code := call(t, app, "DELETE", "/alumni/1", "", nil); code != 200 {
+		t.Fatalf("delete: status %d, want 200", code)
+	}
+	if code := call(t, app, "DELETE", "/alumni/1", "", nil); code != 404 {
+		t.Fatalf("delete kedua: status %d, want 404", code)
+	}
+	if len(repo.Data) != 0 {
+		t.Fatalf("data masih tersisa: %d", len(repo.Data))
+	}
+}
+
+func TestAlumniListSearchSortAndPaging(t *testing.T) {
+	repo := repository.NewMockAlumniRepository()
+	seedAlumni(repo,
+		models.Alumni{NIM: "001", Nama: "Andi", Angkatan: 2018},
+		models.Alumni{NIM: "002", Nama: "Citra", Angkatan: 2019},
+		models.Alumni{NIM: "003", Nama: "Bayu", Angkatan: 2019},
+		models.Alumni{NIM: "104", Nama: "Dewi", Angkatan: 2020},
+	)
+	app := newAlumniApp(&AlumniService{Repo: repo})
+
+	var resp models.UserResponse[models.Alumni]
+	if code := call(t, app, "GET", "/alumni-pag?search=00&sortBy=nama&order=desc&limit=2", "", &resp); code != 200 {
+		t.Fatalf("status %d", code)
+	}
+	if resp.Meta.Total != 3 || resp.Meta.Pages != 2 {
+		t.Fatalf("meta = %+v, want total 3, pages 2", resp.Meta)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].Nama != "Citra" || resp.Data[1].Nama != "Bayu" {
+		t.Fatalf("halaman 1 = %+v", resp.Data)
+	}
+
+	call(t, app, "GET", "/alumni-pag?search=00&sortBy=nama&order=desc&limit=2&page=2", "", &resp)
+	if len(resp.Data) != 1 || resp.Data[0].Nama != "Andi" {
+		t.Fatalf("halaman 2 = %+v", resp.Data)
+	}
+
+	// kolom di luar whitelist jatuh ke id
+	call(t, app, "GET", "/alumni-pag?sortBy=password", "", &resp)
+	if resp.Meta.SortBy != "id" || resp.Data[0].NIM != "001" {
+		t.Fatalf("sortBy tidak di-whitelist: meta %+v", resp.Meta)
+	}
+
+	var angkatan struct {
+		Data models.AlumniAngkatan `json:"data"`
+	}
+	if code := call(t, app, "GET", "/alumni/angkatan/2019", "", &angkatan); code != 200 || angkatan.Data.Jumlah != 2 {
+		t.Fatalf("angkatan 2019: status %d, %+v", code, angkatan.Data)
+	}
+}
+
+func TestAlumniAndPekerjaanJoin(t *testing.T) {
+	repo := repository.NewMockAlumniRepository()
+	repo.Pekerjaan = repository.NewMockPekerjaanRepository()
+	seedAlumni(repo, models.Alumni{NIM: "001", Nama: "Andi"}, models.Alumni{NIM: "002", Nama: "Citra"})
+	repo.Pekerjaan.CreatePekerjaan(context.Background(), &models.PekerjaanAlumni{AlumniID: 1, NamaPerusahaan: "PT Maju", PosisiJabatan: "Engineer"})
+	app := newAlumniApp(&AlumniService{Repo: repo})
+
+	var got struct {
+		Data models.AlumniPekerjaan `json:"data"`
+	}
+	if code := call(t, app, "GET", "/alumni/detail/1", "", &got); code != 200 || got.Data.NamaPerusahaan != "PT Maju" {
+		t.Fatalf("alumni dengan pekerjaan: status %d, %+v", code, got.Data)
+	}
+	// alumni tanpa pekerjaan tidak ikut JOIN
+	if code := call(t, app, "GET", "/alumni/detail/2", "", nil); code == 200 {
+		t.Fatal("alumni tanpa pekerjaan tidak boleh 200")
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+const (
+	defaultAPIKeyDays = 90
+	maxAPIKeyDays     = 730
+)
+
+type APIKeyService struct {
+	Repo  repository.APIKeyRepositoryInterface
+	Audit *middleware.Auditor
+}
+
+// ListAPIKeys godoc
+// @Summary Daftar API key
+// @Description Key utuh tidak pernah ditampilkan lagi, hanya prefix
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Router /admin/api-keys [get]
+func (s *APIKeyService) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := s.Repo.List(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Buat API key
+// @Description Scopes = permission (lihat /admin/permissions), tidak boleh melebihi permission pembuat.
+// @Description Scope * dan pekerjaan:write_own (butuh akun pemilik) ditolak.
+// @Description Key dikirim lewat header X-API-Key dan hanya ditampilkan sekali.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} models.CreateAPIKeyResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/api-keys [post]
+func (s *APIKeyService) CreateAPIKey(c *fiber.Ctx) error {
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "name wajib (maks 100 karakter)"})
+	}
+	if len(req.Scopes) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "scopes wajib"})
+	}
+	scopes, err := normalizePermissions(req.Scopes)
+	if err != nil {
+		return roleError(c, err)
+	}
+	for _, p := range scopes {
+		if p == models.PermAll {
+			return c.Status(400).JSON(fiber.Map{"error": "API key tidak boleh memakai scope *"})
+		}
+		// "milik sendiri" dicek lewat users.alumni_id, API key tidak punya akun pemilik
+		if p == models.PermPekerjaanWriteOwn {
+			return c.Status(400).JSON(fiber.Map{"error": "API key tidak bisa memakai scope " + p + ", pakai " + models.PermPekerjaanWrite})
+		}
+		// pembuat tidak bisa memberi akses yang tidak ia punya
+		if !middleware.HasPermission(c, p) {
+			return c.Status(403).JSON(fiber.Map{"error": "tidak bisa memberi scope yang tidak kamu punya: " + p})
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyDays
+	}
+	if days < 0 || days > maxAPIKeyDays {
+		return c.Status(400).JSON(fiber.Map{"error": "expires_in_days harus 1-" + strconv.Itoa(maxAPIKeyDays)})
+	}
+
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat API key"})
+	}
+	k := models.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(key),
+		Scopes:    scopes,
+		CreatedBy: middleware.Claims(c).Subject,
+		ExpiresAt: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+	}
+	if err := s.Repo.Create(c.UserContext(), &k); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityAPIKey, strconv.Itoa(k.ID), nil, k)
+	return c.Status(201).JSON(models.CreateAPIKeyResponse{APIKey: k, Key: key})
+}
+
+// RevokeAPIKey godoc
+// @Summary Cabut API key
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID API key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (s *APIKeyService) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	n, err := s.Repo.Revoke(c.UserContext(), id)
+	if err != nil && err != sql.ErrNoRows {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if n == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "API key tidak ditemukan atau sudah dicabut"})
+	}
+	// key tidak dihapus, hanya revoked_at yang diisi
+	s.Audit.Log(c, models.AuditDelete, models.AuditEntityAPIKey, strconv.Itoa(id), nil, nil)
+	return c.JSON(fiber.Map{"message": "API key dicabut"})
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+// write_own butuh akun pemilik (users.alumni_id), API key tidak punya
+func TestAPIKeyRejectsWriteOwnScope(t *testing.T) {
+	svc := &APIKeyService{}
+	app := fiber.New()
+	app.Post("/api-keys", authAs(1, models.PermPekerjaanWrite, models.PermPekerjaanWriteOwn), svc.CreateAPIKey)
+
+	body := `{"name":"sync","scopes":["` + models.PermPekerjaanWriteOwn + `"]}`
+	if code := call(t, app, "POST", "/api-keys", body, nil); code != 400 {
+		t.Fatalf("scope write_own: status %d, want 400", code)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+)
+
+type AuditService struct {
+	Repo repository.AuditRepositoryInterface
+}
+
+// parseAuditTime menerima RFC3339 atau tanggal saja (YYYY-MM-DD, UTC)
+func parseAuditTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// ListAuditLogs godoc
+// @Summary Lihat audit log perubahan data
+// @Description Terbaru dulu. Filter actor (subject "postgres:1" atau username), entitas, aksi dan rentang waktu [from, to).
+// @Description Pencatatan best-effort: entri ditulis setelah perubahan berhasil (onboarding: dalam transaksi yang sama),
+// @Description jadi perubahan yang audit-nya gagal ditulis tidak muncul di sini (lihat log server).
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param actor query string false "Subject atau username pelaku"
+// @Param entity_type query string false "alumni, pekerjaan, alumni_mongo, pekerjaan_mongo, file, user, role, api_key, outbox_event, consistency"
+// @Param entity_id query string false "ID entitas"
+// @Param action query string false "create, update, delete, soft_delete, restore"
+// @Param from query string false "Mulai (RFC3339 atau YYYY-MM-DD)"
+// @Param to query string false "Sampai sebelum (RFC3339 atau YYYY-MM-DD)"
+// @Param page query int false "Halaman"
+// @Param limit query int false "Limit data (maks 100)"
+// @Success 200 {object} models.UserResponse[models.AuditEntry]
+// @Failure 400 {object} models.ErrorResponse
+// @Router /admin/audit-logs [get]
+func (s *AuditService) ListAuditLogs(c *fiber.Ctx) error {
+	params := getListParams(c, nil)
+	from, err := parseAuditTime(c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "format from tidak valid"})
+	}
+	to, err := parseAuditTime(c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "format to tidak valid"})
+	}
+
+	entries, total, err := s.Repo.List(c.UserContext(), models.AuditFilter{
+		Actor:      c.Query("actor"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Action:     c.Query("action"),
+		From:       from,
+		To:         to,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(models.UserResponse[models.AuditEntry]{
+		Data: entries,
+		Meta: models.MetaInfo{
+			Page:  params.Page,
+			Limit: params.Limit,
+			Total: total,
+			Pages: (total + params.Limit - 1) / params.Limit,
+			Order: "desc",
+		},
+	})
+}
@@ -1,28 +1,54 @@
 package service
 
 import (
+	"context"
+    "strconv"
     "strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go_clean/app/models/postgresql"
 	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
 	"go_clean/utils"
 )
 
+// MFAGate dipenuhi oleh MFAService (app/service/identity). Challenge nil = tidak perlu 2FA.
+type MFAGate interface {
+    Begin(ctx context.Context, id models.Identity) (*models.MFAChallenge, error)
+}
+
 type AuthService struct {
-    Repo *repository.AuthRepository
+    Repo         repository.AuthRepositoryInterface
+    Tokens       *TokenService
+    Verification *VerificationService
+    MFA          MFAGate
+    Guard        *middleware.LoginGuard
+    Audit        *middleware.Auditor
+}
+
+// issueLoginTokens memberi pasangan access + refresh token; access token selalu terikat sesi
+// supaya bisa dicabut lewat logout
+func (s *AuthService) issueLoginTokens(c *fiber.Ctx, u models.User) (string, string, error) {
+	pair, err := s.Tokens.IssueTokenPair(c.UserContext(), models.PostgresIdentity(u), middleware.SessionMeta(c))
+	if err != nil {
+		return "", "", err
+	}
+	return pair.Token, pair.RefreshToken, nil
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Mengembalikan JWT token
+// @Description Mengembalikan JWT token. Akun dengan 2FA (atau admin saat 2FA wajib) mendapat 202 + mfa_token,
+// @Description lanjutkan ke /login/mfa.
 // @Tags Auth
 // @Version 1.0
 // @Accept json
 // @Produce json
 // @Param request body models.LoginRequest true "Login Data"
 // @Success 200 {object} models.LoginResponse
+// @Success 202 {object} models.MFAChallenge
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Router /login [post]
 func (s *AuthService) LoginUser(c *fiber.Ctx) error {
 	var req models.LoginRequest
@@ -39,9 +65,15 @@ func (s *AuthService) LoginUser(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "username & password wajib"})
 	}
 
+	// brute-force: identifier yang sedang dijeda / dikunci langsung ditolak
+	if b := s.Guard.CheckLogin(req.Username, c.IP()); b != nil {
+		return b.Respond(c)
+	}
+
 	// ambil data user dari repository
-	u, hash, err := s.Repo.GetByUsernameOrEmail(req.Username)
+	u, hash, err := s.Repo.GetByUsernameOrEmail(c.UserContext(), req.Username)
 	if err != nil {	
+		s.Guard.FailLogin(req.Username, c.IP())
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "username/password salah",
 		})
@@ -49,19 +81,41 @@ func (s *AuthService) LoginUser(c *fiber.Ctx) error {
 
 	// cek password hash
 	if !utils.CheckPassword(req.Password, hash) {
+		s.Guard.FailLogin(req.Username, c.IP())
 		return c.Status(401).JSON(fiber.Map{"error": "username/password salah"})
 	}
+	s.Guard.SucceedLogin(req.Username, c.IP())
+	utils.RehashIfNeeded(req.Password, hash, func(newHash string) error {
+		return s.Repo.UpdatePassword(c.UserContext(), strconv.Itoa(u.ID), newHash)
+	})
+
+	// status nonaktif baru dibuka setelah password benar
+	if u.Disabled {
+		return c.Status(403).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+	}
+
+	// 2FA: token baru diberikan setelah kode diverifikasi di /api/login/mfa
+	if s.MFA != nil {
+		challenge, err := s.MFA.Begin(c.UserContext(), models.PostgresIdentity(*u))
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal cek 2FA"})
+		}
+		if challenge != nil {
+			return c.Status(fiber.StatusAccepted).JSON(challenge)
+		}
+	}
 
-	// generate token JWT
-	token, err := utils.GenerateToken(*u)
+	// generate token JWT + refresh token
+	token, refresh, err := s.issueLoginTokens(c, *u)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
 	}
 
 	// return response model
 	return c.JSON(models.LoginResponse{
-		User:  *u,
-		Token: token,
+		User:         *u,
+		Token:        token,
+		RefreshToken: refresh,
 	})
 }
 
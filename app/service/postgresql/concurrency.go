@@ -0,0 +1,30 @@
+package service
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+)
+
+// ifMatchFailed = respons untuk error middleware.IfMatch (428 header wajib, 400 format salah)
+func ifMatchFailed(c *fiber.Ctx, err error) error {
+	return c.Status(middleware.IfMatchStatus(err)).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// versionConflict = 412 berisi data terbaru beserta ETag-nya, supaya client bisa menggabungkan perubahannya lalu kirim ulang
+func versionConflict(c *fiber.Ctx, version int, current interface{}) error {
+	middleware.SetETag(c, version)
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"success": false,
+		"message": "Data sudah diubah pengguna lain, muat ulang lalu coba lagi",
+		"data":    current,
+	})
+}
+
+// staleVersion: If-Match berisi versi yang sudah bukan versi terbaru (models.AnyVersion tidak pernah basi)
+func staleVersion(want, current int) bool {
+	return want != models.AnyVersion && want != current
+}
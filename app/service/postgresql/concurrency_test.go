@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+)
+
+func TestAlumniIfMatch(t *testing.T) {
+	repo := repository.NewMockAlumniRepository()
+	seedAlumni(repo, models.Alumni{NIM: "123", Nama: "Budi", Jurusan: "TI", Angkatan: 2019, TahunLulus: 2023, Email: "budi@example.com"})
+	app := newAlumniApp(&AlumniService{Repo: repo, Concurrency: config.ConcurrencyConfig{RequireIfMatch: true}})
+
+	code, etag := callIfMatch(t, app, "GET", "/alumni/1", "", "", nil)
+	if code != 200 || etag != `"1"` {
+		t.Fatalf("get: status %d, etag %q", code, etag)
+	}
+
+	update := `{"nama":"Budi Santoso","jurusan":"TI","angkatan":2019,"tahun_lulus":2023,"email":"budi@example.com"}`
+	if code, _ := callIfMatch(t, app, "PUT", "/alumni/1", update, "", nil); code != 428 {
+		t.Fatalf("tanpa If-Match: status %d, want 428", code)
+	}
+	if code, _ := callIfMatch(t, app, "PUT", "/alumni/1", update, "1", nil); code != 400 {
+		t.Fatalf("If-Match tanpa kutip: status %d, want 400", code)
+	}
+
+	var got struct {
+		Data models.Alumni `json:"data"`
+	}
+	code, etag = callIfMatch(t, app, "PUT", "/alumni/1", update, `"1"`, &got)
+	if code != 200 || etag != `"2"` || got.Data.Version != 2 {
+		t.Fatalf("update: status %d, etag %q, data %+v", code, etag, got.Data)
+	}
+
+	// pengguna kedua masih memegang ETag lama
+	stale := `{"nama":"Budi S.","jurusan":"TI","angkatan":2019,"tahun_lulus":2023,"email":"budi@example.com"}`
+	code, etag = callIfMatch(t, app, "PUT", "/alumni/1", stale, `"1"`, &got)
+	if code != 412 || etag != `"2"` || got.Data.Nama != "Budi Santoso" {
+		t.Fatalf("update basi: status %d, etag %q, data %+v", code, etag, got.Data)
+	}
+	if code, _ := callIfMatch(t, app, "DELETE", "/alumni/1", "", `"1"`, nil); code != 412 {
+		t.Fatalf("delete basi: status %d, want 412", code)
+	}
+	if code, _ := callIfMatch(t, app, "DELETE", "/alumni/1", "", `W/"2"`, nil); code != 200 {
+		t.Fatalf("delete: status %d, want 200", code)
+	}
+	if code, _ := callIfMatch(t, app, "DELETE", "/alumni/1", "", `"2"`, nil); code != 404 {
+		t.Fatalf("delete kedua: status %d, want 404", code)
+	}
+}
+
+func TestPekerjaanIfMatch(t *testing.T) {
+	jobs, users := repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.InsertUser(&models.User{ID: 1, Username: "admin", Role: models.RoleAdmin})
+	seedPekerjaan(jobs, models.PekerjaanAlumni{AlumniID: 1, NamaPerusahaan: "PT Maju", PosisiJabatan: "Engineer"})
+	app := newPekerjaanApp(&PekerjaanService{Repo: jobs, Users: users},
+		authAs(1, models.PermPekerjaanWrite, models.PermPekerjaanReadAll, models.PermPekerjaanHardDelete))
+
+	body := `{"nama_perusahaan":"PT Maju Jaya","posisi_jabatan":"Lead"}`
+	if code, etag := callIfMatch(t, app, "PUT", "/pekerjaan/1", body, `"1"`, nil); code != 200 || etag != `"2"` {
+		t.Fatalf("update: status %d, etag %q", code, etag)
+	}
+	if code, etag := callIfMatch(t, app, "DELETE", "/pekerjaan/1", "", `"1"`, nil); code != 412 || etag != `"2"` {
+		t.Fatalf("soft delete basi: status %d, etag %q", code, etag)
+	}
+	// If-Match tidak diwajibkan (Concurrency kosong): tanpa header = tanpa syarat
+	if code, _ := callIfMatch(t, app, "DELETE", "/pekerjaan/1", "", "", nil); code != 200 {
+		t.Fatalf("soft delete: status %d, want 200", code)
+	}
+	if code, _ := callIfMatch(t, app, "PUT", "/pekerjaan/restore/1", "", `"2"`, nil); code != 412 {
+		t.Fatalf("restore basi: status %d, want 412", code)
+	}
+	if code, _ := callIfMatch(t, app, "PUT", "/pekerjaan/restore/1", "", `"3"`, nil); code != 200 || jobs.Data[1].IsDeleted {
+		t.Fatalf("restore: status %d", code)
+	}
+	if code, _ := callIfMatch(t, app, "DELETE", "/pekerjaan/hard-delete/1", "", `"3"`, nil); code != 412 {
+		t.Fatalf("hard delete basi: status %d, want 412", code)
+	}
+	if code, _ := callIfMatch(t, app, "DELETE", "/pekerjaan/hard-delete/1", "", `"4"`, nil); code != 200 {
+		t.Fatalf("hard delete: status %d, want 200", code)
+	}
+}
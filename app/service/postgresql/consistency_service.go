@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/middleware"
+)
+
+// ConsistencyStore = satu sisi pengecekan konsistensi (Postgres atau MongoDB): bisa dibaca seluruhnya
+// dan ditulis dengan id yang sama dengan sisi lain
+type ConsistencyStore interface {
+	ReplicaWriter
+	SnapshotAlumni(ctx context.Context) ([]models.Alumni, error)
+	SnapshotPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error)
+}
+
+// ConsistencyChecker membandingkan alumni (kunci alumni_id, atau NIM untuk dokumen Mongo lama tanpa alumni_id)
+// dan pekerjaan (kunci pekerjaan_id) antara dua penyimpan, lalu bisa memperbaiki tujuan dari sumber.
+// created_at / updated_at / deleted_at tidak dibandingkan karena presisi waktunya berbeda di tiap database.
+type ConsistencyChecker struct {
+	Stores map[string]ConsistencyStore
+	Now    func() time.Time
+}
+
+const (
+	repairUpsert = "upsert"
+	repairDelete = "delete"
+)
+
+func optText(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func dateText(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+type fieldValue struct{ name, value string }
+
+func alumniFields(a models.Alumni) []fieldValue {
+	return []fieldValue{
+		{"nim", a.NIM}, {"nama", a.Nama}, {"jurusan", a.Jurusan},
+		{"angkatan", strconv.Itoa(a.Angkatan)}, {"tahun_lulus", strconv.Itoa(a.TahunLulus)}, {"email", a.Email},
+		{"no_telepon", optText(a.NoTelepon)}, {"alamat", optText(a.Alamat)},
+	}
+}
+
+func pekerjaanFields(p models.PekerjaanAlumni) []fieldValue {
+	return []fieldValue{
+		{"alumni_id", strconv.Itoa(p.AlumniID)}, {"nama_perusahaan", p.NamaPerusahaan}, {"posisi_jabatan", p.PosisiJabatan},
+		{"bidang_industri", p.BidangIndustri}, {"lokasi_kerja", p.LokasiKerja}, {"gaji_range", optText(p.GajiRange)},
+		{"tanggal_mulai_kerja", dateText(&p.TanggalMulaiKerja)}, {"tanggal_selesai_kerja", dateText(p.TanggalSelesaiKerja)},
+		{"status_pekerjaan", p.StatusPekerjaan}, {"deskripsi_pekerjaan", optText(p.DeskripsiPekerjaan)},
+		{"is_delete", strconv.FormatBool(p.IsDeleted)}, {"deleted_by", p.DeletedBy},
+	}
+}
+
+func diffFields(source, target []fieldValue) []models.FieldDiff {
+	var diffs []models.FieldDiff
+	for i := range source {
+		if source[i].value != target[i].value {
+			diffs = append(diffs, models.FieldDiff{Field: source[i].name, Source: source[i].value, Target: target[i].value})
+		}
+	}
+	return diffs
+}
+
+// plan = satu perbaikan yang menunggu dijalankan, diff menunjuk ke baris laporan
+type plan struct {
+	diff  int
+	apply func(ctx context.Context) error
+}
+
+// Check membandingkan source dengan penyimpan lainnya. repair=true menyusun perbaikan tujuan;
+// perbaikan hanya dijalankan kalau dryRun=false. Urutan perbaikan: hapus pekerjaan & alumni yang
+// berlebih dulu (membebaskan NIM), lalu upsert alumni, baru upsert pekerjaan (butuh alumninya). Alumni sumber
+// tanpa id yang baru dibuat di tujuan mendapat alumni_id barunya kembali (lihat backfillLegacy).
+func (s *ConsistencyChecker) Check(ctx context.Context, source string, repair, dryRun bool) (*models.ConsistencyReport, error) {
+	src, ok := s.Stores[source]
+	if !ok {
+		return nil, fmt.Errorf("sumber harus postgres atau mongo, bukan %q", source)
+	}
+	target := models.BackendMongo
+	if source == models.BackendMongo {
+		target = models.BackendPostgres
+	}
+	dst, ok := s.Stores[target]
+	if !ok {
+		return nil, fmt.Errorf("penyimpan %s tidak tersedia", target)
+	}
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	report := &models.ConsistencyReport{
+		Source: source, Target: target, Repair: repair, DryRun: repair && dryRun,
+		CheckedAt: now, Diffs: []models.ConsistencyDiff{},
+	}
+
+	srcAlumni, err := src.SnapshotAlumni(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baca alumni %s: %w", source, err)
+	}
+	dstAlumni, err := dst.SnapshotAlumni(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baca alumni %s: %w", target, err)
+	}
+	srcJobs, err := src.SnapshotPekerjaan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baca pekerjaan %s: %w", source, err)
+	}
+	dstJobs, err := dst.SnapshotPekerjaan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baca pekerjaan %s: %w", target, err)
+	}
+	report.Counts.SourceAlumni, report.Counts.TargetAlumni = len(srcAlumni), len(dstAlumni)
+	report.Counts.SourcePekerjaan, report.Counts.TargetPekerjaan = len(srcJobs), len(dstJobs)
+
+	var deletes, alumniUpserts, jobUpserts []plan
+	add := func(d models.ConsistencyDiff) int {
+		switch d.Kind {
+		case models.ConsistencyMissing:
+			report.Counts.Missing++
+		case models.ConsistencyExtra:
+			report.Counts.Extra++
+		default:
+			report.Counts.Mismatched++
+		}
+		report.Diffs = append(report.Diffs, d)
+		return len(report.Diffs) - 1
+	}
+
+	// alumni: cocokkan lewat alumni_id, sisi yang id-nya 0 dicocokkan lewat NIM
+	byID := map[int]int{}
+	byNIM := map[string]int{}
+	for i, a := range dstAlumni {
+		if a.ID > 0 {
+			byID[a.ID] = i
+		}
+		byNIM[a.NIM] = i
+	}
+	matched := make([]bool, len(dstAlumni))
+	// legacy = diff alumni sumber tanpa id yang belum ada di tujuan; id barunya ditulis balik ke sumber
+	legacy := map[string]int{}
+	for _, a := range srcAlumni {
+		a := a
+		i, found := byID[a.ID]
+		if !found || a.ID <= 0 {
+			i, found = byNIM[a.NIM]
+			found = found && !matched[i] && (a.ID <= 0 || dstAlumni[i].ID <= 0)
+		}
+		d := models.ConsistencyDiff{Entity: models.OutboxAggregateAlumni, ID: a.ID, NIM: a.NIM, Action: repairUpsert}
+		if !found {
+			d.Kind = models.ConsistencyMissing
+		} else {
+			matched[i] = true
+			t := dstAlumni[i]
+			d.Fields = diffFields(alumniFields(a), alumniFields(t))
+			if a.ID != t.ID {
+				d.Fields = append([]models.FieldDiff{{Field: "alumni_id", Source: strconv.Itoa(a.ID), Target: strconv.Itoa(t.ID)}}, d.Fields...)
+				// baris lama tanpa id memakai id yang sudah ada di sisi lain
+				if a.ID <= 0 {
+					a.ID, d.ID = t.ID, t.ID
+				}
+			}
+			if len(d.Fields) == 0 {
+				continue
+			}
+			d.Kind = models.ConsistencyMismatch
+		}
+		idx := add(d)
+		if !found && a.ID <= 0 {
+			legacy[a.NIM] = idx
+		}
+		alumniUpserts = append(alumniUpserts, plan{idx, func(ctx context.Context) error { return dst.UpsertAlumni(ctx, a) }})
+	}
+	for i, t := range dstAlumni {
+		if matched[i] {
+			continue
+		}
+		d := models.ConsistencyDiff{Entity: models.OutboxAggregateAlumni, ID: t.ID, NIM: t.NIM, Kind: models.ConsistencyExtra, Action: repairDelete}
+		idx := add(d)
+		// dokumen tanpa id tidak bisa dihapus lewat id; cukup dilaporkan
+		if t.ID <= 0 {
+			report.Diffs[idx].Action = ""
+			continue
+		}
+		id := t.ID
+		deletes = append(deletes, plan{idx, func(ctx context.Context) error { return dst.DeleteAlumni(ctx, id) }})
+	}
+
+	// pekerjaan: cocokkan lewat pekerjaan_id
+	jobByID := map[int]models.PekerjaanAlumni{}
+	for _, p := range dstJobs {
+		jobByID[p.ID] = p
+	}
+	var jobDeletes []plan
+	for _, p := range srcJobs {
+		p := p
+		d := models.ConsistencyDiff{Entity: models.OutboxAggregatePekerjaan, ID: p.ID, Action: repairUpsert}
+		t, found := jobByID[p.ID]
+		if !found {
+			d.Kind = models.ConsistencyMissing
+		} else {
+			delete(jobByID, p.ID)
+			if d.Fields = diffFields(pekerjaanFields(p), pekerjaanFields(t)); len(d.Fields) == 0 {
+				continue
+			}
+			d.Kind = models.ConsistencyMismatch
+		}
+		jobUpserts = append(jobUpserts, plan{add(d), func(ctx context.Context) error { return dst.UpsertPekerjaan(ctx, p) }})
+	}
+	for _, t := range dstJobs {
+		if _, extra := jobByID[t.ID]; !extra {
+			continue
+		}
+		id := t.ID
+		idx := add(models.ConsistencyDiff{Entity: models.OutboxAggregatePekerjaan, ID: id, Kind: models.ConsistencyExtra, Action: repairDelete})
+		jobDeletes = append(jobDeletes, plan{idx, func(ctx context.Context) error { return dst.DeletePekerjaan(ctx, id) }})
+	}
+
+	if !repair {
+		for i := range report.Diffs {
+			report.Diffs[i].Action = ""
+		}
+		return report, nil
+	}
+	if dryRun {
+		return report, nil
+	}
+	for _, group := range [][]plan{jobDeletes, deletes, alumniUpserts, jobUpserts} {
+		for _, p := range group {
+			if err := p.apply(ctx); err != nil {
+				report.Diffs[p.diff].Error = err.Error()
+				report.Counts.Failed++
+				continue
+			}
+			report.Diffs[p.diff].Repaired = true
+			report.Counts.Repaired++
+		}
+	}
+	backfillLegacy(ctx, src, dst, legacy, report)
+	return report, nil
+}
+
+// backfillLegacy menulis alumni_id yang baru dibuat di tujuan ke baris sumber tanpa id (dicocokkan lewat NIM).
+// Tanpa ini dokumen Mongo lama tetap tanpa alumni_id dan event outbox untuk id baru membuat dokumen kedua
+// dengan NIM yang sama. Gagal di sini membuat diff-nya ditandai belum diperbaiki.
+func backfillLegacy(ctx context.Context, src, dst ConsistencyStore, legacy map[string]int, report *models.ConsistencyReport) {
+	pending := map[string]int{}
+	for nim, idx := range legacy {
+		if report.Diffs[idx].Repaired {
+			pending[nim] = idx
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	fail := func(idx int, err error) {
+		report.Diffs[idx].Repaired = false
+		report.Diffs[idx].Error = "tulis balik alumni_id: " + err.Error()
+		report.Counts.Repaired--
+		report.Counts.Failed++
+	}
+	rows, err := dst.SnapshotAlumni(ctx)
+	if err != nil {
+		for _, idx := range pending {
+			fail(idx, err)
+		}
+		return
+	}
+	for _, a := range rows {
+		idx, ok := pending[a.NIM]
+		if !ok || a.ID <= 0 {
+			continue
+		}
+		delete(pending, a.NIM)
+		report.Diffs[idx].ID = a.ID
+		if err := src.UpsertAlumni(ctx, a); err != nil {
+			fail(idx, err)
+		}
+	}
+	for _, idx := range pending {
+		fail(idx, fmt.Errorf("alumni NIM %s tidak ditemukan di %s", report.Diffs[idx].NIM, report.Target))
+	}
+}
+
+var consistencyCSVHeader = []string{"entity", "id", "nim", "kind", "field", "source", "target", "action", "repaired", "error"}
+
+// WriteConsistencyCSV menulis satu baris per field yang berbeda; baris missing / extra tanpa kolom field
+func WriteConsistencyCSV(w io.Writer, report *models.ConsistencyReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write(consistencyCSVHeader)
+	for _, d := range report.Diffs {
+		row := func(f models.FieldDiff) {
+			cw.Write([]string{d.Entity, strconv.Itoa(d.ID), d.NIM, d.Kind, f.Field, f.Source, f.Target,
+				d.Action, strconv.FormatBool(d.Repaired), d.Error})
+		}
+		if len(d.Fields) == 0 {
+			row(models.FieldDiff{})
+		}
+		for _, f := range d.Fields {
+			row(f)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ConsistencyService = endpoint admin pengecekan konsistensi Postgres ↔ MongoDB
+type ConsistencyService struct {
+	Checker *ConsistencyChecker
+	Audit   *middleware.Auditor
+}
+
+func (s *ConsistencyService) respond(c *fiber.Ctx, report *models.ConsistencyReport) error {
+	switch c.Query("format", "json") {
+	case "csv":
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="consistency.csv"`)
+		return WriteConsistencyCSV(c, report)
+	case "json":
+		return c.JSON(report)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "format harus json atau csv"})
+	}
+}
+
+// CheckConsistency godoc
+// @Summary Bandingkan data alumni & pekerjaan Postgres dan MongoDB
+// @Description Alumni dicocokkan lewat alumni_id (atau NIM untuk dokumen Mongo tanpa alumni_id), pekerjaan lewat pekerjaan_id.
+// @Description missing = ada di sumber saja, extra = ada di tujuan saja, mismatch = field berbeda. Tidak mengubah data.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param source query string false "Penyimpan acuan: postgres (default) atau mongo"
+// @Param format query string false "json (default) atau csv"
+// @Success 200 {object} models.ConsistencyReport
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/consistency [get]
+func (s *ConsistencyService) CheckConsistency(c *fiber.Ctx) error {
+	source := c.Query("source", models.BackendPostgres)
+	if _, ok := s.Checker.Stores[source]; !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "source harus postgres atau mongo"})
+	}
+	report, err := s.Checker.Check(c.UserContext(), source, false, false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return s.respond(c, report)
+}
+
+// RepairConsistency godoc
+// @Summary Perbaiki penyimpan tujuan dari penyimpan sumber
+// @Description Baris missing & mismatch di-upsert ke tujuan, baris extra dihapus dari tujuan.
+// @Description Default dry_run=true: hanya menampilkan rencana perbaikan; kirim dry_run=false untuk menjalankannya.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param source query string false "Penyimpan acuan: postgres (default) atau mongo"
+// @Param dry_run query bool false "true (default) = tidak mengubah data"
+// @Param format query string false "json (default) atau csv"
+// @Success 200 {object} models.ConsistencyReport
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/consistency/repair [post]
+func (s *ConsistencyService) RepairConsistency(c *fiber.Ctx) error {
+	source := c.Query("source", models.BackendPostgres)
+	if _, ok := s.Checker.Stores[source]; !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "source harus postgres atau mongo"})
+	}
+	dryRun := c.QueryBool("dry_run", true)
+	report, err := s.Checker.Check(c.UserContext(), source, true, dryRun)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !dryRun && (report.Counts.Repaired > 0 || report.Counts.Failed > 0) {
+		s.Audit.Log(c, models.AuditUpdate, models.AuditEntityConsistency, report.Target, nil, report.Counts)
+	}
+	return s.respond(c, report)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+// memStore = ConsistencyStore di memori. Upsert alumni dengan id 0 diberi id baru, sama seperti sequence Postgres.
+type memStore struct {
+	alumni map[int]models.Alumni
+	jobs   map[int]models.PekerjaanAlumni
+	nextID int
+}
+
+func newMemStore(alumni []models.Alumni, jobs []models.PekerjaanAlumni) *memStore {
+	s := &memStore{alumni: map[int]models.Alumni{}, jobs: map[int]models.PekerjaanAlumni{}, nextID: 1000}
+	for _, a := range alumni {
+		s.alumni[a.ID] = a
+	}
+	for _, p := range jobs {
+		s.jobs[p.ID] = p
+	}
+	return s
+}
+
+func (s *memStore) SnapshotAlumni(ctx context.Context) ([]models.Alumni, error) {
+	list := []models.Alumni{}
+	for _, a := range s.alumni {
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+func (s *memStore) SnapshotPekerjaan(ctx context.Context) ([]models.PekerjaanAlumni, error) {
+	list := []models.PekerjaanAlumni{}
+	for _, p := range s.jobs {
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+func (s *memStore) UpsertAlumni(ctx context.Context, a models.Alumni) error {
+	if a.ID <= 0 {
+		s.nextID++
+		a.ID = s.nextID
+	}
+	// dokumen lama dengan NIM yang sama dipakai ulang, seperti ReplicaMongoRepository
+	if old, ok := s.alumni[0]; ok && old.NIM == a.NIM {
+		delete(s.alumni, 0)
+	}
+	s.alumni[a.ID] = a
+	return nil
+}
+
+func (s *memStore) DeleteAlumni(ctx context.Context, id int) error {
+	delete(s.alumni, id)
+	for pid, p := range s.jobs {
+		if p.AlumniID == id {
+			delete(s.jobs, pid)
+		}
+	}
+	return nil
+}
+
+func (s *memStore) UpsertPekerjaan(ctx context.Context, p models.PekerjaanAlumni) error {
+	s.jobs[p.ID] = p
+	return nil
+}
+
+func (s *memStore) DeletePekerjaan(ctx context.Context, id int) error {
+	delete(s.jobs, id)
+	return nil
+}
+
+// newDriftedStores: Postgres acuan, Mongo punya alumni lama tanpa alumni_id (NIM 103), alumni berlebih (id 9),
+// satu field berbeda (alumni 2) dan pekerjaan yang hilang / berlebih / berbeda status trash
+func newDriftedStores() (*memStore, *memStore) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	pg := newMemStore([]models.Alumni{
+		{ID: 1, NIM: "101", Nama: "Budi", Email: "budi@mail.com"},
+		{ID: 2, NIM: "102", Nama: "Sari", Email: "sari@mail.com", Alamat: strPtr("Solo")},
+		{ID: 3, NIM: "103", Nama: "Andi", Email: "andi@mail.com"},
+		{ID: 4, NIM: "104", Nama: "Rina", Email: "rina@mail.com"},
+	}, []models.PekerjaanAlumni{
+		{ID: 10, AlumniID: 1, NamaPerusahaan: "PT Maju", TanggalMulaiKerja: start},
+		{ID: 11, AlumniID: 2, NamaPerusahaan: "CV Sentosa", TanggalMulaiKerja: start, IsDeleted: true},
+	})
+	mongo := newMemStore([]models.Alumni{
+		{ID: 1, NIM: "101", Nama: "Budi", Email: "budi@mail.com"},
+		{ID: 2, NIM: "102", Nama: "Sari", Email: "sari@mail.com", Alamat: strPtr("Solo Baru")},
+		{ID: 0, NIM: "103", Nama: "Andi", Email: "andi@mail.com"},
+		{ID: 9, NIM: "109", Nama: "Hantu", Email: "hantu@mail.com"},
+	}, []models.PekerjaanAlumni{
+		// zona waktu berbeda tetap dianggap tanggal yang sama
+		{ID: 10, AlumniID: 1, NamaPerusahaan: "PT Maju", TanggalMulaiKerja: start.In(time.FixedZone("WIB", 7*3600))},
+		{ID: 11, AlumniID: 2, NamaPerusahaan: "CV Sentosa", TanggalMulaiKerja: start},
+		{ID: 12, AlumniID: 9, NamaPerusahaan: "PT Hilang"},
+	})
+	return pg, mongo
+}
+
+func newChecker(pg, mongo *memStore) *ConsistencyChecker {
+	return &ConsistencyChecker{Stores: map[string]ConsistencyStore{
+		models.BackendPostgres: pg,
+		models.BackendMongo:    mongo,
+	}}
+}
+
+func findDiff(r *models.ConsistencyReport, entity string, id int) *models.ConsistencyDiff {
+	for i := range r.Diffs {
+		if r.Diffs[i].Entity == entity && r.Diffs[i].ID == id {
+			return &r.Diffs[i]
+		}
+	}
+	return nil
+}
+
+func TestConsistencyCheckReportsDiffs(t *testing.T) {
+	pg, mongo := newDriftedStores()
+	report, err := newChecker(pg, mongo).Check(context.Background(), models.BackendPostgres, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Target != models.BackendMongo || report.Counts.Missing != 1 || report.Counts.Extra != 2 || report.Counts.Mismatched != 3 {
+		t.Fatalf("counts = %+v, diffs %+v", report.Counts, report.Diffs)
+	}
+	if d := findDiff(report, "alumni", 4); d == nil || d.Kind != models.ConsistencyMissing || d.Action != "" {
+		t.Fatalf("alumni 4 = %+v, want missing tanpa action", d)
+	}
+	if d := findDiff(report, "alumni", 2); d == nil || len(d.Fields) != 1 || d.Fields[0] != (models.FieldDiff{Field: "alamat", Source: "Solo", Target: "Solo Baru"}) {
+		t.Fatalf("alumni 2 = %+v", d)
+	}
+	// dokumen lama dicocokkan lewat NIM, bukan dilaporkan missing + extra
+	if d := findDiff(report, "alumni", 3); d == nil || d.Kind != models.ConsistencyMismatch || d.Fields[0].Field != "alumni_id" {
+		t.Fatalf("alumni 3 = %+v", d)
+	}
+	if d := findDiff(report, "alumni", 9); d == nil || d.Kind != models.ConsistencyExtra {
+		t.Fatalf("alumni 9 = %+v", d)
+	}
+	if d := findDiff(report, "pekerjaan", 11); d == nil || len(d.Fields) != 1 || d.Fields[0].Field != "is_delete" {
+		t.Fatalf("pekerjaan 11 = %+v", d)
+	}
+	if d := findDiff(report, "pekerjaan", 10); d != nil {
+		t.Fatalf("pekerjaan 10 sama, tapi dilaporkan %+v", d)
+	}
+
+	if _, err := newChecker(pg, mongo).Check(context.Background(), "mysql", false, false); err == nil {
+		t.Fatal("source tidak dikenal harus error")
+	}
+}
+
+func TestConsistencyRepair(t *testing.T) {
+	pg, mongo := newDriftedStores()
+	checker := newChecker(pg, mongo)
+
+	plan, err := checker.Check(context.Background(), models.BackendPostgres, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.DryRun || plan.Counts.Repaired != 0 || len(mongo.alumni) != 4 {
+		t.Fatalf("dry-run mengubah data: %+v", plan.Counts)
+	}
+	if d := findDiff(plan, "alumni", 9); d.Action != "delete" {
+		t.Fatalf("rencana alumni 9 = %+v", d)
+	}
+
+	report, err := checker.Check(context.Background(), models.BackendPostgres, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Counts.Repaired != 6 || report.Counts.Failed != 0 {
+		t.Fatalf("repair = %+v", report.Counts)
+	}
+	if _, legacy := mongo.alumni[0]; legacy || mongo.alumni[3].NIM != "103" {
+		t.Fatalf("dokumen lama tidak diadopsi: %+v", mongo.alumni)
+	}
+
+	again, _ := checker.Check(context.Background(), models.BackendPostgres, false, false)
+	if len(again.Diffs) != 0 {
+		t.Fatalf("setelah repair masih berbeda: %+v", again.Diffs)
+	}
+}
+
+func TestConsistencyRepairFromMongo(t *testing.T) {
+	pg, mongo := newDriftedStores()
+	report, err := newChecker(pg, mongo).Check(context.Background(), models.BackendMongo, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// alumni lama di Mongo memakai id Postgres yang sudah ada, tidak membuat baris baru
+	if _, ok := pg.alumni[1001]; ok || pg.alumni[3].NIM != "103" {
+		t.Fatalf("alumni NIM 103 = %+v", pg.alumni)
+	}
+	if _, ok := pg.alumni[4]; ok || pg.alumni[9].Nama != "Hantu" || pg.jobs[12].NamaPerusahaan != "PT Hilang" {
+		t.Fatalf("postgres = %+v / %+v", pg.alumni, pg.jobs)
+	}
+	if report.Counts.Failed != 0 {
+		t.Fatalf("repair = %+v", report.Counts)
+	}
+}
+
+func TestConsistencyRepairFromMongoBackfillsLegacyID(t *testing.T) {
+	pg := newMemStore([]models.Alumni{{ID: 1, NIM: "101", Nama: "Budi", Email: "budi@mail.com"}}, nil)
+	mongo := newMemStore([]models.Alumni{
+		{ID: 1, NIM: "101", Nama: "Budi", Email: "budi@mail.com"},
+		{ID: 0, NIM: "105", Nama: "Dewi", Email: "dewi@mail.com"},
+	}, nil)
+	checker := newChecker(pg, mongo)
+
+	report, err := checker.Check(context.Background(), models.BackendMongo, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Counts.Repaired != 1 || report.Counts.Failed != 0 {
+		t.Fatalf("repair = %+v", report.Diffs)
+	}
+	// id baru dari Postgres ditulis balik ke dokumen Mongo lama, bukan dokumen kedua dengan NIM sama
+	if pg.alumni[1001].NIM != "105" || report.Diffs[0].ID != 1001 {
+		t.Fatalf("postgres = %+v, diff = %+v", pg.alumni, report.Diffs)
+	}
+	if _, legacy := mongo.alumni[0]; legacy || mongo.alumni[1001].NIM != "105" || len(mongo.alumni) != 2 {
+		t.Fatalf("mongo = %+v", mongo.alumni)
+	}
+
+	// event outbox untuk id baru hanya menimpa dokumen yang sama
+	mongo.UpsertAlumni(context.Background(), pg.alumni[1001])
+	again, _ := checker.Check(context.Background(), models.BackendPostgres, false, false)
+	if len(mongo.alumni) != 2 || len(again.Diffs) != 0 {
+		t.Fatalf("setelah relay = %+v / %+v", mongo.alumni, again.Diffs)
+	}
+}
+
+func TestConsistencyEndpoints(t *testing.T) {
+	pg, mongo := newDriftedStores()
+	svc := &ConsistencyService{Checker: newChecker(pg, mongo)}
+	app := fiber.New()
+	app.Get("/consistency", svc.CheckConsistency)
+	app.Post("/consistency/repair", svc.RepairConsistency)
+
+	if code := call(t, app, "GET", "/consistency?source=oracle", "", nil); code != 400 {
+		t.Fatalf("source tidak valid: status %d, want 400", code)
+	}
+	if code := call(t, app, "GET", "/consistency?format=xml", "", nil); code != 400 {
+		t.Fatalf("format tidak valid: status %d, want 400", code)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/consistency?format=csv", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	resp.Body.Close()
+	if err != nil || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("csv: %v, content-type %q", err, resp.Header.Get("Content-Type"))
+	}
+	// header + 1 missing + 2 extra + alumni 2 (1 field) + alumni 3 (1 field) + pekerjaan 11 (1 field)
+	if len(rows) != 7 || rows[0][0] != "entity" {
+		t.Fatalf("csv rows = %v", rows)
+	}
+
+	// tanpa dry_run=false tidak ada yang diubah
+	var report models.ConsistencyReport
+	call(t, app, "POST", "/consistency/repair", "", &report)
+	if !report.DryRun || len(mongo.alumni) != 4 {
+		t.Fatalf("repair default harus dry-run: %+v", report.Counts)
+	}
+	call(t, app, "POST", "/consistency/repair?dry_run=false", "", &report)
+	if report.DryRun || report.Counts.Repaired != 6 {
+		t.Fatalf("repair = %+v", report.Counts)
+	}
+}
+
+func TestWriteConsistencyCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteConsistencyCSV(&buf, &models.ConsistencyReport{Diffs: []models.ConsistencyDiff{
+		{Entity: "alumni", ID: 2, NIM: "102", Kind: models.ConsistencyMismatch, Action: "upsert", Repaired: true, Fields: []models.FieldDiff{
+			{Field: "nama", Source: "Sari", Target: "Sari, S.T."}, {Field: "email", Source: "a@b", Target: ""},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "entity,id,nim,kind,field,source,target,action,repaired,error\n" +
+		"alumni,2,102,mismatch,nama,Sari,\"Sari, S.T.\",upsert,true,\n" +
+		"alumni,2,102,mismatch,email,a@b,,upsert,true,\n"
+	if buf.String() != want {
+		t.Fatalf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
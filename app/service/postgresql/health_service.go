@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+// HealthCheck = satu dependency yang dicek /readyz (Postgres, MongoDB, folder upload, migration)
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthService melayani probe /healthz & /readyz dan diagnosa admin
+type HealthService struct {
+	Checks []HealthCheck
+	// Timeout = batas waktu tiap pengecekan; pengecekan yang lewat batas dianggap gagal
+	Timeout time.Duration
+	// Pools = statistik pool koneksi per dependency untuk /api/admin/diagnostics
+	Pools   map[string]func() interface{}
+	Started time.Time
+}
+
+// Ready menjalankan semua pengecekan bersamaan, jadi lama respons = pengecekan paling lambat (maks. Timeout)
+func (s *HealthService) Ready(ctx context.Context) models.ReadinessReport {
+	results := make([]models.HealthCheckResult, len(s.Checks))
+	done := make(chan struct{}, len(s.Checks))
+	for i, hc := range s.Checks {
+		go func(i int, hc HealthCheck) {
+			results[i] = s.run(ctx, hc)
+			done <- struct{}{}
+		}(i, hc)
+	}
+	for range s.Checks {
+		<-done
+	}
+
+	report := models.ReadinessReport{Status: models.HealthStatusOK, CheckedAt: time.Now(), Checks: results}
+	for _, r := range results {
+		if r.Status != models.HealthStatusOK {
+			report.Status = models.HealthStatusFail
+		}
+	}
+	return report
+}
+
+// run tidak menunggu Check yang mengabaikan ctx lebih lama dari Timeout
+func (s *HealthService) run(ctx context.Context, hc HealthCheck) models.HealthCheckResult {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() { errc <- hc.Check(ctx) }()
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = fmt.Errorf("tidak ada respons dalam %s", timeout)
+	}
+
+	result := models.HealthCheckResult{
+		Name:      hc.Name,
+		Status:    models.HealthStatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status, result.Error = models.HealthStatusFail, err.Error()
+	}
+	return result
+}
+
+// Liveness: proses hidup dan bisa melayani request. Sengaja tidak mengecek dependency supaya
+// Postgres / MongoDB yang mati tidak membuat semua instance di-restart orchestrator.
+func (s *HealthService) Liveness(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(models.LivenessReport{Status: models.HealthStatusOK, UptimeSeconds: time.Since(s.Started).Seconds()})
+}
+
+// Readiness: 200 kalau semua dependency siap, 503 kalau ada yang gagal (instance dikeluarkan dari load balancer).
+// Pesan error disembunyikan karena endpoint ini tanpa login; detailnya ada di /api/admin/diagnostics.
+func (s *HealthService) Readiness(c *fiber.Ctx) error {
+	report := s.Ready(c.UserContext())
+	for i := range report.Checks {
+		report.Checks[i].Error = ""
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	status := fiber.StatusOK
+	if report.Status != models.HealthStatusOK {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
+
+// GetDiagnostics godoc
+// @Summary Diagnosa dependency & pool koneksi
+// @Description Hasil pengecekan /readyz lengkap dengan pesan error dan latency, ditambah statistik pool koneksi Postgres & MongoDB. Selalu 200; lihat field status.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.DiagnosticsReport
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/diagnostics [get]
+func (s *HealthService) GetDiagnostics(c *fiber.Ctx) error {
+	report := models.DiagnosticsReport{
+		ReadinessReport: s.Ready(c.UserContext()),
+		UptimeSeconds:   time.Since(s.Started).Seconds(),
+		Goroutines:      runtime.NumGoroutine(),
+		Pools:           map[string]interface{}{},
+	}
+	for name, stats := range s.Pools {
+		report.Pools[name] = stats()
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(report)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+func newHealthApp(s *HealthService) *fiber.App {
+	app := fiber.New()
+	app.Get("/healthz", s.Liveness)
+	app.Get("/readyz", s.Readiness)
+	app.Get("/diagnostics", s.GetDiagnostics)
+	return app
+}
+
+func TestReadiness(t *testing.T) {
+	var mongoErr error
+	block := make(chan struct{})
+	defer close(block)
+	s := &HealthService{
+		Checks: []HealthCheck{
+			{Name: "postgres", Check: func(context.Context) error { return nil }},
+			{Name: "mongodb", Check: func(context.Context) error { return mongoErr }},
+		},
+		Timeout: 50 * time.Millisecond,
+		Pools:   map[string]func() interface{}{"postgres": func() interface{} { return map[string]int{"open": 3} }},
+		Started: time.Now(),
+	}
+	app := newHealthApp(s)
+
+	var live models.LivenessReport
+	if code := call(t, app, "GET", "/healthz", "", &live); code != 200 || live.Status != models.HealthStatusOK {
+		t.Fatalf("healthz: status %d, %+v", code, live)
+	}
+
+	var ready models.ReadinessReport
+	if code := call(t, app, "GET", "/readyz", "", &ready); code != 200 || ready.Status != models.HealthStatusOK || len(ready.Checks) != 2 {
+		t.Fatalf("readyz sehat: status %d, %+v", code, ready)
+	}
+	if ready.Checks[0].Name != "postgres" || ready.Checks[1].Name != "mongodb" {
+		t.Fatalf("urutan check harus sama dengan konfigurasi: %+v", ready.Checks)
+	}
+
+	mongoErr = errors.New("server selection error: mongo-internal:27017")
+	if code := call(t, app, "GET", "/readyz", "", &ready); code != 503 || ready.Status != models.HealthStatusFail {
+		t.Fatalf("readyz mongo mati: status %d, %+v", code, ready)
+	}
+	if ready.Checks[1].Status != models.HealthStatusFail || ready.Checks[1].Error != "" {
+		t.Fatalf("readyz publik tidak boleh membocorkan error: %+v", ready.Checks[1])
+	}
+
+	var diag models.DiagnosticsReport
+	if code := call(t, app, "GET", "/diagnostics", "", &diag); code != 200 || diag.Checks[1].Error == "" || diag.Pools["postgres"] == nil {
+		t.Fatalf("diagnostics: status %d, %+v", code, diag)
+	}
+
+	// check yang mengabaikan ctx tetap dipotong Timeout
+	mongoErr = nil
+	s.Checks = append(s.Checks, HealthCheck{Name: "uploads", Check: func(context.Context) error { <-block; return nil }})
+	start := time.Now()
+	report := s.Ready(context.Background())
+	if report.Status != models.HealthStatusFail || report.Checks[2].Status != models.HealthStatusFail {
+		t.Fatalf("check macet harus gagal: %+v", report)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("Ready menunggu check macet %s", time.Since(start))
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+)
+
+// send mengirim request JSON dengan header tambahan dan men-decode body respons ke out (boleh nil)
+func send(t *testing.T, app *fiber.App, method, route, body string, header map[string]string, out interface{}) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, route, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, route, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, route, err)
+		}
+	}
+	return resp
+}
+
+// call mengirim request JSON dan men-decode body respons ke out (boleh nil)
+func call(t *testing.T, app *fiber.App, method, route, body string, out interface{}) int {
+	t.Helper()
+	return send(t, app, method, route, body, nil, out).StatusCode
+}
+
+// callIfMatch seperti call, tapi mengirim If-Match (kosong = tidak dikirim) dan mengembalikan ETag respons
+func callIfMatch(t *testing.T, app *fiber.App, method, route, body, ifMatch string, out interface{}) (int, string) {
+	t.Helper()
+	header := map[string]string{}
+	if ifMatch != "" {
+		header["If-Match"] = ifMatch
+	}
+	resp := send(t, app, method, route, body, header, out)
+	return resp.StatusCode, resp.Header.Get("ETag")
+}
+
+// callBearer mengirim request tanpa body dengan access token, lewat Auth.Required yang sebenarnya
+func callBearer(t *testing.T, app *fiber.App, method, route, token string) int {
+	t.Helper()
+	return send(t, app, method, route, "", map[string]string{"Authorization": "Bearer " + token}, nil).StatusCode
+}
+
+// authAs memasang klaim + permission seolah-olah request sudah lewat Auth.Required dan Require.
+// userID 0 = tanpa klaim, hanya permission.
+func authAs(userID int, perms ...string) fiber.Handler {
+	set := map[string]bool{}
+	for _, p := range perms {
+		set[p] = true
+	}
+	return func(c *fiber.Ctx) error {
+		if userID != 0 {
+			c.Locals("claims", &models.JWTClaims{UserID: strconv.Itoa(userID), Backend: models.BackendPostgres})
+		}
+		c.Locals("permissions", set)
+		return c.Next()
+	}
+}
+
+func seedAlumni(repo *repository.MockAlumniRepository, list ...models.Alumni) {
+	for i := range list {
+		repo.CreateAlumni(context.Background(), &list[i])
+	}
+}
+
+func seedPekerjaan(repo *repository.MockPekerjaanRepository, list ...models.PekerjaanAlumni) {
+	for i := range list {
+		repo.CreatePekerjaan(context.Background(), &list[i])
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+// Transactor dipenuhi repository.UnitOfWork
+type Transactor interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AccountCreator = bagian AuthRepository yang dipakai onboarding
+type AccountCreator interface {
+	ExistsByUsernameOrEmail(ctx context.Context, username, email string) (bool, error)
+	Create(ctx context.Context, username, email, passwordHash, role string, emailVerified bool) (*models.User, error)
+}
+
+// OnboardingService mendaftarkan lulusan baru dalam satu transaksi Postgres: alumni, pekerjaan saat ini
+// dan akun login yang tertaut. Selalu memakai repository Postgres (apa pun DATA_BACKEND) karena
+// users.alumni_id merujuk ke tabel alumni; data ikut tersalin ke Mongo lewat outbox.
+type OnboardingService struct {
+	UoW       Transactor
+	Alumni    repository.AlumniRepositoryInterface
+	Pekerjaan repository.PekerjaanRepositoryInterface
+	Accounts  AccountCreator
+	Users     repository.UserRepositoryInterface
+	// Audit ditulis di transaksi yang sama dengan datanya
+	Audit *middleware.Auditor
+}
+
+// errAccountTaken = username / email sudah dipakai, dicek ulang di dalam transaksi
+var errAccountTaken = errors.New("username/email sudah dipakai")
+
+func validateOnboarding(req *models.OnboardingRequest) error {
+	a := &req.Alumni
+	a.NIM, a.Nama, a.Jurusan, a.Email = strings.TrimSpace(a.NIM), strings.TrimSpace(a.Nama), strings.TrimSpace(a.Jurusan), strings.TrimSpace(a.Email)
+	if a.NIM == "" || a.Nama == "" || a.Jurusan == "" || a.Email == "" {
+		return errors.New("alumni: nim, nama, jurusan, email wajib")
+	}
+	if p := req.Pekerjaan; p != nil && (strings.TrimSpace(p.NamaPerusahaan) == "" || strings.TrimSpace(p.PosisiJabatan) == "" || p.TanggalMulaiKerja.IsZero()) {
+		return errors.New("pekerjaan: nama_perusahaan, posisi_jabatan, tanggal_mulai_kerja wajib")
+	}
+
+	u := &req.User
+	u.Username = strings.TrimSpace(u.Username)
+	u.Email = strings.TrimSpace(u.Email)
+	u.Password = strings.TrimSpace(u.Password)
+	u.Role = strings.ToLower(strings.TrimSpace(u.Role))
+	if u.Email == "" {
+		u.Email = a.Email
+	}
+	if u.Role == "" {
+		u.Role = models.RoleAlumni
+	}
+	if u.Username == "" || u.Password == "" {
+		return errors.New("user: username, password wajib")
+	}
+	if !isEmail(u.Email) {
+		return errors.New("user: format email tidak valid")
+	}
+	return utils.ValidatePassword(u.Password)
+}
+
+// Onboard godoc
+// @Summary Daftarkan lulusan baru (alumni + pekerjaan + akun) sekaligus
+// @Description Alumni, pekerjaan saat ini (opsional) dan akun login yang tertaut ke alumni dibuat dalam satu transaksi:
+// @Description kalau salah satu gagal tidak ada data yang tersimpan. Pekerjaan butuh izin pekerjaan:write.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.OnboardingRequest true "Data lulusan"
+// @Success 201 {object} models.OnboardingResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/onboarding [post]
+func (s *OnboardingService) Onboard(c *fiber.Ctx) error {
+	var req models.OnboardingRequest
+	if err := c.BodyParser(&req); err != nil {
+		var perr *time.ParseError
+		if errors.As(err, &perr) {
+			return c.Status(400).JSON(fiber.Map{"error": "format tanggal tidak valid, gunakan RFC3339 (mis. 2024-01-15T00:00:00Z)"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	if err := validateOnboarding(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Pekerjaan != nil && !middleware.HasPermission(c, models.PermPekerjaanWrite) {
+		return c.Status(403).JSON(fiber.Map{"error": "tidak punya izin: " + models.PermPekerjaanWrite})
+	}
+	hash, err := utils.HashPassword(req.User.Password)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
+	}
+
+	res := models.OnboardingResponse{Message: "lulusan berhasil didaftarkan"}
+	err = s.UoW.Do(c.UserContext(), func(ctx context.Context) error {
+		taken, err := s.Accounts.ExistsByUsernameOrEmail(ctx, req.User.Username, req.User.Email)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errAccountTaken
+		}
+
+		alumniID, err := s.Alumni.CreateAlumni(ctx, &req.Alumni)
+		if err != nil {
+			return err
+		}
+		if res.Alumni, err = s.Alumni.GetAlumniByID(ctx, alumniID); err != nil {
+			return err
+		}
+
+		if req.Pekerjaan != nil {
+			req.Pekerjaan.AlumniID = alumniID
+			pekerjaanID, err := s.Pekerjaan.CreatePekerjaan(ctx, req.Pekerjaan)
+			if err != nil {
+				return err
+			}
+			if res.Pekerjaan, err = s.Pekerjaan.GetPekerjaanByID(ctx, pekerjaanID); err != nil {
+				return err
+			}
+		}
+
+		u, err := s.Accounts.Create(ctx, req.User.Username, req.User.Email, hash, req.User.Role, true)
+		if err != nil {
+			return err
+		}
+		if _, err := s.Users.SetAlumniID(ctx, u.ID, &alumniID); err != nil {
+			return err
+		}
+		u.AlumniID = &alumniID
+		res.User = u
+
+		// audit ikut transaksi: gagal dicatat = tidak ada data yang disimpan
+		if err := s.Audit.LogInTx(ctx, c, models.AuditCreate, models.AuditEntityAlumni, strconv.Itoa(res.Alumni.ID), nil, res.Alumni); err != nil {
+			return err
+		}
+		if res.Pekerjaan != nil {
+			if err := s.Audit.LogInTx(ctx, c, models.AuditCreate, models.AuditEntityPekerjaan, strconv.Itoa(res.Pekerjaan.ID), nil, res.Pekerjaan); err != nil {
+				return err
+			}
+		}
+		return s.Audit.LogInTx(ctx, c, models.AuditCreate, models.AuditEntityUser, strconv.Itoa(u.ID), nil, u)
+	})
+
+	var pqErr *pq.Error
+	switch {
+	case err == nil:
+	case err == errAccountTaken:
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case err == repository.ErrInvalidRole:
+		return c.Status(400).JSON(fiber.Map{"error": "role tidak dikenal, lihat /api/admin/roles"})
+	case errors.As(err, &pqErr) && pqErr.Code == "23505":
+		return c.Status(409).JSON(fiber.Map{"error": "data sudah ada (nim / username / email duplikat)"})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "gagal mendaftarkan lulusan, tidak ada data yang disimpan"})
+	}
+
+	return c.Status(201).JSON(res)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+)
+
+func newOnboardingApp(svc *OnboardingService, perms ...string) *fiber.App {
+	app := fiber.New()
+	app.Post("/onboarding", authAs(0, perms...), svc.Onboard)
+	return app
+}
+
+const onboardingAlumni = `"alumni":{"nim":"2101","nama":"Budi","jurusan":"TI","angkatan":2021,"email":"budi@mail.com"}`
+
+func TestOnboardingCreatesLinkedRecords(t *testing.T) {
+	uow, alumni, jobs, users := &repository.MockUnitOfWork{}, repository.NewMockAlumniRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.Alumni = alumni
+	svc := &OnboardingService{UoW: uow, Alumni: alumni, Pekerjaan: jobs, Accounts: users, Users: users}
+	app := newOnboardingApp(svc, models.PermUsersManage, models.PermAlumniWrite, models.PermPekerjaanWrite)
+
+	var res models.OnboardingResponse
+	body := `{` + onboardingAlumni + `,"pekerjaan":{"alumni_id":99,"nama_perusahaan":"PT Maju","posisi_jabatan":"Engineer","tanggal_mulai_kerja":"2024-01-15T00:00:00Z"},
+		"user":{"username":"budi","password":"rahasia123"}}`
+	if code := call(t, app, "POST", "/onboarding", body, &res); code != 201 {
+		t.Fatalf("status %d, want 201", code)
+	}
+	if res.Alumni == nil || res.Pekerjaan == nil || res.User == nil {
+		t.Fatalf("response = %+v", res)
+	}
+	// alumni_id pekerjaan dari request diabaikan, email & role akun diisi default
+	if res.Pekerjaan.AlumniID != res.Alumni.ID || res.User.Email != "budi@mail.com" || res.User.Role != models.RoleAlumni {
+		t.Fatalf("pekerjaan %+v, user %+v", res.Pekerjaan, res.User)
+	}
+	if u := users.Data[res.User.ID]; u.AlumniID == nil || *u.AlumniID != res.Alumni.ID || !u.EmailVerified {
+		t.Fatalf("user tersimpan = %+v", u)
+	}
+	if uow.Commits != 1 || uow.Rollbacks != 0 {
+		t.Fatalf("commit %d, rollback %d", uow.Commits, uow.Rollbacks)
+	}
+}
+
+func TestOnboardingWithoutPekerjaan(t *testing.T) {
+	uow, alumni, jobs, users := &repository.MockUnitOfWork{}, repository.NewMockAlumniRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.Alumni = alumni
+	svc := &OnboardingService{UoW: uow, Alumni: alumni, Pekerjaan: jobs, Accounts: users, Users: users}
+	app := newOnboardingApp(svc, models.PermUsersManage, models.PermAlumniWrite)
+
+	var res models.OnboardingResponse
+	if code := call(t, app, "POST", "/onboarding", `{`+onboardingAlumni+`,"user":{"username":"budi","password":"rahasia123"}}`, &res); code != 201 {
+		t.Fatalf("status %d, want 201", code)
+	}
+	if res.Pekerjaan != nil || len(jobs.Data) != 0 {
+		t.Fatalf("pekerjaan tidak diminta tapi dibuat: %+v", res.Pekerjaan)
+	}
+}
+
+func TestOnboardingRejects(t *testing.T) {
+	uow, alumni, jobs, users := &repository.MockUnitOfWork{}, repository.NewMockAlumniRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.Alumni = alumni
+	svc := &OnboardingService{UoW: uow, Alumni: alumni, Pekerjaan: jobs, Accounts: users, Users: users}
+	users.InsertUser(&models.User{ID: 1, Username: "budi", Email: "lain@mail.com", Role: models.RoleAlumni})
+	app := newOnboardingApp(svc, models.PermUsersManage, models.PermAlumniWrite)
+
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"alumni tidak lengkap", `{"alumni":{"nim":"1"},"user":{"username":"x","password":"rahasia123"}}`, 400},
+		{"pekerjaan tidak lengkap", `{` + onboardingAlumni + `,"pekerjaan":{"nama_perusahaan":"PT"},"user":{"username":"x","password":"rahasia123"}}`, 400},
+		{"pekerjaan tanpa tanggal mulai", `{` + onboardingAlumni + `,"pekerjaan":{"nama_perusahaan":"PT","posisi_jabatan":"Staff"},"user":{"username":"x","password":"rahasia123"}}`, 400},
+		{"tanggal mulai tidak valid", `{` + onboardingAlumni + `,"pekerjaan":{"nama_perusahaan":"PT","posisi_jabatan":"Staff","tanggal_mulai_kerja":"kemarin"},"user":{"username":"x","password":"rahasia123"}}`, 400},
+		{"tanpa username", `{` + onboardingAlumni + `,"user":{"password":"rahasia123"}}`, 400},
+		{"email tidak valid", `{` + onboardingAlumni + `,"user":{"username":"x","email":"bukan-email","password":"rahasia123"}}`, 400},
+		{"pekerjaan tanpa izin", `{` + onboardingAlumni + `,"pekerjaan":{"nama_perusahaan":"PT","posisi_jabatan":"Staff","tanggal_mulai_kerja":"2024-01-15T00:00:00Z"},"user":{"username":"x","password":"rahasia123"}}`, 403},
+	}
+	for _, tc := range cases {
+		if code := call(t, app, "POST", "/onboarding", tc.body, nil); code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, code, tc.want)
+		}
+	}
+	if uow.Commits+uow.Rollbacks != 0 || len(alumni.Data) != 0 {
+		t.Fatalf("validasi gagal tidak boleh membuka transaksi")
+	}
+
+	if code := call(t, app, "POST", "/onboarding", `{`+onboardingAlumni+`,"user":{"username":"budi","password":"rahasia123"}}`, nil); code != 409 {
+		t.Fatalf("username dipakai: status %d, want 409", code)
+	}
+	if uow.Rollbacks != 1 || len(alumni.Data) != 0 {
+		t.Fatalf("username dipakai: rollback %d, alumni %d", uow.Rollbacks, len(alumni.Data))
+	}
+}
+
+func TestOnboardingRollsBackOnLateFailure(t *testing.T) {
+	uow, alumni, jobs, users := &repository.MockUnitOfWork{}, repository.NewMockAlumniRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.Alumni = alumni
+	svc := &OnboardingService{UoW: uow, Alumni: alumni, Pekerjaan: jobs, Accounts: users, Users: users}
+	app := newOnboardingApp(svc, models.PermUsersManage, models.PermAlumniWrite)
+
+	// role tidak ada baru ketahuan saat akun dibuat, setelah alumni tersimpan di transaksi
+	body := `{` + onboardingAlumni + `,"user":{"username":"budi","password":"rahasia123","role":"hantu"}}`
+	if code := call(t, app, "POST", "/onboarding", body, nil); code != 400 {
+		t.Fatalf("status %d, want 400", code)
+	}
+	if uow.Rollbacks != 1 || uow.Commits != 0 || len(users.Data) != 0 {
+		t.Fatalf("commit %d, rollback %d, users %d", uow.Commits, uow.Rollbacks, len(users.Data))
+	}
+}
+
+// auditLog = middleware.AuditStore di memori; err membuat setiap insert gagal
+type auditLog struct {
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (a *auditLog) Insert(ctx context.Context, e *models.AuditEntry) error {
+	a.entries = append(a.entries, e)
+	return a.err
+}
+
+func TestOnboardingAuditInSameTransaction(t *testing.T) {
+	audit := &auditLog{}
+
+	uow, alumni, jobs, users := &repository.MockUnitOfWork{}, repository.NewMockAlumniRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	users.Alumni = alumni
+	svc := &OnboardingService{UoW: uow, Alumni: alumni, Pekerjaan: jobs, Accounts: users, Users: users,
+		Audit: &middleware.Auditor{Store: audit}}
+	app := newOnboardingApp(svc, models.PermUsersManage, models.PermAlumniWrite)
+
+	body := `{` + onboardingAlumni + `,"user":{"username":"budi","password":"rahasia123"}}`
+	if code := call(t, app, "POST", "/onboarding", body, nil); code != 201 {
+		t.Fatalf("status %d, want 201", code)
+	}
+	if len(audit.entries) != 2 || uow.Commits != 1 {
+		t.Fatalf("audit %d entri, commit %d", len(audit.entries), uow.Commits)
+	}
+
+	// audit gagal ditulis → seluruh onboarding dibatalkan
+	audit.err = errors.New("audit_logs tidak bisa ditulis")
+	body = `{"alumni":{"nim":"2102","nama":"Sari","jurusan":"TI","angkatan":2021,"email":"sari@mail.com"},"user":{"username":"sari","password":"rahasia123"}}`
+	if code := call(t, app, "POST", "/onboarding", body, nil); code != 500 {
+		t.Fatalf("audit gagal: status %d, want 500", code)
+	}
+	if uow.Rollbacks != 1 || uow.Commits != 1 {
+		t.Fatalf("commit %d, rollback %d", uow.Commits, uow.Rollbacks)
+	}
+}
@@ -2,29 +2,65 @@ package service
 
 import (
 	"fmt"
+	"errors"
 	"database/sql"
 	"go_clean/app/models/postgresql"
 	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+	"go_clean/middleware"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// PekerjaanService tidak bergantung backend: Repo adalah repository Postgres atau driver Mongo sesuai DATA_BACKEND.
+// Users tetap di Postgres karena kepemilikan dicek lewat users.alumni_id. Concurrency seperti AlumniService.
 type PekerjaanService struct {
-	Repo *repository.PekerjaanRepository
+	Repo        repository.PekerjaanRepositoryInterface
+	Users       repository.UserRepositoryInterface
+	Concurrency config.ConcurrencyConfig
+	Audit       *middleware.Auditor
+}
+
+// currentUser mengambil user Postgres pemilik token. Token akun Mongo ditolak di sini
+// karena kepemilikan pekerjaan dicek lewat users.alumni_id di Postgres.
+func (s *PekerjaanService) currentUser(c *fiber.Ctx) (*models.User, error) {
+	userID, err := middleware.Claims(c).PostgresUserID()
+	if err != nil {
+		return nil, err
+	}
+	return s.Users.GetUserByID(c.UserContext(), userID)
+}
+
+// userFailed: token tanpa user Postgres (API key, akun Mongo) tidak punya pekerjaan milik sendiri → 403
+func userFailed(c *fiber.Ctx, err error) error {
+	if errors.Is(err, models.ErrNotPostgresUser) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": "Akses hanya untuk akun Postgres"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data user"})
+}
+
+// canModify: pekerjaan:write boleh mengubah data siapa saja, pekerjaan:write_own hanya
+// pekerjaan milik alumni yang terhubung ke akun user
+func canModify(c *fiber.Ctx, user *models.User, alumniID int) bool {
+	if middleware.HasPermission(c, models.PermPekerjaanWrite) {
+		return true
+	}
+	return middleware.HasPermission(c, models.PermPekerjaanWriteOwn) &&
+		user.AlumniID != nil && *user.AlumniID == alumniID
 }
 
 // GetAllPekerjaan godoc
 // @Summary Ambil semua data pekerjaan
-// @Description Mengambil semua pekerjaan dari PostgreSQL (tanpa filter/pagination)
-// @Tags Pekerjaan-PostgresSQL
+// @Description Mengambil semua pekerjaan (tanpa filter/pagination)
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} models.PekerjaanAlumni
 // @Failure 500 {object} models.ErrorResponse
 // @Router /pekerjaan [get]
 func (s *PekerjaanService) GetAllPekerjaan(c *fiber.Ctx) error {
-	pekerjaan, err := s.Repo.GetAllPekerjaan()
+	pekerjaan, err := s.Repo.GetAllPekerjaan(c.UserContext())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -41,11 +77,12 @@ func (s *PekerjaanService) GetAllPekerjaan(c *fiber.Ctx) error {
 // GetPekerjaanByID godoc
 // @Summary Ambil pekerjaan berdasarkan ID
 // @Description Mengambil satu pekerjaan berdasarkan ID
-// @Tags Pekerjaan-PostgresSQL
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Param id path int true "ID Pekerjaan"
 // @Produce json
 // @Success 200 {object} models.PekerjaanAlumni
+// @Header 200 {string} ETag "Versi data, kirim sebagai If-Match saat update / hapus / restore"
 // @Failure 404 {object} models.ErrorResponse
 // @Router /pekerjaan/{id} [get]
 func (s *PekerjaanService) GetPekerjaanByID(c *fiber.Ctx) error {
@@ -57,7 +94,7 @@ func (s *PekerjaanService) GetPekerjaanByID(c *fiber.Ctx) error {
 		})
 	}
 
-	pekerjaan, err := s.Repo.GetPekerjaanByID(id)
+	pekerjaan, err := s.Repo.GetPekerjaanByID(c.UserContext(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -71,6 +108,7 @@ func (s *PekerjaanService) GetPekerjaanByID(c *fiber.Ctx) error {
 		})
 	}
 
+	middleware.SetETag(c, pekerjaan.Version)
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Data pekerjaan berhasil diambil",
@@ -81,7 +119,7 @@ func (s *PekerjaanService) GetPekerjaanByID(c *fiber.Ctx) error {
 // GetPekerjaanList godoc
 // @Summary Ambil data pekerjaan dengan pagination, search, dan sorting
 // @Description Pagination + sorting + search pekerjaan berdasarkan nama_perusahaan atau posisi_jabatan
-// @Tags Pekerjaan-PostgresSQL
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Produce json
 // @Param search query string false "Cari pekerjaan"
@@ -96,13 +134,13 @@ func (s *PekerjaanService) GetPekerjaanList(c *fiber.Ctx) error {
 	sortable := repository.PekerjaanSortable()
 	params := getListParams(c, sortable)
 
-	items, err := repository.ListPekerjaanRepo(params.Search, params.SortBy, params.Order, params.Limit, params.Offset)
+	items, err := s.Repo.ListPekerjaanRepo(c.UserContext(), params.Search, params.SortBy, params.Order, params.Limit, params.Offset)
 	if err != nil {
 		fmt.Printf("ListPekerjaanRepo error: %v\n", err)
 		return c.Status(500).JSON(fiber.Map{"error": "failed to fetch pekerjaan"})
 	}
 
-	total, err := repository.CountPekerjaanRepo(params.Search)
+	total, err := s.Repo.CountPekerjaanRepo(c.UserContext(), params.Search)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to count pekerjaan"})
 	}
@@ -125,7 +163,7 @@ func (s *PekerjaanService) GetPekerjaanList(c *fiber.Ctx) error {
 // GetPekerjaanByAlumniID godoc
 // @Summary Ambil data pekerjaan berdasarkan ID alumni
 // @Description Mengambil semua pekerjaan yang dimiliki alumni tertentu
-// @Tags Pekerjaan-PostgresSQL
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Param alumni_id path int true "ID Alumni"
 // @Produce json
@@ -141,7 +179,7 @@ func (s *PekerjaanService) GetPekerjaanByAlumniID(c *fiber.Ctx) error {
 		})
 	}
 
-	pekerjaan, err := s.Repo.GetPekerjaanByAlumniID(alumniID)
+	pekerjaan, err := s.Repo.GetPekerjaanByAlumniID(c.UserContext(), alumniID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -158,7 +196,7 @@ func (s *PekerjaanService) GetPekerjaanByAlumniID(c *fiber.Ctx) error {
 // CreatePekerjaan godoc
 // @Summary Tambah data pekerjaan baru
 // @Description Tambah pekerjaan (hanya bisa diakses Admin)
-// @Tags Pekerjaan-PostgresSQL
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Accept json
 // @Produce json
@@ -183,7 +221,7 @@ func (s *PekerjaanService) CreatePekerjaan(c *fiber.Ctx) error {
 		})
 	}
 
-	newID, err := s.Repo.CreatePekerjaan(&p)
+	newID, err := s.Repo.CreatePekerjaan(c.UserContext(), &p)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -191,7 +229,8 @@ func (s *PekerjaanService) CreatePekerjaan(c *fiber.Ctx) error {
 		})
 	}
 
-	newPekerjaan, _ := s.Repo.GetPekerjaanByID(newID)
+	newPekerjaan, _ := s.Repo.GetPekerjaanByID(c.UserContext(), newID)
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityPekerjaan, strconv.Itoa(newID), nil, newPekerjaan)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Pekerjaan berhasil ditambahkan",
@@ -201,16 +240,20 @@ func (s *PekerjaanService) CreatePekerjaan(c *fiber.Ctx) error {
 
 // UpdatePekerjaan godoc
 // @Summary Update data pekerjaan
-// @Description User hanya boleh update datanya sendiri, Admin boleh update data siapa saja
-// @Tags Pekerjaan-PostgresSQL
+// @Description Izin pekerjaan:write boleh update data siapa saja, pekerjaan:write_own hanya datanya sendiri.
+// @Description If-Match yang sudah basi dibalas 412 berisi data terbaru.
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path int true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Param request body models.PekerjaanAlumni true "Data pekerjaan baru"
 // @Success 200 {object} models.PekerjaanAlumni
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanAlumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /pekerjaan/{id} [put]
 func (s *PekerjaanService) UpdatePekerjaan(c *fiber.Ctx) error {
     id, err := strconv.Atoi(c.Params("id"))
@@ -218,13 +261,9 @@ func (s *PekerjaanService) UpdatePekerjaan(c *fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "ID pekerjaan tidak valid"})
     }
 
-    role := c.Locals("role").(string)
-    userID := c.Locals("user_id").(int)
-
-    userRepo := repository.UserRepository{DB: s.Repo.DB}
-    user, err := userRepo.GetUserByID(userID)
+    user, err := s.currentUser(c)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Gagal mengambil data user"})
+        return userFailed(c, err)
     }
 
     var p models.PekerjaanAlumni
@@ -232,37 +271,56 @@ func (s *PekerjaanService) UpdatePekerjaan(c *fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Request body tidak valid"})
     }
 
-    existing, err := s.Repo.GetPekerjaanByID(id)
+    version, err := middleware.IfMatch(c, s.Concurrency)
+    if err != nil {
+        return ifMatchFailed(c, err)
+    }
+
+    existing, err := s.Repo.GetPekerjaanByID(c.UserContext(), id)
     if err != nil {
         return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Data pekerjaan tidak ditemukan"})
     }
 
-    if role == "user" && existing.AlumniID != *user.AlumniID {
+    if !canModify(c, user, existing.AlumniID) {
         return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Tidak punya izin mengubah pekerjaan ini"})
     }
+    if staleVersion(version, existing.Version) {
+        return versionConflict(c, existing.Version, existing)
+    }
 
-    rows, err := s.Repo.UpdatePekerjaan(id, &p)
+    rows, err := s.Repo.UpdatePekerjaan(c.UserContext(), id, &p, version)
     if err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Gagal mengupdate pekerjaan"})
     }
 
     if rows == 0 {
+        // diubah pengguna lain di antara GET di atas dan UPDATE
+        if current, err := s.Repo.GetPekerjaanByID(c.UserContext(), id); err == nil && staleVersion(version, current.Version) {
+            return versionConflict(c, current.Version, current)
+        }
         return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Pekerjaan tidak ditemukan"})
     }
 
-    updated, _ := s.Repo.GetPekerjaanByID(id)
+    updated, _ := s.Repo.GetPekerjaanByID(c.UserContext(), id)
+    s.Audit.Log(c, models.AuditUpdate, models.AuditEntityPekerjaan, strconv.Itoa(id), existing, updated)
+    if updated != nil {
+        middleware.SetETag(c, updated.Version)
+    }
     return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil diupdate", "data": updated})
 }
 
 // DeletePekerjaan godoc
 // @Summary Soft delete pekerjaan
-// @Description Menghapus pekerjaan (soft delete). Admin bisa hapus siapa saja, User hanya boleh data miliknya.
-// @Tags Pekerjaan-PostgresSQL
+// @Description Menghapus pekerjaan (soft delete). Izin pekerjaan:write bisa hapus siapa saja, pekerjaan:write_own hanya data miliknya.
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Param id path int true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 200 {string} string "Pekerjaan berhasil dihapus"
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanAlumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /pekerjaan/{id} [delete]
 func (s *PekerjaanService) DeletePekerjaan(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -270,59 +328,68 @@ func (s *PekerjaanService) DeletePekerjaan(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID pekerjaan tidak valid"})
 	}
 
-	role := c.Locals("role").(string)
-	userID := c.Locals("user_id").(int)
+	user, err := s.currentUser(c)
+	if err != nil {
+		return userFailed(c, err)
+	}
 
-	userRepo := repository.UserRepository{DB: s.Repo.DB}
-	user, err := userRepo.GetUserByID(userID)
+	version, err := middleware.IfMatch(c, s.Concurrency)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data user"})
+		return ifMatchFailed(c, err)
 	}
 
-	existing, err := s.Repo.GetPekerjaanByID(id)
+	existing, err := s.Repo.GetPekerjaanByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Pekerjaan tidak ditemukan"})
 	}
 
-	if role == "user" && existing.AlumniID != *user.AlumniID {
+	if !canModify(c, user, existing.AlumniID) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": "Tidak punya izin menghapus pekerjaan ini"})
 	}
+	if staleVersion(version, existing.Version) {
+		return versionConflict(c, existing.Version, existing)
+	}
 
-	rows, err := s.Repo.SoftDeletePekerjaan(id, userID)
+	rows, err := s.Repo.SoftDeletePekerjaan(c.UserContext(), id, user.ID, version)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal menghapus pekerjaan"})
 	}
 
 	if rows == 0 {
+		if current, err := s.Repo.GetPekerjaanByID(c.UserContext(), id); err == nil && staleVersion(version, current.Version) {
+			return versionConflict(c, current.Version, current)
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Pekerjaan tidak ditemukan"})
 	}
 
+	after, _ := s.Repo.GetPekerjaanByID(c.UserContext(), id)
+	s.Audit.Log(c, models.AuditSoftDelete, models.AuditEntityPekerjaan, strconv.Itoa(id), existing, after)
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil dihapus (soft delete)"})
 }
 
 // TrashAllPekerjaan godoc
 // @Summary Ambil semua data yang terhapus (soft delete)
-// @Description Admin melihat semua data trash, User hanya melihat miliknya
-// @Tags Pekerjaan-PostgresSQL
+// @Description Dengan izin pekerjaan:read_all melihat semua data trash, selain itu hanya miliknya
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} models.PekerjaanAlumni
+// @Failure 403 {object} models.ErrorResponse
 // @Router /pekerjaan/trash [get]
 func (s *PekerjaanService) TrashAllPekerjaan(c *fiber.Ctx) error {
-	role := c.Locals("role").(string)
-	userID := c.Locals("user_id").(int)
-
-	userRepo := repository.UserRepository{DB: s.Repo.DB}
-	user, err := userRepo.GetUserByID(userID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data user"})
-	}
-
-	var pekerjaan []models.PekerjaanAlumni
-	if role == "admin" {
-		pekerjaan, err = s.Repo.TrashAllPekerjaan()
+	var err error
+	pekerjaan := []models.PekerjaanAlumni{}
+	if middleware.HasPermission(c, models.PermPekerjaanReadAll) {
+		// dicek duluan: API key tidak punya user Postgres
+		pekerjaan, err = s.Repo.TrashAllPekerjaan(c.UserContext())
 	} else {
-		pekerjaan, err = s.Repo.TrashPekerjaanByAlumniID(*user.AlumniID)
+		user, uerr := s.currentUser(c)
+		if uerr != nil {
+			return userFailed(c, uerr)
+		}
+		if user.AlumniID != nil {
+			pekerjaan, err = s.Repo.TrashPekerjaanByAlumniID(c.UserContext(), *user.AlumniID)
+		}
 	}
 
 	if err != nil {
@@ -335,81 +402,102 @@ func (s *PekerjaanService) TrashAllPekerjaan(c *fiber.Ctx) error {
 // RestorePekerjaan godoc
 // @Summary Restore pekerjaan dari trash
 // @Description Mengembalikan pekerjaan (soft delete → active)
-// @Tags Pekerjaan-PostgresSQL
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Param id path int true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 200 {string} string "Pekerjaan berhasil direstore"
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanAlumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /pekerjaan/restore/{id} [put]
 func (s *PekerjaanService) RestorePekerjaan(c *fiber.Ctx) error {
-	role := c.Locals("role").(string)
-	userID := c.Locals("user_id").(int)
 	pekerjaanID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID pekerjaan tidak valid"})
 	}
 
-	userRepo := repository.UserRepository{DB: s.Repo.DB}
-	user, err := userRepo.GetUserByID(userID)
+	user, err := s.currentUser(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data user"})
+		return userFailed(c, err)
 	}
 
-	existing, err := s.Repo.GetPekerjaanByID(pekerjaanID)
+	version, err := middleware.IfMatch(c, s.Concurrency)
+	if err != nil {
+		return ifMatchFailed(c, err)
+	}
+
+	existing, err := s.Repo.GetPekerjaanByID(c.UserContext(), pekerjaanID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Data pekerjaan tidak ditemukan"})
 	}
 
-	if role == "user" && existing.AlumniID != *user.AlumniID {
+	if !canModify(c, user, existing.AlumniID) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": "Tidak punya izin restore"})
 	}
+	if staleVersion(version, existing.Version) {
+		return versionConflict(c, existing.Version, existing)
+	}
 
-	err = s.Repo.RestorePekerjaanByID(pekerjaanID)
+	rows, err := s.Repo.RestorePekerjaanByID(c.UserContext(), pekerjaanID, version)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal restore pekerjaan"})
 	}
+	if rows == 0 {
+		if current, err := s.Repo.GetPekerjaanByID(c.UserContext(), pekerjaanID); err == nil && staleVersion(version, current.Version) {
+			return versionConflict(c, current.Version, current)
+		}
+	}
 
+	after, _ := s.Repo.GetPekerjaanByID(c.UserContext(), pekerjaanID)
+	s.Audit.Log(c, models.AuditRestore, models.AuditEntityPekerjaan, strconv.Itoa(pekerjaanID), existing, after)
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil di-restore"})
 }
 
 // HardDeletePekerjaan godoc
 // @Summary Hapus permanen pekerjaan
-// @Description Menghapus data secara permanen dari database
-// @Tags Pekerjaan-PostgresSQL
+// @Description Menghapus data secara permanen dari database (butuh izin pekerjaan:hard_delete)
+// @Tags Pekerjaan
 // @Security BearerAuth
 // @Param id path int true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id} (wajib kalau CONCURRENCY_REQUIRE_IF_MATCH=true)"
 // @Success 200 {string} string "Pekerjaan berhasil dihapus permanen"
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.PekerjaanAlumni
+// @Failure 428 {object} models.ErrorResponse
 // @Router /pekerjaan/hard-delete/{id} [delete]
 func (s *PekerjaanService) HardDeletePekerjaan(c *fiber.Ctx) error {
-	role := c.Locals("role").(string)
-	userID := c.Locals("user_id").(int)
 	pekerjaanID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID pekerjaan tidak valid"})
 	}
 
-	userRepo := repository.UserRepository{DB: s.Repo.DB}
-	user, err := userRepo.GetUserByID(userID)
+	version, err := middleware.IfMatch(c, s.Concurrency)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data user"})
+		return ifMatchFailed(c, err)
 	}
 
-	existing, err := s.Repo.GetPekerjaanByID(pekerjaanID)
+	// izin pekerjaan:hard_delete dicek di route
+	existing, err := s.Repo.GetPekerjaanByID(c.UserContext(), pekerjaanID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Pekerjaan tidak ditemukan"})
 	}
-
-	if role == "user" && existing.AlumniID != *user.AlumniID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": "Tidak punya izin menghapus permanen"})
+	if staleVersion(version, existing.Version) {
+		return versionConflict(c, existing.Version, existing)
 	}
 
-	err = s.Repo.HardDeletePekerjaanByID(pekerjaanID)
+	rows, err := s.Repo.HardDeletePekerjaanByID(c.UserContext(), pekerjaanID, version)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal menghapus permanen pekerjaan"})
 	}
+	if rows == 0 {
+		if current, err := s.Repo.GetPekerjaanByID(c.UserContext(), pekerjaanID); err == nil && staleVersion(version, current.Version) {
+			return versionConflict(c, current.Version, current)
+		}
+	}
 
+	s.Audit.Log(c, models.AuditDelete, models.AuditEntityPekerjaan, strconv.Itoa(pekerjaanID), existing, nil)
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil dihapus permanen"})
 }
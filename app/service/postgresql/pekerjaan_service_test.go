@@ -0,0 +1,169 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+)
+
+// newPekerjaanApp memasang semua route pekerjaan di belakang auth (lihat authAs)
+func newPekerjaanApp(svc *PekerjaanService, auth fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(auth)
+	app.Get("/pekerjaan", svc.GetAllPekerjaan)
+	app.Get("/pekerjaan/list", svc.GetPekerjaanList)
+	app.Get("/pekerjaan/trash", svc.TrashAllPekerjaan)
+	app.Get("/pekerjaan/alumni/:alumni_id", svc.GetPekerjaanByAlumniID)
+	app.Get("/pekerjaan/:id", svc.GetPekerjaanByID)
+	app.Post("/pekerjaan", svc.CreatePekerjaan)
+	app.Put("/pekerjaan/restore/:id", svc.RestorePekerjaan)
+	app.Put("/pekerjaan/:id", svc.UpdatePekerjaan)
+	app.Delete("/pekerjaan/hard-delete/:id", svc.HardDeletePekerjaan)
+	app.Delete("/pekerjaan/:id", svc.DeletePekerjaan)
+	return app
+}
+
+func TestPekerjaanCreateAndList(t *testing.T) {
+	svc := &PekerjaanService{Repo: repository.NewMockPekerjaanRepository(), Users: repository.NewMockUserRepository()}
+	app := newPekerjaanApp(svc, authAs(1, models.PermPekerjaanWrite))
+
+	if code := call(t, app, "POST", "/pekerjaan", `{"nama_perusahaan":"PT Maju"}`, nil); code != 400 {
+		t.Fatalf("field wajib kosong: status %d, want 400", code)
+	}
+	for _, body := range []string{
+		`{"alumni_id":1,"nama_perusahaan":"PT Maju","posisi_jabatan":"Engineer"}`,
+		`{"alumni_id":2,"nama_perusahaan":"CV Sentosa","posisi_jabatan":"Analyst"}`,
+		`{"alumni_id":2,"nama_perusahaan":"PT Makmur","posisi_jabatan":"Manager"}`,
+	} {
+		if code := call(t, app, "POST", "/pekerjaan", body, nil); code != 201 {
+			t.Fatalf("create %s: status %d, want 201", body, code)
+		}
+	}
+
+	var resp models.UserResponse[models.PekerjaanAlumni]
+	call(t, app, "GET", "/pekerjaan/list?search=pt&sortBy=nama_perusahaan&order=desc", "", &resp)
+	if resp.Meta.Total != 2 || len(resp.Data) != 2 || resp.Data[0].NamaPerusahaan != "PT Makmur" {
+		t.Fatalf("list = %+v, meta %+v", resp.Data, resp.Meta)
+	}
+
+	var byAlumni struct {
+		Data []models.PekerjaanAlumni `json:"data"`
+	}
+	call(t, app, "GET", "/pekerjaan/alumni/2", "", &byAlumni)
+	if len(byAlumni.Data) != 2 {
+		t.Fatalf("pekerjaan alumni 2 = %d, want 2", len(byAlumni.Data))
+	}
+	if code := call(t, app, "GET", "/pekerjaan/99", "", nil); code != 404 {
+		t.Fatalf("id tidak ada: status %d, want 404", code)
+	}
+}
+
+func TestPekerjaanWriteOwnOnlyTouchesOwnData(t *testing.T) {
+	jobs, users := repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	svc := &PekerjaanService{Repo: jobs, Users: users}
+	users.InsertUser(&models.User{ID: 7, Username: "budi", Role: models.RoleAlumni, AlumniID: intPtr(1)})
+	seedPekerjaan(jobs,
+		models.PekerjaanAlumni{AlumniID: 1, NamaPerusahaan: "PT Maju", PosisiJabatan: "Engineer"},
+		models.PekerjaanAlumni{AlumniID: 2, NamaPerusahaan: "CV Sentosa", PosisiJabatan: "Analyst"},
+	)
+	app := newPekerjaanApp(svc, authAs(7, models.PermPekerjaanWriteOwn))
+
+	update := `{"nama_perusahaan":"PT Maju Jaya","posisi_jabatan":"Lead"}`
+	if code := call(t, app, "PUT", "/pekerjaan/2", update, nil); code != 403 {
+		t.Fatalf("update milik orang lain: status %d, want 403", code)
+	}
+	if code := call(t, app, "DELETE", "/pekerjaan/2", "", nil); code != 403 {
+		t.Fatalf("hapus milik orang lain: status %d, want 403", code)
+	}
+	if code := call(t, app, "PUT", "/pekerjaan/1", update, nil); code != 200 {
+		t.Fatalf("update milik sendiri: status %d, want 200", code)
+	}
+	if jobs.Data[1].NamaPerusahaan != "PT Maju Jaya" || jobs.Data[2].NamaPerusahaan != "CV Sentosa" {
+		t.Fatalf("data setelah update: %+v / %+v", jobs.Data[1], jobs.Data[2])
+	}
+
+	// user tidak ada di Postgres → 500, tidak boleh lolos cek kepemilikan
+	if code := call(t, newPekerjaanApp(svc, authAs(99, models.PermPekerjaanWriteOwn)), "PUT", "/pekerjaan/1", update, nil); code != 500 {
+		t.Fatalf("user tidak dikenal: status %d, want 500", code)
+	}
+}
+
+// API key / akun Mongo tidak punya users.alumni_id: write_own ditolak 403, bukan 500
+func TestPekerjaanWriteOwnWithoutPostgresUser(t *testing.T) {
+	jobs := repository.NewMockPekerjaanRepository()
+	seedPekerjaan(jobs, models.PekerjaanAlumni{AlumniID: 1, NamaPerusahaan: "PT Maju", PosisiJabatan: "Engineer"})
+	svc := &PekerjaanService{Repo: jobs, Users: repository.NewMockUserRepository()}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("claims", &models.JWTClaims{UserID: "3", Backend: middleware.BackendAPIKey})
+		c.Locals("permissions", map[string]bool{models.PermPekerjaanWriteOwn: true})
+		return c.Next()
+	})
+	app.Put("/pekerjaan/:id", svc.UpdatePekerjaan)
+	app.Get("/pekerjaan/trash", svc.TrashAllPekerjaan)
+
+	if code := call(t, app, "PUT", "/pekerjaan/1", `{"nama_perusahaan":"PT Lain","posisi_jabatan":"Lead"}`, nil); code != 403 {
+		t.Fatalf("update: status %d, want 403", code)
+	}
+	if code := call(t, app, "GET", "/pekerjaan/trash", "", nil); code != 403 {
+		t.Fatalf("trash: status %d, want 403", code)
+	}
+}
+
+func TestPekerjaanSoftDeleteTrashRestoreHardDelete(t *testing.T) {
+	jobs, users := repository.NewMockPekerjaanRepository(), repository.NewMockUserRepository()
+	svc := &PekerjaanService{Repo: jobs, Users: users}
+	users.InsertUser(&models.User{ID: 7, Username: "budi", Role: models.RoleAlumni, AlumniID: intPtr(1)})
+	users.InsertUser(&models.User{ID: 1, Username: "admin", Role: models.RoleAdmin})
+	seedPekerjaan(jobs,
+		models.PekerjaanAlumni{AlumniID: 1, NamaPerusahaan: "PT Maju", PosisiJabatan: "Engineer"},
+		models.PekerjaanAlumni{AlumniID: 2, NamaPerusahaan: "CV Sentosa", PosisiJabatan: "Analyst"},
+	)
+	owner := newPekerjaanApp(svc, authAs(7, models.PermPekerjaanWriteOwn))
+	admin := newPekerjaanApp(svc, authAs(1, models.PermPekerjaanWrite, models.PermPekerjaanReadAll, models.PermPekerjaanHardDelete))
+
+	if code := call(t, owner, "DELETE", "/pekerjaan/1", "", nil); code != 200 {
+		t.Fatalf("soft delete: status %d", code)
+	}
+	if code := call(t, owner, "DELETE", "/pekerjaan/1", "", nil); code != 404 {
+		t.Fatalf("soft delete kedua: status %d, want 404", code)
+	}
+	if code := call(t, admin, "DELETE", "/pekerjaan/2", "", nil); code != 200 {
+		t.Fatalf("soft delete admin: status %d", code)
+	}
+	if jobs.Data[1].DeletedBy != "7" {
+		t.Fatalf("deleted_by = %q, want 7", jobs.Data[1].DeletedBy)
+	}
+
+	var list struct {
+		Data []models.PekerjaanAlumni `json:"data"`
+	}
+	call(t, admin, "GET", "/pekerjaan", "", &list)
+	if len(list.Data) != 0 {
+		t.Fatalf("data di trash ikut tampil: %+v", list.Data)
+	}
+	call(t, owner, "GET", "/pekerjaan/trash", "", &list)
+	if len(list.Data) != 1 || list.Data[0].ID != 1 {
+		t.Fatalf("trash milik sendiri = %+v", list.Data)
+	}
+	call(t, admin, "GET", "/pekerjaan/trash", "", &list)
+	if len(list.Data) != 2 {
+		t.Fatalf("trash semua = %d, want 2", len(list.Data))
+	}
+
+	if code := call(t, owner, "PUT", "/pekerjaan/restore/1", "", nil); code != 200 || jobs.Data[1].IsDeleted {
+		t.Fatalf("restore: status %d, is_delete %v", code, jobs.Data[1].IsDeleted)
+	}
+	if code := call(t, admin, "DELETE", "/pekerjaan/hard-delete/2", "", nil); code != 200 {
+		t.Fatalf("hard delete: status %d", code)
+	}
+	if _, ok := jobs.Data[2]; ok {
+		t.Fatal("pekerjaan 2 masih ada setelah hard delete")
+	}
+	if code := call(t, admin, "DELETE", "/pekerjaan/hard-delete/2", "", nil); code != 404 {
+		t.Fatalf("hard delete kedua: status %d, want 404", code)
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+)
+
+// ReplicaWriter = tujuan replikasi (koleksi Mongo). Semua operasi harus idempotent karena
+// satu event bisa diterapkan lebih dari sekali (relay mati sebelum MarkDone).
+type ReplicaWriter interface {
+	UpsertAlumni(ctx context.Context, a models.Alumni) error
+	DeleteAlumni(ctx context.Context, id int) error
+	UpsertPekerjaan(ctx context.Context, p models.PekerjaanAlumni) error
+	DeletePekerjaan(ctx context.Context, id int) error
+}
+
+// errBadEvent = event yang tidak akan pernah berhasil diterapkan; langsung masuk dead-letter
+var errBadEvent = errors.New("event outbox tidak valid")
+
+// purgeEvery = jarak antar pembersihan event yang sudah tersalin
+const purgeEvery = time.Hour
+
+// OutboxRelay menyalin event outbox_events ke Mongo. Event satu baris (Key) diterapkan berurutan:
+// kalau satu event gagal, event berikutnya untuk baris yang sama ditahan sampai event itu berhasil
+// atau masuk dead-letter. Baris lain tetap jalan: Repo.Pending tidak mengembalikan event yang ditahan,
+// jadi baris yang macet tidak memenuhi batch.
+type OutboxRelay struct {
+	Repo        repository.OutboxRepositoryInterface
+	Replica     ReplicaWriter
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Retention = umur event done sebelum dihapus
+	Retention time.Duration
+	// Now bisa diganti di test
+	Now func() time.Time
+
+	mu            sync.Mutex
+	lastRelayedAt *time.Time
+	running       bool
+	lastPurge     time.Time
+}
+
+func (r *OutboxRelay) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// backoff = BaseBackoff * 2^(attempts-1), maksimal MaxBackoff
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := r.BaseBackoff
+	for i := 1; i < attempts && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	return d
+}
+
+func (r *OutboxRelay) apply(ctx context.Context, e models.OutboxEvent) error {
+	switch e.Aggregate + "/" + e.Op {
+	case models.OutboxAggregateAlumni + "/" + models.OutboxOpUpsert:
+		var a models.Alumni
+		if err := json.Unmarshal(e.Payload, &a); err != nil || a.ID != e.AggregateID {
+			return fmt.Errorf("%w: payload alumni rusak", errBadEvent)
+		}
+		return r.Replica.UpsertAlumni(ctx, a)
+	case models.OutboxAggregateAlumni + "/" + models.OutboxOpDelete:
+		return r.Replica.DeleteAlumni(ctx, e.AggregateID)
+	case models.OutboxAggregatePekerjaan + "/" + models.OutboxOpUpsert:
+		var p models.PekerjaanAlumni
+		if err := json.Unmarshal(e.Payload, &p); err != nil || p.ID != e.AggregateID {
+			return fmt.Errorf("%w: payload pekerjaan rusak", errBadEvent)
+		}
+		return r.Replica.UpsertPekerjaan(ctx, p)
+	case models.OutboxAggregatePekerjaan + "/" + models.OutboxOpDelete:
+		return r.Replica.DeletePekerjaan(ctx, e.AggregateID)
+	default:
+		return fmt.Errorf("%w: %s/%s tidak dikenal", errBadEvent, e.Aggregate, e.Op)
+	}
+}
+
+// parentKey = baris alumni pemilik event upsert pekerjaan. Hapus alumni di Mongo ikut menghapus pekerjaannya,
+// sedangkan ON DELETE CASCADE di Postgres tidak menulis event pekerjaan; jadi selama upsert pekerjaan masih
+// tertahan, event alumni pemiliknya ikut ditahan supaya upsert itu tidak menghidupkan lagi dokumen yatim.
+func parentKey(e models.OutboxEvent) string {
+	if e.Aggregate != models.OutboxAggregatePekerjaan || e.Op != models.OutboxOpUpsert {
+		return ""
+	}
+	var p struct {
+		AlumniID int `json:"alumni_id"`
+	}
+	if json.Unmarshal(e.Payload, &p) != nil || p.AlumniID == 0 {
+		return ""
+	}
+	return models.OutboxAggregateAlumni + ":" + strconv.Itoa(p.AlumniID)
+}
+
+// RunOnce memproses satu batch dan mengembalikan jumlah event yang berhasil tersalin.
+// Kalau instance lain sedang memegang lock relay, batch dilewati.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	unlock, ok, err := r.Repo.TryLock(ctx)
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer unlock()
+
+	events, err := r.Repo.Pending(ctx, r.now(), r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	relayed := 0
+	blocked := map[string]bool{}
+	block := func(e models.OutboxEvent) {
+		blocked[e.Key()] = true
+		if parent := parentKey(e); parent != "" {
+			blocked[parent] = true
+		}
+	}
+	// Pending sudah membuang event yang ditahan; blocked menangani event yang gagal di batch ini
+	for _, e := range events {
+		key := e.Key()
+		if blocked[key] {
+			block(e)
+			continue
+		}
+		now := r.now()
+
+		applyErr := r.apply(ctx, e)
+		if applyErr == nil {
+			if err := r.Repo.MarkDone(ctx, e.ID); err != nil {
+				return relayed, err
+			}
+			relayed++
+			r.mu.Lock()
+			r.lastRelayedAt = &now
+			r.mu.Unlock()
+			continue
+		}
+
+		// event dead-letter tidak menahan event berikutnya: upsert selalu membawa isi baris terbaru
+		attempts := e.Attempts + 1
+		dead := errors.Is(applyErr, errBadEvent) || attempts >= r.MaxAttempts
+		if !dead {
+			block(e)
+		}
+		if err := r.Repo.MarkFailed(ctx, e.ID, applyErr.Error(), now.Add(r.backoff(attempts)), dead); err != nil {
+			return relayed, err
+		}
+		if dead {
+			log.Printf("outbox: event %d (%s) masuk dead-letter: %v", e.ID, key, applyErr)
+		}
+	}
+	return relayed, nil
+}
+
+// purge menghapus event done yang lebih tua dari Retention, paling sering sekali per purgeEvery
+func (r *OutboxRelay) purge(ctx context.Context) {
+	now := r.now()
+	if r.Retention <= 0 || now.Sub(r.lastPurge) < purgeEvery {
+		return
+	}
+	r.lastPurge = now
+	if n, err := r.Repo.PurgeDone(ctx, now.Add(-r.Retention)); err != nil {
+		log.Printf("outbox: gagal membersihkan event: %v", err)
+	} else if n > 0 {
+		log.Printf("outbox: %d event lama dihapus", n)
+	}
+}
+
+// Run menjalankan relay tiap interval sampai ctx dibatalkan
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	r.mu.Lock()
+	r.running = true
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// batch penuh langsung disusul batch berikutnya supaya antrian cepat habis
+		for {
+			n, err := r.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("outbox: relay gagal: %v", err)
+			}
+			if err != nil || n < r.BatchSize {
+				break
+			}
+		}
+		r.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *OutboxRelay) LastRelayedAt() *time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastRelayedAt
+}
+
+func (r *OutboxRelay) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// ReplicationService = endpoint admin untuk memantau replikasi Postgres → Mongo.
+// Relay nil kalau OUTBOX_RELAY_ENABLED=false di instance ini.
+type ReplicationService struct {
+	Repo  repository.OutboxRepositoryInterface
+	Relay *OutboxRelay
+	Now   func() time.Time
+	Audit *middleware.Auditor
+}
+
+// GetReplicationStatus godoc
+// @Summary Status replikasi alumni & pekerjaan ke MongoDB
+// @Description Jumlah event menunggu & dead-letter, lag (umur event pending tertua) dan kondisi relay di instance ini.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ReplicationStatus
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/replication [get]
+func (s *ReplicationService) GetReplicationStatus(c *fiber.Ctx) error {
+	status, err := s.Repo.Status(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	if status.OldestPendingAt != nil {
+		status.LagSeconds = now.Sub(*status.OldestPendingAt).Seconds()
+	}
+	if s.Relay != nil {
+		status.LastRelayedAt = s.Relay.LastRelayedAt()
+		status.RelayRunning = s.Relay.Running()
+	}
+	return c.JSON(status)
+}
+
+// ListDeadEvents godoc
+// @Summary Daftar event replikasi yang gagal (dead-letter)
+// @Description Terbaru dulu, lengkap dengan error terakhir dan payload-nya.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Halaman"
+// @Param limit query int false "Limit data (maks 100)"
+// @Success 200 {object} models.UserResponse[models.OutboxEvent]
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/replication/dead [get]
+func (s *ReplicationService) ListDeadEvents(c *fiber.Ctx) error {
+	params := getListParams(c, nil)
+	events, total, err := s.Repo.Dead(c.UserContext(), params.Limit, params.Offset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(models.UserResponse[models.OutboxEvent]{
+		Data: events,
+		Meta: models.MetaInfo{
+			Page:  params.Page,
+			Limit: params.Limit,
+			Total: total,
+			Pages: (total + params.Limit - 1) / params.Limit,
+			Order: "desc",
+		},
+	})
+}
+
+// RetryDeadEvent godoc
+// @Summary Coba ulang event dead-letter
+// @Description Event dikembalikan ke antrian dengan hitungan percobaan dari nol dan diproses relay berikutnya.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID event"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/replication/dead/{id}/retry [post]
+func (s *ReplicationService) RetryDeadEvent(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "id tidak valid"})
+	}
+	n, err := s.Repo.Requeue(c.UserContext(), id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	if n == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "event dead-letter tidak ditemukan"})
+	}
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityOutboxEvent, c.Params("id"),
+		fiber.Map{"status": models.OutboxDead}, fiber.Map{"status": models.OutboxPending})
+	return c.JSON(fiber.Map{"message": "event dimasukkan kembali ke antrian"})
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+)
+
+// fakeReplica mencatat urutan operasi; fail[key] = sisa jumlah kegagalan untuk baris itu
+type fakeReplica struct {
+	applied []string
+	fail    map[string]int
+}
+
+func (f *fakeReplica) do(key string) error {
+	if f.fail[key] > 0 {
+		f.fail[key]--
+		return errors.New("mongo tidak bisa dihubungi")
+	}
+	f.applied = append(f.applied, key)
+	return nil
+}
+
+func (f *fakeReplica) UpsertAlumni(ctx context.Context, a models.Alumni) error {
+	return f.do("upsert alumni:" + a.Nama)
+}
+func (f *fakeReplica) DeleteAlumni(ctx context.Context, id int) error { return f.do("delete alumni") }
+func (f *fakeReplica) UpsertPekerjaan(ctx context.Context, p models.PekerjaanAlumni) error {
+	return f.do("upsert pekerjaan:" + p.NamaPerusahaan)
+}
+func (f *fakeReplica) DeletePekerjaan(ctx context.Context, id int) error {
+	return f.do("delete pekerjaan")
+}
+
+// newTestRelay memakai jam *now supaya test bisa memajukan waktu antar RunOnce
+func newTestRelay(repo *repository.MockOutboxRepository, replica *fakeReplica, now *time.Time) *OutboxRelay {
+	return &OutboxRelay{
+		Repo: repo, Replica: replica, BatchSize: 100, MaxAttempts: 3,
+		BaseBackoff: time.Second, MaxBackoff: 10 * time.Second, Retention: time.Hour,
+		Now: func() time.Time { return *now },
+	}
+}
+
+func addEvent(repo *repository.MockOutboxRepository, now time.Time, aggregate string, id int, op string, payload interface{}) int64 {
+	e := models.OutboxEvent{Aggregate: aggregate, AggregateID: id, Op: op, CreatedAt: now}
+	if payload != nil {
+		e.Payload, _ = json.Marshal(payload)
+	}
+	return repo.Add(e)
+}
+
+func runRelay(t *testing.T, relay *OutboxRelay) int {
+	t.Helper()
+	n, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	return n
+}
+
+func TestOutboxRelayKeepsOrderPerRow(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	addEvent(repo, now, models.OutboxAggregateAlumni, 1, models.OutboxOpUpsert, models.Alumni{ID: 1, Nama: "v1"})
+	addEvent(repo, now, models.OutboxAggregatePekerjaan, 5, models.OutboxOpUpsert, models.PekerjaanAlumni{ID: 5, NamaPerusahaan: "PT Maju"})
+	addEvent(repo, now, models.OutboxAggregateAlumni, 1, models.OutboxOpUpsert, models.Alumni{ID: 1, Nama: "v2"})
+	replica.fail["upsert alumni:v1"] = 1
+
+	// v1 gagal: v2 untuk baris yang sama ditahan, pekerjaan tetap tersalin
+	if n := runRelay(t, relay); n != 1 {
+		t.Fatalf("relayed = %d, want 1", n)
+	}
+	if len(replica.applied) != 1 || replica.applied[0] != "upsert pekerjaan:PT Maju" {
+		t.Fatalf("applied = %v", replica.applied)
+	}
+	if e := repo.Events[1]; e.Attempts != 1 || e.Status != models.OutboxPending || !e.NextAttemptAt.Equal(now.Add(time.Second)) {
+		t.Fatalf("event gagal = %+v", e)
+	}
+
+	// belum waktunya dicoba ulang
+	if n := runRelay(t, relay); n != 0 {
+		t.Fatalf("sebelum backoff: relayed = %d, want 0", n)
+	}
+
+	now = now.Add(time.Second)
+	if n := runRelay(t, relay); n != 2 {
+		t.Fatalf("setelah backoff: relayed = %d, want 2", n)
+	}
+	want := []string{"upsert pekerjaan:PT Maju", "upsert alumni:v1", "upsert alumni:v2"}
+	for i, w := range want {
+		if replica.applied[i] != w {
+			t.Fatalf("applied = %v, want %v", replica.applied, want)
+		}
+	}
+	if last := relay.LastRelayedAt(); last == nil || !last.Equal(now) {
+		t.Fatalf("LastRelayedAt = %v", last)
+	}
+}
+
+func TestOutboxRelayAlumniDeleteWaitsForItsPekerjaan(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	addEvent(repo, now, models.OutboxAggregatePekerjaan, 5, models.OutboxOpUpsert, models.PekerjaanAlumni{ID: 5, AlumniID: 1, NamaPerusahaan: "PT Maju"})
+	addEvent(repo, now, models.OutboxAggregateAlumni, 1, models.OutboxOpDelete, nil)
+	addEvent(repo, now, models.OutboxAggregateAlumni, 2, models.OutboxOpDelete, nil)
+	replica.fail["upsert pekerjaan:PT Maju"] = 1
+
+	// upsert pekerjaan alumni 1 gagal: hapus alumni 1 (cascade di Mongo) harus menunggu, alumni lain jalan
+	if n := runRelay(t, relay); n != 1 {
+		t.Fatalf("relayed = %d, want 1", n)
+	}
+	now = now.Add(time.Second)
+	runRelay(t, relay)
+	// hapus alumni 1 baru ikut batch setelah upsert pekerjaannya tersalin
+	runRelay(t, relay)
+	want := []string{"delete alumni", "upsert pekerjaan:PT Maju", "delete alumni"}
+	if len(replica.applied) != len(want) {
+		t.Fatalf("applied = %v, want %v", replica.applied, want)
+	}
+	for i, w := range want {
+		if replica.applied[i] != w {
+			t.Fatalf("applied = %v, want %v", replica.applied, want)
+		}
+	}
+}
+
+func TestOutboxRelayStuckRowDoesNotFillBatch(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	relay.BatchSize = 3
+	for i := 0; i < 5; i++ {
+		addEvent(repo, now, models.OutboxAggregateAlumni, 1, models.OutboxOpUpsert, models.Alumni{ID: 1, Nama: "macet"})
+	}
+	addEvent(repo, now, models.OutboxAggregateAlumni, 2, models.OutboxOpUpsert, models.Alumni{ID: 2, Nama: "lain"})
+	replica.fail["upsert alumni:macet"] = 100
+
+	runRelay(t, relay)
+	// event alumni 1 yang tersisa ditahan di query, jadi alumni 2 masuk batch berikutnya
+	if n := runRelay(t, relay); n != 1 || replica.applied[len(replica.applied)-1] != "upsert alumni:lain" {
+		t.Fatalf("relayed = %d, applied = %v", n, replica.applied)
+	}
+}
+
+func TestOutboxRelayDeadLetter(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	id := addEvent(repo, now, models.OutboxAggregatePekerjaan, 5, models.OutboxOpDelete, nil)
+	addEvent(repo, now, models.OutboxAggregatePekerjaan, 5, models.OutboxOpUpsert, models.PekerjaanAlumni{ID: 5, NamaPerusahaan: "PT Baru"})
+	replica.fail["delete pekerjaan"] = 10
+
+	// backoff naik 1s, 2s lalu percobaan ke-3 = MaxAttempts → dead-letter
+	for i, wait := range []time.Duration{0, time.Second, 2 * time.Second} {
+		now = now.Add(wait)
+		runRelay(t, relay)
+		if got := repo.Events[id].Attempts; got != i+1 {
+			t.Fatalf("percobaan %d: attempts = %d", i+1, got)
+		}
+	}
+	if e := repo.Events[id]; e.Status != models.OutboxDead || e.LastError == "" {
+		t.Fatalf("event = %+v, want dead", e)
+	}
+	// event dead tidak menahan event berikutnya
+	if len(replica.applied) != 1 || replica.applied[0] != "upsert pekerjaan:PT Baru" {
+		t.Fatalf("applied = %v", replica.applied)
+	}
+}
+
+func TestOutboxRelayBadEventIsDeadImmediately(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	bad := repo.Add(models.OutboxEvent{Aggregate: models.OutboxAggregateAlumni, AggregateID: 1, Op: models.OutboxOpUpsert,
+		Payload: json.RawMessage(`"bukan objek"`), CreatedAt: now})
+	unknown := addEvent(repo, now, "mahasiswa", 1, models.OutboxOpUpsert, nil)
+
+	runRelay(t, relay)
+	for _, id := range []int64{bad, unknown} {
+		if e := repo.Events[id]; e.Status != models.OutboxDead || e.Attempts != 1 {
+			t.Fatalf("event %d = %+v, want dead setelah 1 percobaan", id, e)
+		}
+	}
+}
+
+func TestOutboxRelaySkipsWhenLocked(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	addEvent(repo, now, models.OutboxAggregateAlumni, 1, models.OutboxOpDelete, nil)
+	repo.Locked = true
+	if n := runRelay(t, relay); n != 0 || len(replica.applied) != 0 {
+		t.Fatalf("lock dipegang instance lain: relayed %d", n)
+	}
+}
+
+func TestOutboxRelayBackoffCap(t *testing.T) {
+	r := &OutboxRelay{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 50: 10 * time.Second} {
+		if got := r.backoff(attempts); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestReplicationEndpoints(t *testing.T) {
+	repo, replica := repository.NewMockOutboxRepository(), &fakeReplica{fail: map[string]int{}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, replica, &now)
+	now = time.Now()
+	repo.Add(models.OutboxEvent{Aggregate: models.OutboxAggregateAlumni, AggregateID: 1, Op: models.OutboxOpDelete,
+		CreatedAt: now.Add(-90 * time.Second)})
+	dead := repo.Add(models.OutboxEvent{Aggregate: "mahasiswa", AggregateID: 2, Op: models.OutboxOpDelete, Status: models.OutboxDead})
+
+	svc := &ReplicationService{Repo: repo, Relay: relay, Now: func() time.Time { return now }}
+	app := fiber.New()
+	app.Get("/replication", svc.GetReplicationStatus)
+	app.Get("/replication/dead", svc.ListDeadEvents)
+	app.Post("/replication/dead/:id/retry", svc.RetryDeadEvent)
+
+	var status models.ReplicationStatus
+	call(t, app, "GET", "/replication", "", &status)
+	if status.Pending != 1 || status.Dead != 1 || status.LagSeconds < 89 || status.LagSeconds > 91 || status.RelayRunning {
+		t.Fatalf("status = %+v", status)
+	}
+
+	var list models.UserResponse[models.OutboxEvent]
+	call(t, app, "GET", "/replication/dead", "", &list)
+	if list.Meta.Total != 1 || len(list.Data) != 1 || list.Data[0].ID != dead {
+		t.Fatalf("dead = %+v", list)
+	}
+
+	if code := call(t, app, "POST", "/replication/dead/abc/retry", "", nil); code != 400 {
+		t.Fatalf("id tidak valid: status %d, want 400", code)
+	}
+	if code := call(t, app, "POST", "/replication/dead/1/retry", "", nil); code != 404 {
+		t.Fatalf("event bukan dead: status %d, want 404", code)
+	}
+	if code := call(t, app, "POST", "/replication/dead/2/retry", "", nil); code != 200 {
+		t.Fatalf("retry: status %d, want 200", code)
+	}
+	if e := repo.Events[dead]; e.Status != models.OutboxPending || e.Attempts != 0 {
+		t.Fatalf("setelah retry = %+v", e)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+type RoleService struct {
+	Repo repository.RoleRepositoryInterface
+	// Permissions dihapus setelah role diubah supaya langsung berlaku di instance ini
+	Permissions *middleware.PermissionCache
+	Audit       *middleware.Auditor
+}
+
+// normalizePermissions memvalidasi permission terhadap katalog dan membuang duplikat
+func normalizePermissions(perms []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if _, ok := models.AllPermissions[p]; !ok {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "permission tidak dikenal: "+p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func roleError(c *fiber.Ctx, err error) error {
+	if fe, ok := err.(*fiber.Error); ok {
+		return c.Status(fe.Code).JSON(fiber.Map{"error": fe.Message})
+	}
+	if err == sql.ErrNoRows {
+		return c.Status(404).JSON(fiber.Map{"error": "role tidak ditemukan"})
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return c.Status(409).JSON(fiber.Map{"error": "role sudah ada"})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "db error"})
+}
+
+// ListPermissions godoc
+// @Summary Katalog permission
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /admin/permissions [get]
+func (s *RoleService) ListPermissions(c *fiber.Ctx) error {
+	return c.JSON(models.AllPermissions)
+}
+
+// ListRoles godoc
+// @Summary Daftar role beserta permission
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Role
+// @Router /admin/roles [get]
+func (s *RoleService) ListRoles(c *fiber.Ctx) error {
+	roles, err := s.Repo.List(c.UserContext())
+	if err != nil {
+		return roleError(c, err)
+	}
+	return c.JSON(roles)
+}
+
+// GetRole godoc
+// @Summary Detail role
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Nama role"
+// @Success 200 {object} models.Role
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/roles/{name} [get]
+func (s *RoleService) GetRole(c *fiber.Ctx) error {
+	role, err := s.Repo.Get(c.UserContext(), c.Params("name"))
+	if err != nil {
+		return roleError(c, err)
+	}
+	return c.JSON(role)
+}
+
+// CreateRole godoc
+// @Summary Buat role baru
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.RoleRequest true "Role"
+// @Success 201 {object} models.Role
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/roles [post]
+func (s *RoleService) CreateRole(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(req.Name) || req.Name == models.RoleLegacyUser {
+		return c.Status(400).JSON(fiber.Map{"error": "nama role harus huruf kecil/angka/underscore, 2-32 karakter"})
+	}
+	perms, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	role := &models.Role{Name: req.Name, Description: strings.TrimSpace(req.Description), Permissions: perms}
+	if err := s.Repo.Create(c.UserContext(), role); err != nil {
+		return roleError(c, err)
+	}
+	s.Permissions.Invalidate()
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityRole, role.Name, nil, role)
+	return c.Status(201).JSON(role)
+}
+
+// UpdateRole godoc
+// @Summary Ganti deskripsi & permission role
+// @Description Permission lama diganti seluruhnya. Role admin tidak bisa diubah.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "Nama role"
+// @Param request body models.RoleRequest true "Role (name diabaikan)"
+// @Success 200 {object} models.Role
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/roles/{name} [put]
+func (s *RoleService) UpdateRole(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == models.RoleAdmin {
+		// mencegah admin tidak sengaja mengunci dirinya sendiri
+		return c.Status(403).JSON(fiber.Map{"error": "role admin tidak bisa diubah"})
+	}
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	perms, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return roleError(c, err)
+	}
+
+	before, _ := s.Repo.Get(c.UserContext(), name)
+	role := &models.Role{Name: name, Description: strings.TrimSpace(req.Description), Permissions: perms}
+	if err := s.Repo.Update(c.UserContext(), role); err != nil {
+		return roleError(c, err)
+	}
+	s.Permissions.Invalidate()
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityRole, name, before, role)
+	return c.JSON(role)
+}
+
+// DeleteRole godoc
+// @Summary Hapus role
+// @Description Role bawaan (system) dan role yang masih dipakai user tidak bisa dihapus
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Nama role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/roles/{name} [delete]
+func (s *RoleService) DeleteRole(c *fiber.Ctx) error {
+	name := c.Params("name")
+	role, err := s.Repo.Get(c.UserContext(), name)
+	if err != nil {
+		return roleError(c, err)
+	}
+	if role.System {
+		return c.Status(409).JSON(fiber.Map{"error": "role bawaan tidak bisa dihapus"})
+	}
+	inUse, err := s.Repo.CountUsers(c.UserContext(), name)
+	if err != nil {
+		return roleError(c, err)
+	}
+	if inUse > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "role masih dipakai user", "users": inUse})
+	}
+	if _, err := s.Repo.Delete(c.UserContext(), name); err != nil {
+		return roleError(c, err)
+	}
+	s.Permissions.Invalidate()
+	s.Audit.Log(c, models.AuditDelete, models.AuditEntityRole, name, role, nil)
+	return c.JSON(fiber.Map{"message": "role dihapus"})
+}
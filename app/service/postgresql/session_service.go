@@ -0,0 +1,146 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+)
+
+// SessionService mengelola sesi login (satu sesi = satu family refresh token) untuk user Postgres maupun Mongo
+type SessionService struct {
+	Repo    repository.SessionRepositoryInterface
+	Revoker repository.RefreshTokenRepositoryInterface
+}
+
+func markCurrent(sessions []models.Session, currentID string) {
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+}
+
+// ListSessions godoc
+// @Summary Daftar sesi aktif milik sendiri
+// @Description Perangkat (user agent), IP, dan aktivitas terakhir. Sesi yang sedang dipakai ditandai current=true.
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Session
+// @Router /sessions [get]
+func (s *SessionService) ListSessions(c *fiber.Ctx) error {
+	claims := middleware.Claims(c)
+	sessions, err := s.Repo.ListActive(c.UserContext(), claims.Backend, claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	markCurrent(sessions, claims.FamilyID)
+	return c.JSON(sessions)
+}
+
+// RevokeSession godoc
+// @Summary Cabut satu sesi milik sendiri
+// @Description Access token dan refresh token sesi tersebut langsung tidak berlaku
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID sesi"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /sessions/{id} [delete]
+func (s *SessionService) RevokeSession(c *fiber.Ctx) error {
+	claims := middleware.Claims(c)
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "sesi tidak ditemukan"})
+	}
+	sess, err := s.Repo.Get(c.UserContext(), c.Params("id"))
+	if err != nil && err != sql.ErrNoRows {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	// sesi user lain diperlakukan sama dengan tidak ada
+	if err == sql.ErrNoRows || sess.Backend != claims.Backend || sess.UserID != claims.UserID {
+		return c.Status(404).JSON(fiber.Map{"error": "sesi tidak ditemukan"})
+	}
+	if err := s.Revoker.RevokeFamily(c.UserContext(), sess.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal mencabut sesi"})
+	}
+	return c.JSON(fiber.Map{"message": "sesi dicabut"})
+}
+
+// RevokeAllSessions godoc
+// @Summary Logout dari semua perangkat
+// @Description Mencabut semua sesi milik sendiri. keep_current=true mempertahankan sesi yang sedang dipakai.
+// @Tags Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param keep_current query bool false "Pertahankan sesi ini"
+// @Success 200 {object} map[string]interface{}
+// @Router /sessions [delete]
+func (s *SessionService) RevokeAllSessions(c *fiber.Ctx) error {
+	claims := middleware.Claims(c)
+	var err error
+	if c.QueryBool("keep_current") && claims.FamilyID != "" {
+		err = s.Revoker.RevokeOthersForUser(c.UserContext(), claims.Backend, claims.UserID, claims.FamilyID)
+	} else {
+		err = s.Revoker.RevokeAllForUser(c.UserContext(), claims.Backend, claims.UserID)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal mencabut sesi"})
+	}
+	return c.JSON(fiber.Map{"message": "semua sesi dicabut"})
+}
+
+// adminTarget membaca user tujuan dari path :id dan query ?backend= (default postgres)
+func adminTarget(c *fiber.Ctx) (string, string, bool) {
+	backend := c.Query("backend", models.BackendPostgres)
+	if backend != models.BackendPostgres && backend != models.BackendMongo {
+		return "", "", false
+	}
+	return backend, c.Params("id"), c.Params("id") != ""
+}
+
+// AdminListSessions godoc
+// @Summary Daftar sesi aktif seorang user
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID user (int Postgres atau ObjectID Mongo)"
+// @Param backend query string false "postgres (default) atau mongo"
+// @Success 200 {array} models.Session
+// @Failure 400 {object} models.ErrorResponse
+// @Router /admin/users/{id}/sessions [get]
+func (s *SessionService) AdminListSessions(c *fiber.Ctx) error {
+	backend, userID, ok := adminTarget(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "backend harus postgres atau mongo"})
+	}
+	sessions, err := s.Repo.ListActive(c.UserContext(), backend, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	return c.JSON(sessions)
+}
+
+// AdminRevokeSessions godoc
+// @Summary Cabut semua sesi seorang user
+// @Description Dipakai saat akun dicurigai bocor; user harus login ulang di semua perangkat
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID user (int Postgres atau ObjectID Mongo)"
+// @Param backend query string false "postgres (default) atau mongo"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /admin/users/{id}/sessions [delete]
+func (s *SessionService) AdminRevokeSessions(c *fiber.Ctx) error {
+	backend, userID, ok := adminTarget(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "backend harus postgres atau mongo"})
+	}
+	if err := s.Revoker.RevokeAllForUser(c.UserContext(), backend, userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal mencabut sesi"})
+	}
+	return c.JSON(fiber.Map{"message": "semua sesi user dicabut"})
+}
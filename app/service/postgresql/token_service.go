@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+// AccountStatus dicek setiap kali token diterbitkan (login, 2FA, refresh), dipenuhi repository.UserRepository
+type AccountStatus interface {
+	AccountState(ctx context.Context, backend, userID string) (models.AccountState, error)
+}
+
+type TokenService struct {
+	Repo     repository.RefreshTokenRepositoryInterface
+	Accounts AccountStatus
+}
+
+// IssueTokenPair membuat sesi (family) baru: access token pendek + refresh token yang disimpan (hash) di DB.
+// meta = perangkat yang login, tampil di daftar sesi.
+func (s *TokenService) IssueTokenPair(ctx context.Context, id models.Identity, meta models.SessionMeta) (*models.TokenPair, error) {
+	return s.issue(ctx, 0, id, uuid.NewString(), meta)
+}
+
+func (s *TokenService) issue(ctx context.Context, oldID int, id models.Identity, familyID string, meta models.SessionMeta) (*models.TokenPair, error) {
+	jwtCfg := config.LoadJWT()
+
+	// akun yang dinonaktifkan setelah login tidak bisa memperpanjang sesinya, dan role / status verifikasi
+	// selalu dibaca ulang: refresh token hanya menyimpan salinan saat login, yang bisa sudah basi
+	if s.Accounts != nil {
+		st, err := s.Accounts.AccountState(ctx, id.Backend, id.ID)
+		if err != nil {
+			return nil, err
+		}
+		if st.Disabled {
+			return nil, models.ErrAccountDisabled
+		}
+		if st.Known {
+			id.Role, id.EmailVerified = st.Role, st.EmailVerified
+		}
+	}
+
+	refresh, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	rt := &models.RefreshToken{
+		Backend:   id.Backend,
+		UserID:    id.ID,
+		Username:  id.Username,
+		Role:      id.Role,
+		Verified:  id.EmailVerified,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refresh),
+		ExpiresAt: time.Now().Add(jwtCfg.RefreshTTL),
+	}
+	if oldID == 0 {
+		err = s.Repo.StartSession(ctx, &models.Session{
+			ID:        familyID,
+			Backend:   id.Backend,
+			UserID:    id.ID,
+			Username:  id.Username,
+			UserAgent: meta.UserAgent,
+			IP:        meta.IP,
+			ExpiresAt: rt.ExpiresAt,
+		}, rt)
+	} else {
+		err = s.Repo.Rotate(ctx, oldID, rt, meta)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := utils.SignClaims(utils.NewClaims(id, familyID))
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenPair{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(jwtCfg.AccessTTL.Seconds()),
+	}, nil
+}
+
+// Refresh godoc
+// @Summary Tukar refresh token dengan pasangan token baru
+// @Description Refresh token lama langsung tidak berlaku. Memakai ulang token lama akan mencabut seluruh sesi (family).
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.TokenPair
+// @Failure 401 {object} models.ErrorResponse
+// @Router /token/refresh [post]
+func (s *TokenService) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "refresh_token wajib"})
+	}
+
+	old, err := s.Repo.FindByHash(c.UserContext(), utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.Status(401).JSON(fiber.Map{"error": "refresh token tidak valid"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+
+	// token yang sudah dipakai / dicabut datang lagi → kemungkinan dicuri, matikan seluruh family
+	if old.UsedAt != nil || old.RevokedAt != nil {
+		_ = s.Repo.RevokeFamily(c.UserContext(), old.FamilyID)
+		return c.Status(401).JSON(fiber.Map{"error": "refresh token sudah dipakai, sesi dicabut"})
+	}
+	if time.Now().After(old.ExpiresAt) {
+		return c.Status(401).JSON(fiber.Map{"error": "refresh token expired"})
+	}
+
+	id := models.Identity{Backend: old.Backend, ID: old.UserID, Username: old.Username, Role: old.Role, EmailVerified: old.Verified}
+	pair, err := s.issue(c.UserContext(), old.ID, id, old.FamilyID, middleware.SessionMeta(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			_ = s.Repo.RevokeFamily(c.UserContext(), old.FamilyID)
+			return c.Status(401).JSON(fiber.Map{"error": "refresh token sudah dipakai, sesi dicabut"})
+		}
+		if errors.Is(err, models.ErrAccountDisabled) {
+			_ = s.Repo.RevokeFamily(c.UserContext(), old.FamilyID)
+			return c.Status(403).JSON(fiber.Map{"error": "akun dinonaktifkan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
+	}
+	return c.JSON(pair)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Mencabut sesi (family refresh token) milik access token yang sedang dipakai
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest false "Refresh token (opsional)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} models.ErrorResponse
+// @Router /logout [post]
+func (s *TokenService) Logout(c *fiber.Ctx) error {
+	familyID, _ := c.Locals("family_id").(string)
+
+	// access token lama (tanpa family) tetap bisa logout lewat refresh token di body
+	var req models.RefreshRequest
+	_ = c.BodyParser(&req)
+	if req.RefreshToken != "" {
+		if t, err := s.Repo.FindByHash(c.UserContext(), utils.HashToken(strings.TrimSpace(req.RefreshToken))); err == nil {
+			if err := s.Repo.RevokeFamily(c.UserContext(), t.FamilyID); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "gagal logout"})
+			}
+		}
+	}
+
+	if familyID != "" {
+		if err := s.Repo.RevokeFamily(c.UserContext(), familyID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal logout"})
+		}
+	}
+	return c.JSON(fiber.Map{"message": "logout sukses"})
+}
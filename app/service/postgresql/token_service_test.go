@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+// newTokenApp memasang /token/refresh, /logout dan /me (keduanya lewat Auth.Required) di atas repository mock;
+// user 1 = admin, user 2 = budi
+func newTokenApp(t *testing.T) (*fiber.App, *TokenService, *repository.MockUserRepository) {
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	users := repository.NewMockUserRepository()
+	users.InsertUser(&models.User{ID: 1, Username: "root", Role: models.RoleAdmin, EmailVerified: true})
+	users.InsertUser(&models.User{ID: 2, Username: "budi", Role: models.RoleAdmin, EmailVerified: true})
+	sessions := repository.NewMockRefreshTokenRepository()
+	tokens := &TokenService{Repo: sessions, Accounts: users}
+	auth := &middleware.Auth{Sessions: sessions}
+
+	app := fiber.New()
+	app.Post("/token/refresh", tokens.Refresh)
+	app.Post("/logout", auth.AllowUnverified(), tokens.Logout)
+	app.Get("/me", auth.Required(), func(c *fiber.Ctx) error { return c.SendString(middleware.Claims(c).Username) })
+	return app, tokens, users
+}
+
+func login(t *testing.T, tokens *TokenService, u *models.User) *models.TokenPair {
+	t.Helper()
+	pair, err := tokens.IssueTokenPair(context.Background(), models.PostgresIdentity(*u), models.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+func refresh(t *testing.T, app *fiber.App, refreshToken string, out interface{}) int {
+	return call(t, app, "POST", "/token/refresh", `{"refresh_token":"`+refreshToken+`"}`, out)
+}
+
+func TestRefreshRotatesPair(t *testing.T) {
+	app, tokens, users := newTokenApp(t)
+	pair := login(t, tokens, users.Data[2])
+
+	var next models.TokenPair
+	if code := refresh(t, app, pair.RefreshToken, &next); code != 200 {
+		t.Fatalf("refresh: status %d", code)
+	}
+	if next.RefreshToken == "" || next.RefreshToken == pair.RefreshToken || next.Token == pair.Token {
+		t.Fatalf("pasangan token tidak diganti: %+v", next)
+	}
+	old, _ := utils.ValidateToken(pair.Token)
+	claims, err := utils.ValidateToken(next.Token)
+	if err != nil || claims.FamilyID != old.FamilyID || claims.Username != "budi" {
+		t.Fatalf("access token baru = %+v, %v", claims, err)
+	}
+	if code := callBearer(t, app, "GET", "/me", next.Token); code != 200 {
+		t.Fatalf("/me dengan token baru: status %d", code)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	app, tokens, users := newTokenApp(t)
+	pair := login(t, tokens, users.Data[2])
+
+	var next models.TokenPair
+	if code := refresh(t, app, pair.RefreshToken, &next); code != 200 {
+		t.Fatalf("refresh: status %d", code)
+	}
+	// token lama dipakai lagi (dicuri) → seluruh family dicabut, termasuk pasangan yang baru
+	if code := refresh(t, app, pair.RefreshToken, nil); code != 401 {
+		t.Fatalf("reuse: status %d, want 401", code)
+	}
+	if code := refresh(t, app, next.RefreshToken, nil); code != 401 {
+		t.Fatalf("refresh token baru setelah reuse: status %d, want 401", code)
+	}
+	if code := callBearer(t, app, "GET", "/me", next.Token); code != 401 {
+		t.Fatalf("access token family yang dicabut: status %d, want 401", code)
+	}
+}
+
+func TestLogoutRejectsAccessToken(t *testing.T) {
+	app, tokens, users := newTokenApp(t)
+	pair := login(t, tokens, users.Data[2])
+	other := login(t, tokens, users.Data[2])
+
+	if code := callBearer(t, app, "POST", "/logout", pair.Token); code != 200 {
+		t.Fatalf("logout: status %d", code)
+	}
+	if code := callBearer(t, app, "GET", "/me", pair.Token); code != 401 {
+		t.Fatalf("access token setelah logout: status %d, want 401", code)
+	}
+	if code := refresh(t, app, pair.RefreshToken, nil); code != 401 {
+		t.Fatalf("refresh token setelah logout: status %d, want 401", code)
+	}
+	// sesi lain milik user yang sama tidak ikut keluar
+	if code := callBearer(t, app, "GET", "/me", other.Token); code != 200 {
+		t.Fatalf("sesi lain: status %d", code)
+	}
+}
+
+func TestLogoutAllowsUnverifiedEmail(t *testing.T) {
+	app, tokens, users := newTokenApp(t)
+	users.InsertUser(&models.User{ID: 3, Username: "baru", Role: models.RoleViewer})
+	pair := login(t, tokens, users.Data[3])
+
+	if code := callBearer(t, app, "GET", "/me", pair.Token); code != 403 {
+		t.Fatalf("/me sebelum verifikasi: status %d, want 403", code)
+	}
+	if code := callBearer(t, app, "POST", "/logout", pair.Token); code != 200 {
+		t.Fatalf("logout sebelum verifikasi: status %d, want 200", code)
+	}
+	if code := refresh(t, app, pair.RefreshToken, nil); code != 401 {
+		t.Fatalf("refresh token setelah logout: status %d, want 401", code)
+	}
+}
+
+func TestRefreshPicksUpNewRole(t *testing.T) {
+	app, tokens, users := newTokenApp(t)
+	userSvc := &UserService{Repo: users}
+	app.Put("/admin/users/:id/role", func(c *fiber.Ctx) error {
+		c.Locals("claims", &models.JWTClaims{UserID: "1", Backend: models.BackendPostgres})
+		return c.Next()
+	}, userSvc.UpdateUserRole)
+	pair := login(t, tokens, users.Data[2])
+
+	if code := call(t, app, "PUT", "/admin/users/2/role", `{"role":"viewer"}`, nil); code != 200 {
+		t.Fatalf("demote: status %d", code)
+	}
+
+	var next models.TokenPair
+	if code := refresh(t, app, pair.RefreshToken, &next); code != 200 {
+		t.Fatalf("refresh: status %d", code)
+	}
+	claims, err := utils.ValidateToken(next.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Role != models.RoleViewer {
+		t.Fatalf("role di access token baru = %q, want %q", claims.Role, models.RoleViewer)
+	}
+}
@@ -1,46 +1,59 @@
 package service
 
 import (
-    "strings"
+	"context"
+	"database/sql"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
 	"go_clean/app/models/postgresql"
 	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
 	"go_clean/utils"
+	"log"
 	"net/mail"
 	"strconv"
 )
 
+// PasswordResetSender dipenuhi PasswordService (app/service/identity), mengirim link reset ke email akun
+type PasswordResetSender interface {
+	SendResetLink(ctx context.Context, id models.Identity) error
+}
+
+// UserService: manajemen akun Postgres oleh admin (/api/admin/users)
 type UserService struct {
-	Repo *repository.UserRepository
+	Repo     repository.UserRepositoryInterface
+	Auth     repository.AuthRepositoryInterface
+	Sessions repository.RefreshTokenRepositoryInterface
+	Resets   PasswordResetSender
+	Audit    *middleware.Auditor
 }
 
+// GetUsersService godoc
+// @Summary Daftar user (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman (maks 100)" default(10)
+// @Param sortBy query string false "id, username, email, role, created_at" default(id)
+// @Param order query string false "asc / desc" default(asc)
+// @Param search query string false "Cari username / email"
+// @Success 200 {object} models.UserResponse[models.User]
+// @Router /admin/users [get]
 func (s *UserService) GetUsersService(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	sortBy := c.Query("sortBy", "id")
-	order := c.Query("order", "asc")
-	search := c.Query("search", "")
-	offset := (page - 1) * limit
-
-	// Validasi input
-	sortByWhitelist := map[string]bool{"id": true, "name": true,
-		"email": true, "created_at": true}
-	if !sortByWhitelist[sortBy] {
-		sortBy = "id"
-	}
-	if strings.ToLower(order) != "desc" {
-		order = "asc"
-	}
+	params := getListParams(c, map[string]bool{"id": true, "username": true,
+		"email": true, "role": true, "created_at": true})
 
 	// Ambil data dari repository
-	users, err := s.Repo.GetUsersRepo(search, sortBy, order,
-		limit, offset)
+	users, err := s.Repo.GetUsersRepo(c.UserContext(), params.Search, params.SortBy, params.Order,
+		params.Limit, params.Offset)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch users"})
 	}
 
-	total, err := s.Repo.CountUsersRepo(search)
+	total, err := s.Repo.CountUsersRepo(c.UserContext(), params.Search)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to count users"})
 	}
@@ -49,19 +62,291 @@ func (s *UserService) GetUsersService(c *fiber.Ctx) error {
 	response := models.UserResponse[models.User]{
 		Data: users,
 		Meta: models.MetaInfo{
-			Page:   page,
-			Limit:  limit,
+			Page:   params.Page,
+			Limit:  params.Limit,
 			Total:  total,
-			Pages:  (total + limit - 1) / limit,
-			SortBy: sortBy,
-			Order:  order,
-			Search: search,
+			Pages:  (total + params.Limit - 1) / params.Limit,
+			SortBy: params.SortBy,
+			Order:  params.Order,
+			Search: params.Search,
 		},
 	}
 
 	return c.JSON(response)
 }
 
+func (s *UserService) userFromParam(c *fiber.Ctx) (*models.User, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID user tidak valid")
+	}
+	u, err := s.Repo.GetUserByID(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user tidak ditemukan")
+	}
+	return u, err
+}
+
+// isSelf: admin tidak boleh menonaktifkan, menurunkan role, atau menghapus akunnya sendiri
+func isSelf(c *fiber.Ctx, u *models.User) bool {
+	id, err := middleware.Claims(c).PostgresUserID()
+	return err == nil && id == u.ID
+}
+
+// revokeSessions mencabut semua sesi; kegagalan cukup dicatat karena token juga ditolak saat refresh
+func (s *UserService) revokeSessions(ctx context.Context, u *models.User) {
+	if s.Sessions == nil {
+		return
+	}
+	if err := s.Sessions.RevokeAllForUser(ctx, models.BackendPostgres, strconv.Itoa(u.ID)); err != nil {
+		log.Printf("gagal cabut sesi user %d: %v", u.ID, err)
+	}
+}
+
+// GetUserDetail godoc
+// @Summary Detail user (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} models.User
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id} [get]
+func (s *UserService) GetUserDetail(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(u)
+}
+
+// UpdateUserRole godoc
+// @Summary Ganti role user (Admin Only)
+// @Description Berlaku di token berikutnya: refresh token membaca ulang role dari database. Access token yang sudah terbit tetap memakai role lama sampai expired.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID User"
+// @Param request body models.UpdateUserRoleRequest true "Role baru"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (s *UserService) UpdateUserRole(c *fiber.Ctx) error {
+	var req models.UpdateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if req.Role == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "role wajib"})
+	}
+
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if isSelf(c, u) {
+		return c.Status(409).JSON(fiber.Map{"error": "tidak bisa mengganti role akun sendiri"})
+	}
+	if _, err := s.Repo.UpdateRole(c.UserContext(), u.ID, req.Role); err != nil {
+		if err == repository.ErrInvalidRole {
+			return c.Status(400).JSON(fiber.Map{"error": "role tidak dikenal, lihat /api/admin/roles"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update role"})
+	}
+
+	after, err := s.Repo.GetUserByID(c.UserContext(), u.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "db error"})
+	}
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	return c.JSON(after)
+}
+
+// DisableUser godoc
+// @Summary Nonaktifkan user (Admin Only)
+// @Description Semua sesi dicabut; login dan refresh token ditolak sampai diaktifkan lagi
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/disable [post]
+func (s *UserService) DisableUser(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if isSelf(c, u) {
+		return c.Status(409).JSON(fiber.Map{"error": "tidak bisa menonaktifkan akun sendiri"})
+	}
+	if !u.Disabled {
+		if _, err := s.Repo.SetDisabled(c.UserContext(), u.ID, true); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal menonaktifkan user"})
+		}
+		after := *u
+		after.Disabled = true
+		s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	}
+	s.revokeSessions(c.UserContext(), u)
+	return c.JSON(fiber.Map{"message": "user dinonaktifkan"})
+}
+
+// EnableUser godoc
+// @Summary Aktifkan kembali user (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/enable [post]
+func (s *UserService) EnableUser(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if u.Disabled {
+		if _, err := s.Repo.SetDisabled(c.UserContext(), u.ID, false); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal mengaktifkan user"})
+		}
+		after := *u
+		after.Disabled = false
+		s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	}
+	return c.JSON(fiber.Map{"message": "user aktif"})
+}
+
+// LinkUserAlumni godoc
+// @Summary Tautkan user ke data alumni (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID User"
+// @Param request body models.LinkAlumniRequest true "ID alumni"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/alumni [put]
+func (s *UserService) LinkUserAlumni(c *fiber.Ctx) error {
+	var req models.LinkAlumniRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	if req.AlumniID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "alumni_id wajib"})
+	}
+	return s.setAlumni(c, &req.AlumniID)
+}
+
+// UnlinkUserAlumni godoc
+// @Summary Lepas tautan user dari data alumni (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} models.User
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/alumni [delete]
+func (s *UserService) UnlinkUserAlumni(c *fiber.Ctx) error {
+	return s.setAlumni(c, nil)
+}
+
+func (s *UserService) setAlumni(c *fiber.Ctx, alumniID *int) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Repo.SetAlumniID(c.UserContext(), u.ID, alumniID); err != nil {
+		if err == repository.ErrAlumniNotFound {
+			return c.Status(400).JSON(fiber.Map{"error": "alumni tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update alumni_id"})
+	}
+	after := *u
+	after.AlumniID = alumniID
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	return c.JSON(after)
+}
+
+// AdminResetPassword godoc
+// @Summary Paksa reset password user (Admin Only)
+// @Description Password lama langsung tidak berlaku, semua sesi dicabut, dan link reset dikirim ke email user
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/reset-password [post]
+func (s *UserService) AdminResetPassword(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	// ganti dengan password acak yang tidak diketahui siapa pun sampai user memakai link reset
+	random, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat password sementara"})
+	}
+	hash, err := utils.HashPassword(random)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
+	}
+	if err := s.Auth.UpdatePassword(c.UserContext(), strconv.Itoa(u.ID), hash); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal update password"})
+	}
+	s.revokeSessions(c.UserContext(), u)
+	s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), nil, fiber.Map{"password_reset_forced": true})
+
+	resp := fiber.Map{"message": "password direset, link reset dikirim ke email user"}
+	if s.Resets == nil {
+		resp["message"] = "password direset, user perlu memakai lupa password"
+		return c.JSON(resp)
+	}
+	if err := s.Resets.SendResetLink(c.UserContext(), models.PostgresIdentity(*u)); err != nil {
+		log.Printf("gagal kirim link reset ke user %d: %v", u.ID, err)
+		resp["message"] = "password direset tapi email gagal dikirim, user perlu memakai lupa password"
+	}
+	return c.JSON(resp)
+}
+
+// DeleteUser godoc
+// @Summary Hapus user (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id} [delete]
+func (s *UserService) DeleteUser(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if isSelf(c, u) {
+		return c.Status(409).JSON(fiber.Map{"error": "tidak bisa menghapus akun sendiri"})
+	}
+	if _, err := s.Repo.DeleteUser(c.UserContext(), u.ID); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return c.Status(409).JSON(fiber.Map{"error": "user masih dipakai data lain, nonaktifkan saja"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "gagal menghapus user"})
+	}
+	s.revokeSessions(c.UserContext(), u)
+	s.Audit.Log(c, models.AuditDelete, models.AuditEntityUser, strconv.Itoa(u.ID), u, nil)
+	return c.JSON(fiber.Map{"message": "user dihapus"})
+}
+
 // helper validasi ringan
 func isEmail(s string) bool {
 	_, err := mail.ParseAddress(s)
@@ -82,7 +367,7 @@ func isEmail(s string) bool {
 // @Failure 500 {object} models.ErrorResponse
 // @Router /register-admin [post]
 func (s *AuthService) AdminCreateUser(c *fiber.Ctx) error {
-	// pastikan middleware AdminOnly sudah pasang di router
+	// pastikan middleware.Require(users:manage) sudah pasang di router
 	var req models.AdminCreateUserRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
@@ -98,11 +383,11 @@ func (s *AuthService) AdminCreateUser(c *fiber.Ctx) error {
 	if !isEmail(req.Email) {
 		return c.Status(400).JSON(fiber.Map{"error": "format email tidak valid"})
 	}
-	if req.Role != "admin" && req.Role != "user" {
-		return c.Status(400).JSON(fiber.Map{"error": "role harus 'admin' atau 'user'"})
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	exists, err := s.Repo.ExistsByUsernameOrEmail(req.Username, req.Email)
+	exists, err := s.Repo.ExistsByUsernameOrEmail(c.UserContext(), req.Username, req.Email)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "db error"})
 	}
@@ -115,10 +400,14 @@ func (s *AuthService) AdminCreateUser(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
 	}
 
-	u, err := s.Repo.Create(req.Username, req.Email, hash, req.Role)
+	u, err := s.Repo.Create(c.UserContext(), req.Username, req.Email, hash, req.Role, true)
+	if err == repository.ErrInvalidRole {
+		return c.Status(400).JSON(fiber.Map{"error": "role tidak dikenal, lihat /api/admin/roles"})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat user"})
 	}
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityUser, strconv.Itoa(u.ID), nil, u)
 	return c.Status(201).JSON(fiber.Map{
 		"message": "user dibuat",
 		"user":    u,
@@ -127,7 +416,7 @@ func (s *AuthService) AdminCreateUser(c *fiber.Ctx) error {
 
 // Register User godoc
 // @Summary Register user baru
-// @Description Pendaftaran akun user baru (role otomatis = user)
+// @Description Pendaftaran akun user baru (role otomatis = alumni)
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -137,7 +426,7 @@ func (s *AuthService) AdminCreateUser(c *fiber.Ctx) error {
 // @Failure 409 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /register-postgre [post]
-// PUBLIC: register user (role = "user" fixed)
+// PUBLIC: register user (role = "alumni" fixed)
 func (s *AuthService) RegisterUser(c *fiber.Ctx) error {
 	var req models.RegisterRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -153,7 +442,10 @@ func (s *AuthService) RegisterUser(c *fiber.Ctx) error {
 	if !isEmail(req.Email) {
 		return c.Status(400).JSON(fiber.Map{"error": "format email tidak valid"})
 	}
-	exists, err := s.Repo.ExistsByUsernameOrEmail(req.Username, req.Email)
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	exists, err := s.Repo.ExistsByUsernameOrEmail(c.UserContext(), req.Username, req.Email)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "db error"})
 	}
@@ -166,21 +458,31 @@ func (s *AuthService) RegisterUser(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "gagal hash password"})
 	}
 
-	u, err := s.Repo.Create(req.Username, req.Email, hash, "user")
+	u, err := s.Repo.Create(c.UserContext(), req.Username, req.Email, hash, models.RoleAlumni, false)
 	if err != nil {
 		// cek duplikat juga bisa terjadi dari constraint
 		return c.Status(500).JSON(fiber.Map{"error": "gagal membuat user"})
 	}
+	// register publik: actor kosong, user baru terlihat dari after
+	s.Audit.Log(c, models.AuditCreate, models.AuditEntityUser, strconv.Itoa(u.ID), nil, u)
 
-	// opsional: langsung login (return token)
-	token, err := utils.GenerateToken(*u)
+	// akun baru berstatus unverified sampai link di email diklik
+	if s.Verification != nil {
+		if err := s.Verification.SendVerification(*u); err != nil {
+			log.Printf("gagal kirim email verifikasi ke %s: %v", u.Email, err)
+		}
+	}
+
+	// opsional: langsung login (return token). Token unverified ditolak route protected sampai verifikasi.
+	token, refresh, err := s.issueLoginTokens(c, *u)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "gagal generate token"})
 	}
 	return c.Status(201).JSON(fiber.Map{
-		"message": "register sukses",
-		"user":    u,
-		"token":   token,
+		"message":       "register sukses, cek email untuk verifikasi akun",
+		"user":          u,
+		"token":         token,
+		"refresh_token": refresh,
 	})
 }
 
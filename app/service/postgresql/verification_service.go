@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+const emailVerifyPurpose = "email-verify"
+
+type VerificationService struct {
+	Auth      repository.AuthRepositoryInterface
+	Users     repository.UserRepositoryInterface
+	Sessions  repository.RefreshTokenRepositoryInterface
+	Mailer    utils.Mailer
+	PublicURL string
+	Secret    []byte
+	TTL       time.Duration
+	Audit     *middleware.Auditor
+}
+
+// SendVerification mengirim link verifikasi bertanda tangan. Email ikut ditandatangani,
+// jadi link lama otomatis tidak berlaku kalau email user berubah.
+func (s *VerificationService) SendVerification(u models.User) error {
+	token := utils.SignLink(s.Secret, emailVerifyPurpose, time.Now().Add(s.TTL), strconv.Itoa(u.ID), strings.ToLower(u.Email))
+	link := fmt.Sprintf("%s/api/verify-email?token=%s", s.PublicURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk verifikasi email akun Alumni API (berlaku %d jam):\n%s\n",
+		u.Username, int(s.TTL.Hours()), link)
+	return s.Mailer.Send(u.Email, "Verifikasi email", body)
+}
+
+// VerifyEmail godoc
+// @Summary Verifikasi email dari link
+// @Description Mengaktifkan akun self-register. Setelah verifikasi user perlu login ulang.
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Token dari email"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /verify-email [get]
+func (s *VerificationService) VerifyEmail(c *fiber.Ctx) error {
+	fields, err := utils.VerifyLink(s.Secret, emailVerifyPurpose, c.Query("token"), time.Now())
+	if err != nil || len(fields) != 2 {
+		msg := "link verifikasi tidak valid"
+		if err == utils.ErrLinkExpired {
+			msg = "link verifikasi kedaluwarsa, minta kirim ulang"
+		}
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "link verifikasi tidak valid"})
+	}
+	u, err := s.Users.GetUserByID(c.UserContext(), id)
+	if err != nil || !strings.EqualFold(u.Email, fields[1]) {
+		return c.Status(400).JSON(fiber.Map{"error": "link verifikasi tidak valid"})
+	}
+	if u.EmailVerified {
+		return c.JSON(fiber.Map{"message": "email sudah terverifikasi"})
+	}
+
+	if err := s.markVerified(c.UserContext(), u.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal verifikasi email"})
+	}
+	return c.JSON(fiber.Map{"message": "email terverifikasi, silakan login ulang"})
+}
+
+// markVerified juga mencabut sesi lama supaya token berklaim unverified tidak dipakai lagi
+func (s *VerificationService) markVerified(ctx context.Context, id int) error {
+	if _, err := s.Users.MarkEmailVerified(ctx, id); err != nil {
+		return err
+	}
+	if err := s.Sessions.RevokeAllForUser(ctx, models.BackendPostgres, strconv.Itoa(id)); err != nil {
+		log.Printf("gagal cabut sesi user %d: %v", id, err)
+	}
+	return nil
+}
+
+// ResendVerification godoc
+// @Summary Kirim ulang email verifikasi
+// @Description Respons selalu sama supaya email terdaftar tidak bisa ditebak
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} map[string]interface{}
+// @Router /verify-email/resend [post]
+func (s *VerificationService) ResendVerification(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "payload tidak valid"})
+	}
+	genericResp := fiber.Map{"message": "jika akun belum terverifikasi, email verifikasi sudah dikirim ulang"}
+
+	u, _, err := s.Auth.GetByUsernameOrEmail(c.UserContext(), strings.TrimSpace(req.Email))
+	if err != nil || u.EmailVerified {
+		return c.JSON(genericResp)
+	}
+	if err := s.SendVerification(*u); err != nil {
+		log.Printf("gagal kirim email verifikasi ke %s: %v", u.Email, err)
+	}
+	return c.JSON(genericResp)
+}
+
+func (s *VerificationService) userFromParam(c *fiber.Ctx) (*models.User, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID user tidak valid")
+	}
+	u, err := s.Users.GetUserByID(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user tidak ditemukan")
+	}
+	return u, err
+}
+
+// AdminResendVerification godoc
+// @Summary Kirim ulang email verifikasi (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/resend-verification [post]
+func (s *VerificationService) AdminResendVerification(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified {
+		return c.Status(409).JSON(fiber.Map{"error": "email user sudah terverifikasi"})
+	}
+	if err := s.SendVerification(*u); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "gagal kirim email verifikasi"})
+	}
+	return c.JSON(fiber.Map{"message": "email verifikasi dikirim ulang"})
+}
+
+// AdminVerifyUser godoc
+// @Summary Verifikasi email user secara manual (Admin Only)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID User"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/verify [post]
+func (s *VerificationService) AdminVerifyUser(c *fiber.Ctx) error {
+	u, err := s.userFromParam(c)
+	if err != nil {
+		return err
+	}
+	if !u.EmailVerified {
+		if err := s.markVerified(c.UserContext(), u.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "gagal verifikasi user"})
+		}
+		after := *u
+		after.EmailVerified = true
+		s.Audit.Log(c, models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	}
+	return c.JSON(fiber.Map{"message": "user terverifikasi"})
+}
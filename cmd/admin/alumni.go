@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	mongoModel "go_clean/app/models/mongodb"
+	"go_clean/app/models/postgresql"
+	repoMongo "go_clean/app/repository/mongodb"
+	"go_clean/app/repository/postgresql"
+)
+
+// alumniRecord = satu baris file import / export, sama untuk Postgres maupun Mongo
+type alumniRecord struct {
+	NIM        string `json:"nim"`
+	Nama       string `json:"nama"`
+	Jurusan    string `json:"jurusan"`
+	Angkatan   int    `json:"angkatan"`
+	TahunLulus int    `json:"tahun_lulus"`
+	Email      string `json:"email"`
+	NoTelepon  string `json:"no_telepon,omitempty"`
+	Alamat     string `json:"alamat,omitempty"`
+}
+
+var alumniCSVHeader = []string{"nim", "nama", "jurusan", "angkatan", "tahun_lulus", "email", "no_telepon", "alamat"}
+
+func (r alumniRecord) csvRow() []string {
+	return []string{r.NIM, r.Nama, r.Jurusan, strconv.Itoa(r.Angkatan), strconv.Itoa(r.TahunLulus), r.Email, r.NoTelepon, r.Alamat}
+}
+
+func (r alumniRecord) validate() error {
+	if strings.TrimSpace(r.NIM) == "" || strings.TrimSpace(r.Nama) == "" || strings.TrimSpace(r.Email) == "" {
+		return errors.New("nim, nama, email wajib")
+	}
+	return nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatOf: -format menang, selain itu dari ekstensi file
+func formatOf(format, path string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if format != "csv" && format != "json" {
+		return "", fmt.Errorf("format harus csv atau json, bukan %q", format)
+	}
+	return format, nil
+}
+
+func readAlumni(r io.Reader, format string) ([]alumniRecord, error) {
+	if format == "json" {
+		var list []alumniRecord
+		err := json.NewDecoder(r).Decode(&list)
+		return list, err
+	}
+
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	col := map[string]int{}
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range []string{"nim", "nama", "email"} {
+		if _, ok := col[h]; !ok {
+			return nil, fmt.Errorf("kolom %q tidak ada di header CSV", h)
+		}
+	}
+	get := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	list := make([]alumniRecord, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		rec := alumniRecord{
+			NIM:       get(row, "nim"),
+			Nama:      get(row, "nama"),
+			Jurusan:   get(row, "jurusan"),
+			Email:     get(row, "email"),
+			NoTelepon: get(row, "no_telepon"),
+			Alamat:    get(row, "alamat"),
+		}
+		for name, dst := range map[string]*int{"angkatan": &rec.Angkatan, "tahun_lulus": &rec.TahunLulus} {
+			if v := get(row, name); v != "" {
+				if *dst, err = strconv.Atoi(v); err != nil {
+					return nil, fmt.Errorf("baris %d: %s bukan angka", n+2, name)
+				}
+			}
+		}
+		list = append(list, rec)
+	}
+	return list, nil
+}
+
+func writeAlumni(w io.Writer, format string, list []alumniRecord) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(alumniCSVHeader)
+	for _, r := range list {
+		cw.Write(r.csvRow())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func runAlumniExport(a *cli, args []string) error {
+	fs := flag.NewFlagSet("alumni-export", flag.ExitOnError)
+	source := fs.String("source", models.BackendPostgres, "sumber data: postgres atau mongo")
+	format := fs.String("format", "", "csv atau json (default dari ekstensi -out)")
+	out := fs.String("out", "-", "file tujuan, - untuk stdout")
+	fs.Parse(args)
+
+	f, err := formatOf(*format, *out)
+	if err != nil {
+		return err
+	}
+
+	var list []alumniRecord
+	switch *source {
+	case models.BackendPostgres:
+		rows, err := (&repository.AlumniRepository{DB: a.postgres()}).GetAllAlumni(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			list = append(list, alumniRecord{r.NIM, r.Nama, r.Jurusan, r.Angkatan, r.TahunLulus, r.Email, deref(r.NoTelepon), deref(r.Alamat)})
+		}
+	case models.BackendMongo:
+		docs, err := repoMongo.NewAlumniMongoRepository(a.mongoDB()).FindAll(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, d := range docs {
+			list = append(list, alumniRecord{d.NIM, d.Nama, d.Jurusan, d.Angkatan, d.TahunLulus, d.Email, d.NoTelp, d.Alamat})
+		}
+	default:
+		return fmt.Errorf("source harus postgres atau mongo, bukan %q", *source)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := writeAlumni(w, f, list); err != nil {
+		return err
+	}
+	if *out != "-" {
+		fmt.Printf("%d alumni diexport ke %s\n", len(list), *out)
+	}
+	return nil
+}
+
+// runAlumniImport melewati NIM yang sudah ada; baris yang gagal dilaporkan tanpa menghentikan import
+func runAlumniImport(a *cli, args []string) error {
+	fs := flag.NewFlagSet("alumni-import", flag.ExitOnError)
+	target := fs.String("target", models.BackendPostgres, "tujuan: postgres atau mongo")
+	file := fs.String("file", "", "file CSV / JSON")
+	format := fs.String("format", "", "csv atau json (default dari ekstensi file)")
+	dryRun := fs.Bool("dry-run", false, "hanya validasi, tidak menyimpan")
+	fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("-file wajib")
+	}
+	f, err := formatOf(*format, *file)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	list, err := readAlumni(in, f)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	existing := map[string]bool{}
+	var create func(r alumniRecord) (string, interface{}, error)
+	entity := models.AuditEntityAlumni
+	switch *target {
+	case models.BackendPostgres:
+		repo := &repository.AlumniRepository{DB: a.postgres()}
+		rows, err := repo.GetAllAlumni(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			existing[r.NIM] = true
+		}
+		create = func(r alumniRecord) (string, interface{}, error) {
+			now := time.Now()
+			al := &models.Alumni{NIM: r.NIM, Nama: r.Nama, Jurusan: r.Jurusan, Angkatan: r.Angkatan, TahunLulus: r.TahunLulus,
+				Email: r.Email, NoTelepon: optional(r.NoTelepon), Alamat: optional(r.Alamat), CreatedAt: now, UpdatedAt: now}
+			id, err := repo.CreateAlumni(ctx, al)
+			al.ID = id
+			return strconv.Itoa(id), al, err
+		}
+	case models.BackendMongo:
+		repo := repoMongo.NewAlumniMongoRepository(a.mongoDB())
+		docs, err := repo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range docs {
+			existing[d.NIM] = true
+		}
+		entity = models.AuditEntityAlumniMongo
+		create = func(r alumniRecord) (string, interface{}, error) {
+			now := time.Now()
+			doc, err := repo.Create(ctx, &mongoModel.AlumniMongo{NIM: r.NIM, Nama: r.Nama, Jurusan: r.Jurusan, Angkatan: r.Angkatan,
+				TahunLulus: r.TahunLulus, Email: r.Email, NoTelp: r.NoTelepon, Alamat: r.Alamat, CreatedAt: now, UpdatedAt: now})
+			if err != nil {
+				return "", nil, err
+			}
+			return doc.ID.Hex(), doc, nil
+		}
+	default:
+		return fmt.Errorf("target harus postgres atau mongo, bukan %q", *target)
+	}
+
+	var created, skipped, failed int
+	for i, r := range list {
+		if err := r.validate(); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "data #%d: %v\n", i+1, err)
+			continue
+		}
+		if existing[r.NIM] {
+			skipped++
+			continue
+		}
+		existing[r.NIM] = true
+		if *dryRun {
+			created++
+			continue
+		}
+		id, after, err := create(r)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "data #%d (nim %s): %v\n", i+1, r.NIM, err)
+			continue
+		}
+		a.audit(models.AuditCreate, entity, id, nil, after)
+		created++
+	}
+
+	verb := "diimport"
+	if *dryRun {
+		verb = "akan diimport (dry-run)"
+	}
+	fmt.Printf("%d alumni %s, %d dilewati (NIM sudah ada), %d gagal\n", created, verb, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d data gagal diimport", failed)
+	}
+	return nil
+}
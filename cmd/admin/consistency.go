@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go_clean/app/models/postgresql"
+	repoMongo "go_clean/app/repository/mongodb"
+	"go_clean/app/repository/postgresql"
+	servicePostgre "go_clean/app/service/postgresql"
+)
+
+// runConsistencyCheck membandingkan alumni & pekerjaan di Postgres dan MongoDB:
+//
+//	admin consistency-check -source postgres -format csv -out diff.csv
+//	admin consistency-check -source postgres -repair            (dry-run, hanya rencana)
+//	admin consistency-check -source postgres -repair -dry-run=false
+//
+// Exit 1 kalau masih ada perbedaan yang belum diperbaiki, cocok untuk cron / CI.
+func runConsistencyCheck(a *cli, args []string) error {
+	fs := flag.NewFlagSet("consistency-check", flag.ExitOnError)
+	source := fs.String("source", models.BackendPostgres, "penyimpan acuan: postgres atau mongo")
+	format := fs.String("format", "", "csv atau json (default dari ekstensi -out, json untuk stdout)")
+	out := fs.String("out", "-", "file laporan, - untuk stdout")
+	repair := fs.Bool("repair", false, "perbaiki penyimpan tujuan dari penyimpan acuan")
+	dryRun := fs.Bool("dry-run", true, "dengan -repair: hanya tampilkan rencana perbaikan")
+	fs.Parse(args)
+
+	f := *format
+	if f == "" && *out == "-" {
+		f = "json"
+	}
+	f, err := formatOf(f, *out)
+	if err != nil {
+		return err
+	}
+
+	checker := &servicePostgre.ConsistencyChecker{Stores: map[string]servicePostgre.ConsistencyStore{
+		models.BackendPostgres: &repository.ReplicaRepository{DB: a.postgres()},
+		models.BackendMongo:    repoMongo.NewReplicaMongoRepository(a.mongoDB()),
+	}}
+	report, err := checker.Check(context.Background(), *source, *repair, *dryRun)
+	if err != nil {
+		return err
+	}
+	if report.Counts.Repaired > 0 || report.Counts.Failed > 0 {
+		a.audit(models.AuditUpdate, models.AuditEntityConsistency, report.Target, nil, report.Counts)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if f == "csv" {
+		err = servicePostgre.WriteConsistencyCSV(w, report)
+	} else {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	}
+	if err != nil {
+		return err
+	}
+
+	c := report.Counts
+	fmt.Fprintf(os.Stderr, "%s → %s: %d missing, %d extra, %d mismatch", report.Source, report.Target, c.Missing, c.Extra, c.Mismatched)
+	if report.Repair && !report.DryRun {
+		fmt.Fprintf(os.Stderr, ", %d diperbaiki, %d gagal", c.Repaired, c.Failed)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if left := c.Missing + c.Extra + c.Mismatched - c.Repaired; left > 0 {
+		return fmt.Errorf("%d perbedaan belum diperbaiki", left)
+	}
+	return nil
+}
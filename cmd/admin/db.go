@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go_clean/app/models/postgresql"
+	repoMongo "go_clean/app/repository/mongodb"
+	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+	"go_clean/database"
+)
+
+// runMigrate memakai migration yang sama dengan server (di-embed di binary):
+//
+//	admin migrate up
+//	admin migrate down -steps 1
+//	admin migrate status
+func runMigrate(a *cli, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "jumlah migration yang di-rollback (down)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Pemakaian: admin migrate up | down [-steps N] | status")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return errors.New("aksi migrate wajib: up, down atau status")
+	}
+	action := args[0]
+	fs.Parse(args[1:])
+
+	m, err := database.NewMigrator(a.postgres())
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch action {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			fmt.Printf("up   %04d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("skema sudah versi terbaru")
+		}
+		return err
+	case "down":
+		done, err := m.Down(ctx, *steps)
+		for _, mg := range done {
+			fmt.Printf("down %04d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("tidak ada migration yang bisa di-rollback")
+		}
+		return err
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for _, s := range list {
+			state := "pending"
+			switch {
+			case s.Unknown:
+				state = "tidak dikenal (binary lebih lama?)"
+			case s.Modified:
+				state = "BERUBAH setelah dijalankan"
+			case s.Applied:
+				state = "ok " + s.AppliedAt.Format(time.RFC3339)
+			default:
+				pending++
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+		fmt.Printf("%d pending\n", pending)
+		return nil
+	}
+	fs.Usage()
+	return fmt.Errorf("aksi migrate tidak dikenal: %q", action)
+}
+
+// runMongoBootstrap membuat koleksi, index dan validator MongoDB sesuai database.MongoCollections.
+// Dengan -check tidak ada yang diubah; exit 1 kalau ada drift, cocok untuk CI.
+func runMongoBootstrap(a *cli, args []string) error {
+	fs := flag.NewFlagSet("mongo-bootstrap", flag.ExitOnError)
+	check := fs.Bool("check", false, "hanya laporkan perbedaan, tidak mengubah apa pun")
+	fs.Parse(args)
+
+	changes, err := database.EnsureMongoSchema(context.Background(), a.mongoDB(), database.MongoSchemaOptions{
+		DryRun:           *check,
+		ValidationAction: config.LoadMigration().MongoValidationAction,
+	})
+	problems := 0
+	for _, c := range changes {
+		if c.Kind == database.MongoChangeDrift || c.Kind == database.MongoChangeError {
+			problems++
+		}
+		fmt.Println(c)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("koleksi MongoDB sudah sesuai deklarasi")
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d drift / gagal", problems)
+	}
+	return nil
+}
+
+// runSeed membuat role bawaan yang belum ada; role yang sudah ada tidak disentuh
+func runSeed(a *cli, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.Parse(args)
+
+	roles := &repository.RoleRepository{DB: a.postgres()}
+	names := make([]string, 0, len(models.DefaultRolePermissions))
+	for name := range models.DefaultRolePermissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		perms := models.DefaultRolePermissions[name]
+		created, err := roles.EnsureSystemRole(context.Background(), name, models.SystemRoleDescriptions[name], perms)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", name, err)
+		}
+		if !created {
+			fmt.Printf("lewati %s (sudah ada)\n", name)
+			continue
+		}
+		a.audit(models.AuditCreate, models.AuditEntityRole, name, nil, models.Role{
+			Name: name, Description: models.SystemRoleDescriptions[name], System: true, Permissions: perms,
+		})
+		fmt.Printf("role %s dibuat\n", name)
+	}
+	return nil
+}
+
+func runPurgeTrash(a *cli, args []string) error {
+	fs := flag.NewFlagSet("purge-trash", flag.ExitOnError)
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "umur minimal di trash, misal 720h")
+	backend := fs.String("backend", config.LoadData().Backend, "penyimpan data pekerjaan: postgres atau mongo (default DATA_BACKEND)")
+	fs.Parse(args)
+	if *olderThan < 0 {
+		return errors.New("-older-than tidak boleh negatif")
+	}
+
+	var store repository.PekerjaanRepositoryInterface
+	switch *backend {
+	case models.BackendPostgres:
+		store = &repository.PekerjaanRepository{DB: a.postgres()}
+	case models.BackendMongo:
+		store = repoMongo.NewPekerjaanMongoDriver(a.mongoDB())
+	default:
+		return fmt.Errorf("backend harus postgres atau mongo, bukan %q", *backend)
+	}
+
+	before := time.Now().Add(-*olderThan)
+	n, err := store.PurgeTrash(context.Background(), before)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		a.audit(models.AuditDelete, models.AuditEntityPekerjaan, "trash", nil,
+			map[string]interface{}{"purged": n, "deleted_before": before})
+	}
+	fmt.Printf("%d pekerjaan dihapus permanen dari trash (di-trash sebelum %s)\n", n, before.Format(time.RFC3339))
+	return nil
+}
+
+// runDBCheck tidak memakai database.Connect* karena keduanya log.Fatal; semua backend dicek lalu dilaporkan
+func runDBCheck(a *cli, args []string) error {
+	fs := flag.NewFlagSet("db-check", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 5*time.Second, "batas waktu tiap koneksi")
+	fs.Parse(args)
+
+	failed := 0
+	report := func(name string, start time.Time, err error) {
+		if err != nil {
+			failed++
+			fmt.Printf("%-9s GAGAL  %v\n", name, err)
+			return
+		}
+		fmt.Printf("%-9s OK     %s\n", name, time.Since(start).Round(time.Millisecond))
+	}
+
+	start := time.Now()
+	report("postgres", start, pingPostgres(os.Getenv("DB_DSN"), *timeout))
+	start = time.Now()
+	report("mongodb", start, pingMongo(os.Getenv("MONGO_URI"), *timeout))
+
+	if failed > 0 {
+		return fmt.Errorf("%d koneksi gagal", failed)
+	}
+	return nil
+}
+
+func pingPostgres(dsn string, timeout time.Duration) error {
+	if dsn == "" {
+		return errors.New("DB_DSN tidak diset")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
+func pingMongo(uri string, timeout time.Duration) error {
+	if uri == "" {
+		uri = "mongodb://localhost:27017"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	return client.Ping(ctx, nil)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go_clean/app/models/postgresql"
+	"go_clean/app/repository/postgresql"
+	"go_clean/config"
+	"go_clean/database"
+	"go_clean/middleware"
+	"go_clean/utils"
+)
+
+// admin adalah CLI operasional untuk Alumni API. Konfigurasi dibaca dari .env yang sama dengan server.
+// Contoh:
+//
+//	go run ./cmd/admin user-create -username budi -email budi@mail.com -role operator_prodi
+//	go run ./cmd/admin alumni-export -source mongo -format csv -out alumni.csv
+//	go run ./cmd/admin migrate up
+//	go run ./cmd/admin db-check
+//	go run ./cmd/admin consistency-check -source postgres -format csv -out diff.csv
+type command struct {
+	usage string
+	run   func(a *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"hash":                {"cetak hash password (algoritma aktif)", runHash},
+	"user-create":         {"buat akun baru (postgres / mongo)", runUserCreate},
+	"user-disable":        {"nonaktifkan akun Postgres dan cabut semua sesinya", runUserDisable},
+	"user-enable":         {"aktifkan kembali akun Postgres", runUserEnable},
+	"user-reset-password": {"set password baru (acak kalau -password kosong) dan cabut semua sesi", runUserResetPassword},
+	"user-role":           {"ganti role akun (promote / demote)", runUserRole},
+	"migrate":             {"migration skema Postgres: up | down [-steps N] | status", runMigrate},
+	"seed":                {"buat role bawaan beserta permission default", runSeed},
+	"mongo-bootstrap":     {"buat index & validator koleksi MongoDB, -check untuk laporan drift", runMongoBootstrap},
+	"alumni-import":       {"import alumni dari file CSV / JSON", runAlumniImport},
+	"alumni-export":       {"export alumni ke CSV / JSON", runAlumniExport},
+	"purge-trash":         {"hapus permanen pekerjaan di trash yang lebih lama dari -older-than", runPurgeTrash},
+	"db-check":            {"cek koneksi Postgres & MongoDB", runDBCheck},
+	"consistency-check":   {"bandingkan alumni & pekerjaan Postgres ↔ MongoDB, -repair untuk memperbaiki", runConsistencyCheck},
+}
+
+// cli membuka koneksi database hanya saat subcommand membutuhkannya
+type cli struct {
+	pg    *sql.DB
+	mongo *mongo.Database
+}
+
+func (a *cli) postgres() *sql.DB {
+	if a.pg == nil {
+		database.ConnectDB(config.LoadPostgresPool())
+		a.pg = database.DB
+	}
+	return a.pg
+}
+
+func (a *cli) mongoDB() *mongo.Database {
+	if a.mongo == nil {
+		database.ConnectMongoDB(config.LoadMongoPool())
+		a.mongo = database.MongoDB
+	}
+	return a.mongo
+}
+
+func (a *cli) close() {
+	if a.pg != nil {
+		a.pg.Close()
+	}
+	if a.mongo != nil {
+		a.mongo.Client().Disconnect(context.Background())
+	}
+}
+
+// audit mencatat perubahan dari CLI ke audit_logs dengan actor "cli"; gagal mencatat hanya di-log
+func (a *cli) audit(action, entityType, entityID string, before, after interface{}) {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor = "cli:" + u.Username
+	}
+	e := &models.AuditEntry{
+		Actor:         actor,
+		ActorUsername: actor,
+		Role:          "cli",
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityID,
+		Before:        middleware.AuditJSON(before),
+		After:         middleware.AuditJSON(after),
+	}
+	repo := &repository.AuditRepository{DB: a.postgres()}
+	if err := repo.Insert(context.Background(), e); err != nil {
+		log.Printf("gagal mencatat audit %s %s/%s: %v", action, entityType, entityID, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Pemakaian: admin <perintah> [flag]\n\nPerintah:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nJalankan \"admin <perintah> -h\" untuk flag tiap perintah.")
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "perintah tidak dikenal: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	// .env opsional: di server produksi variabel biasanya sudah diset langsung
+	_ = godotenv.Load()
+	passwordCfg, err := config.LoadPassword()
+	if err != nil {
+		log.Fatalf("konfigurasi password tidak valid: %v", err)
+	}
+	utils.UsePasswordConfig(passwordCfg)
+
+	a := &cli{}
+	err = cmd.run(a, os.Args[2:])
+	a.close()
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
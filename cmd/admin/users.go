@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go_clean/app/models/postgresql"
+	repoMongo "go_clean/app/repository/mongodb"
+	"go_clean/app/repository/postgresql"
+	serviceIdentity "go_clean/app/service/identity"
+	"go_clean/utils"
+)
+
+func runHash(a *cli, args []string) error {
+	fs := flag.NewFlagSet("hash", flag.ExitOnError)
+	password := fs.String("password", "", "password yang di-hash")
+	fs.Parse(args)
+	if *password == "" {
+		return errors.New("-password wajib")
+	}
+	h, err := utils.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	fmt.Println(h)
+	return nil
+}
+
+// backendFlag default mengikuti AUTH_BACKEND, sama dengan /api/login
+func backendFlag(fs *flag.FlagSet) *string {
+	def := os.Getenv("AUTH_BACKEND")
+	if def == "" {
+		def = models.BackendPostgres
+	}
+	return fs.String("backend", def, "penyimpan akun: postgres atau mongo")
+}
+
+// userStore dipenuhi repository.AuthRepository (Postgres) dan UserMongoRepository
+type userStore interface {
+	serviceIdentity.AccountStore
+	CreateIdentity(ctx context.Context, username, email, passwordHash, role string) (*models.Identity, error)
+}
+
+func (a *cli) accountStore(backend string) (userStore, error) {
+	switch backend {
+	case models.BackendPostgres:
+		return &repository.AuthRepository{DB: a.postgres()}, nil
+	case models.BackendMongo:
+		return repoMongo.NewUserMongoRepository(a.mongoDB()), nil
+	}
+	return nil, fmt.Errorf("backend harus postgres atau mongo, bukan %q", backend)
+}
+
+// findUser mencari akun berdasarkan username atau email
+func (a *cli) findUser(backend, ident string) (userStore, *models.Identity, error) {
+	if ident == "" {
+		return nil, nil, errors.New("-user (username / email) wajib")
+	}
+	store, err := a.accountStore(backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, _, err := store.FindIdentity(context.Background(), ident)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil, fmt.Errorf("akun %q tidak ditemukan di %s", ident, backend)
+	}
+	return store, id, err
+}
+
+func auditUserEntity(backend string) string {
+	if backend == models.BackendMongo {
+		return models.AuditEntityUserMongo
+	}
+	return models.AuditEntityUser
+}
+
+// passwordOrRandom memvalidasi password dari flag, atau membuat password acak yang dicetak sekali
+func passwordOrRandom(pw string) (string, bool, error) {
+	if pw != "" {
+		return pw, false, utils.ValidatePassword(pw)
+	}
+	random, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", false, err
+	}
+	return random, true, nil
+}
+
+func (a *cli) roleExists(role string) error {
+	exists, err := (&repository.RoleRepository{DB: a.postgres()}).Exists(context.Background(), role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("role %q tidak ada, lihat tabel roles", role)
+	}
+	return nil
+}
+
+func (a *cli) revokeSessions(id *models.Identity) {
+	if err := (&repository.RefreshTokenRepository{DB: a.postgres()}).RevokeAllForUser(context.Background(), id.Backend, id.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "peringatan: gagal mencabut sesi %s: %v\n", id.Subject(), err)
+	}
+}
+
+func runUserCreate(a *cli, args []string) error {
+	fs := flag.NewFlagSet("user-create", flag.ExitOnError)
+	backend := backendFlag(fs)
+	username := fs.String("username", "", "username")
+	email := fs.String("email", "", "email")
+	password := fs.String("password", "", "password (kosong = dibuat acak dan dicetak)")
+	role := fs.String("role", models.RoleAlumni, "nama role di tabel roles")
+	fs.Parse(args)
+
+	*username, *email = strings.TrimSpace(*username), strings.TrimSpace(*email)
+	*role = models.NormalizeRole(strings.ToLower(strings.TrimSpace(*role)))
+	if *username == "" || *email == "" {
+		return errors.New("-username dan -email wajib")
+	}
+	pw, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+	if err := a.roleExists(*role); err != nil {
+		return err
+	}
+	store, err := a.accountStore(*backend)
+	if err != nil {
+		return err
+	}
+	for _, ident := range []string{*username, *email} {
+		if _, _, err := store.FindIdentity(context.Background(), ident); err == nil {
+			return fmt.Errorf("username / email %q sudah dipakai", ident)
+		}
+	}
+	hash, err := utils.HashPassword(pw)
+	if err != nil {
+		return err
+	}
+
+	// akun buatan admin langsung terverifikasi, sama dengan /api/register-admin
+	id, err := store.CreateIdentity(context.Background(), *username, *email, hash, *role)
+	if err != nil {
+		return err
+	}
+	a.audit(models.AuditCreate, auditUserEntity(id.Backend), id.ID, nil, id)
+
+	fmt.Printf("akun dibuat: %s (%s, role %s)\n", id.Username, id.Subject(), id.Role)
+	if generated {
+		fmt.Printf("password sementara: %s\n", pw)
+	}
+	return nil
+}
+
+// postgresUser mengambil user Postgres untuk perintah yang hanya berlaku di Postgres
+func (a *cli) postgresUser(fs *flag.FlagSet, args []string) (*repository.UserRepository, *models.User, error) {
+	ident := fs.String("user", "", "username / email akun Postgres")
+	fs.Parse(args)
+	_, id, err := a.findUser(models.BackendPostgres, *ident)
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, _ := strconv.Atoi(id.ID)
+	users := &repository.UserRepository{DB: a.postgres()}
+	u, err := users.GetUserByID(context.Background(), userID)
+	return users, u, err
+}
+
+func runUserDisable(a *cli, args []string) error {
+	users, u, err := a.postgresUser(flag.NewFlagSet("user-disable", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	if !u.Disabled {
+		if _, err := users.SetDisabled(context.Background(), u.ID, true); err != nil {
+			return err
+		}
+		after := *u
+		after.Disabled = true
+		a.audit(models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	}
+	id := models.PostgresIdentity(*u)
+	a.revokeSessions(&id)
+	fmt.Printf("akun %s dinonaktifkan, semua sesi dicabut\n", u.Username)
+	return nil
+}
+
+func runUserEnable(a *cli, args []string) error {
+	users, u, err := a.postgresUser(flag.NewFlagSet("user-enable", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	if u.Disabled {
+		if _, err := users.SetDisabled(context.Background(), u.ID, false); err != nil {
+			return err
+		}
+		after := *u
+		after.Disabled = false
+		a.audit(models.AuditUpdate, models.AuditEntityUser, strconv.Itoa(u.ID), u, after)
+	}
+	fmt.Printf("akun %s aktif\n", u.Username)
+	return nil
+}
+
+func runUserResetPassword(a *cli, args []string) error {
+	fs := flag.NewFlagSet("user-reset-password", flag.ExitOnError)
+	backend := backendFlag(fs)
+	ident := fs.String("user", "", "username / email")
+	password := fs.String("password", "", "password baru (kosong = dibuat acak dan dicetak)")
+	fs.Parse(args)
+
+	store, id, err := a.findUser(*backend, *ident)
+	if err != nil {
+		return err
+	}
+	pw, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(pw)
+	if err != nil {
+		return err
+	}
+	if err := store.UpdatePassword(context.Background(), id.ID, hash); err != nil {
+		return err
+	}
+	a.revokeSessions(id)
+	a.audit(models.AuditUpdate, auditUserEntity(id.Backend), id.ID, nil, map[string]bool{"password_reset_forced": true})
+
+	fmt.Printf("password %s diganti, semua sesi dicabut\n", id.Username)
+	if generated {
+		fmt.Printf("password sementara: %s\n", pw)
+	}
+	return nil
+}
+
+func runUserRole(a *cli, args []string) error {
+	fs := flag.NewFlagSet("user-role", flag.ExitOnError)
+	backend := backendFlag(fs)
+	ident := fs.String("user", "", "username / email")
+	role := fs.String("role", "", "role baru, misal admin")
+	fs.Parse(args)
+
+	*role = models.NormalizeRole(strings.ToLower(strings.TrimSpace(*role)))
+	if *role == "" {
+		return errors.New("-role wajib")
+	}
+	_, id, err := a.findUser(*backend, *ident)
+	if err != nil {
+		return err
+	}
+	if err := a.roleExists(*role); err != nil {
+		return err
+	}
+
+	if id.Backend == models.BackendPostgres {
+		userID, _ := strconv.Atoi(id.ID)
+		_, err = (&repository.UserRepository{DB: a.postgres()}).UpdateRole(context.Background(), userID, *role)
+	} else {
+		err = repoMongo.NewUserMongoRepository(a.mongoDB()).UpdateRole(context.Background(), id.ID, *role)
+	}
+	if err != nil {
+		return err
+	}
+	after := *id
+	after.Role = *role
+	a.audit(models.AuditUpdate, auditUserEntity(id.Backend), id.ID, id, after)
+
+	// sesi lama dicabut supaya role lama tidak terbawa lewat refresh token
+	a.revokeSessions(id)
+	fmt.Printf("role %s: %s → %s, semua sesi dicabut (berlaku setelah login ulang)\n", id.Username, id.Role, *role)
+	return nil
+}
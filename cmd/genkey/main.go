@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// genkey membuat private key baru untuk JWT_KEYS_FILE dan mencetak entri manifest-nya.
+// Contoh: go run ./cmd/genkey -alg EdDSA -kid 2026-11 -dir ./keys -start 2026-11-01 -days 90
+func main() {
+	alg := flag.String("alg", "EdDSA", "RS256 atau EdDSA")
+	kid := flag.String("kid", time.Now().Format("20060102"), "key id")
+	dir := flag.String("dir", "./keys", "folder penyimpanan key")
+	start := flag.String("start", time.Now().Format("2006-01-02"), "tanggal mulai dipakai sign (YYYY-MM-DD)")
+	days := flag.Int("days", 90, "masa berlaku key (hari)")
+	flag.Parse()
+
+	var der []byte
+	var err error
+	switch *alg {
+	case "RS256":
+		var k *rsa.PrivateKey
+		k, err = rsa.GenerateKey(rand.Reader, 2048)
+		if err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(k)
+		}
+	case "EdDSA":
+		var k ed25519.PrivateKey
+		_, k, err = ed25519.GenerateKey(rand.Reader)
+		if err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(k)
+		}
+	default:
+		log.Fatalf("alg %q tidak didukung", *alg)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	notBefore, err := time.Parse("2006-01-02", *start)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatal(err)
+	}
+	file := *kid + ".pem"
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(*dir, file), pemBytes, 0o600); err != nil {
+		log.Fatal(err)
+	}
+
+	entry, _ := json.MarshalIndent(map[string]interface{}{
+		"kid":         *kid,
+		"alg":         *alg,
+		"private_key": file,
+		"not_before":  notBefore.UTC(),
+		"not_after":   notBefore.AddDate(0, 0, *days).UTC(),
+	}, "", "  ")
+	fmt.Println("Tambahkan ke manifest JWT_KEYS_FILE:")
+	fmt.Println(string(entry))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"go_clean/utils/oidcmock"
+)
+
+// mockoidc menjalankan IdP OIDC tiruan untuk mencoba login SSO secara lokal.
+// Contoh: go run ./cmd/mockoidc -addr :9000 -client-id alumni-api
+// lalu set OIDC_ISSUER=http://localhost:9000 dan OIDC_CLIENT_ID=alumni-api, buka /api/oidc/login.
+// User dipilih lewat ?login_hint=<username> di URL authorize (default user pertama).
+func main() {
+	addr := flag.String("addr", ":9000", "alamat listen")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer (harus sama dengan URL yang dipakai API)")
+	clientID := flag.String("client-id", "alumni-api", "client_id yang diterima")
+	clientSecret := flag.String("client-secret", "", "client secret (kosong = client publik)")
+	email := flag.String("email", "sso.user@alumni.local", "email user default")
+	username := flag.String("username", "sso.user", "preferred_username user default")
+	flag.Parse()
+
+	p, err := oidcmock.New(*issuer, *clientID, oidcmock.User{
+		Subject:           "mock-" + *username,
+		Email:             *email,
+		EmailVerified:     true,
+		PreferredUsername: *username,
+		Name:              *username,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.ClientSecret = *clientSecret
+
+	log.Printf("mock OIDC provider di %s (issuer %s, client_id %s)", *addr, *issuer, *clientID)
+	log.Fatal(http.ListenAndServe(*addr, p.Handler()))
+}
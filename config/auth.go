@@ -0,0 +1,115 @@
+package config
+
+import (
+	"crypto/sha256"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuthConfig struct {
+	// Backend menentukan penyimpan akun untuk /api/login: "postgres" atau "mongo"
+	Backend          string
+	PasswordResetTTL time.Duration
+	// LinkSecret menandatangani link di email (verifikasi email, dsb)
+	LinkSecret     []byte
+	EmailVerifyTTL time.Duration
+	// MFA (TOTP). MFARequiredForAdmin memaksa role admin enroll 2FA saat login berikutnya.
+	MFARequiredForAdmin bool
+	MFAIssuer           string
+	MFAChallengeTTL     time.Duration
+	// MFAEncryptionKey mengenkripsi secret TOTP di DB (AES-256)
+	MFAEncryptionKey []byte
+}
+
+func LoadAuth() AuthConfig {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_BACKEND")))
+	switch backend {
+	case "":
+		backend = "postgres"
+	case "postgres", "mongo":
+	default:
+		log.Fatalf("AUTH_BACKEND tidak dikenal: %q (postgres/mongo)", backend)
+	}
+	resetMin, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES"))
+	if err != nil || resetMin <= 0 {
+		resetMin = 30
+	}
+	verifyHours, err := strconv.Atoi(os.Getenv("EMAIL_VERIFY_TTL_HOURS"))
+	if err != nil || verifyHours <= 0 {
+		verifyHours = 48
+	}
+	linkSecret := os.Getenv("LINK_SIGNING_SECRET")
+	if linkSecret == "" {
+		linkSecret = os.Getenv("JWT_SECRET")
+	}
+	if len(linkSecret) < 32 {
+		log.Fatal("LINK_SIGNING_SECRET (atau JWT_SECRET) minimal 32 karakter")
+	}
+	mfaRequired, _ := strconv.ParseBool(os.Getenv("MFA_REQUIRED_FOR_ADMIN"))
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "Alumni API"
+	}
+	challengeMin, err := strconv.Atoi(os.Getenv("MFA_CHALLENGE_TTL_MINUTES"))
+	if err != nil || challengeMin <= 0 {
+		challengeMin = 5
+	}
+	mfaKey := os.Getenv("MFA_ENCRYPTION_KEY")
+	if mfaKey == "" {
+		mfaKey = linkSecret
+	}
+	mfaEncKey := sha256.Sum256([]byte(mfaKey))
+	return AuthConfig{
+		Backend:          backend,
+		PasswordResetTTL: time.Duration(resetMin) * time.Minute,
+		LinkSecret:       []byte(linkSecret),
+		EmailVerifyTTL:   time.Duration(verifyHours) * time.Hour,
+
+		MFARequiredForAdmin: mfaRequired,
+		MFAIssuer:           mfaIssuer,
+		MFAChallengeTTL:     time.Duration(challengeMin) * time.Minute,
+		MFAEncryptionKey:    mfaEncKey[:],
+	}
+}
+
+// LoginGuardConfig mengatur proteksi brute-force di endpoint login
+type LoginGuardConfig struct {
+	MaxFailures   int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	Lockout       time.Duration
+	MaxLockout    time.Duration
+	IPMaxFailures int
+	IPWindow      time.Duration
+	// AccountMaxFailures = gagal per identifier dari semua IP sebelum akun dikunci AccountLockout
+	// (berlipat tiap kali terkunci lagi, maks MaxLockout). Lebih longgar dari MaxFailures per IP.
+	AccountMaxFailures int
+	AccountLockout     time.Duration
+}
+
+func LoadLoginGuard() LoginGuardConfig {
+	return LoginGuardConfig{
+		MaxFailures:   envInt("LOGIN_MAX_FAILURES", 5),
+		BaseDelay:     time.Duration(envInt("LOGIN_BACKOFF_BASE_SECONDS", 1)) * time.Second,
+		MaxDelay:      30 * time.Second,
+		Lockout:       time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		MaxLockout:    24 * time.Hour,
+		IPMaxFailures: envInt("LOGIN_IP_MAX_FAILURES", 30),
+		IPWindow:      time.Duration(envInt("LOGIN_IP_WINDOW_MINUTES", 15)) * time.Minute,
+
+		AccountMaxFailures: envInt("LOGIN_ACCOUNT_MAX_FAILURES", 20),
+		AccountLockout:     time.Duration(envInt("LOGIN_ACCOUNT_LOCKOUT_MINUTES", 5)) * time.Minute,
+	}
+}
+
+// envInt membaca env angka positif, selain itu pakai default
+func envInt(key string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// ConcurrencyConfig mengatur optimistic concurrency (ETag / If-Match) pada endpoint ubah & hapus alumni / pekerjaan
+type ConcurrencyConfig struct {
+	// RequireIfMatch: PUT / DELETE tanpa header If-Match ditolak 428. false = If-Match tetap dicek kalau dikirim.
+	RequireIfMatch bool
+}
+
+func LoadConcurrency() ConcurrencyConfig {
+	required, err := strconv.ParseBool(os.Getenv("CONCURRENCY_REQUIRE_IF_MATCH"))
+	if err != nil {
+		required = true
+	}
+	return ConcurrencyConfig{RequireIfMatch: required}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+type DataConfig struct {
+	// Backend menentukan penyimpan data alumni & pekerjaan untuk /api/alumni dan /api/pekerjaan: "postgres" atau "mongo"
+	Backend string
+}
+
+func LoadData() DataConfig {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("DATA_BACKEND")))
+	switch backend {
+	case "":
+		backend = "postgres"
+	case "postgres", "mongo":
+	default:
+		log.Fatalf("DATA_BACKEND tidak dikenal: %q (postgres/mongo)", backend)
+	}
+	return DataConfig{Backend: backend}
+}
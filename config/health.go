@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// HealthConfig mengatur /readyz & /api/admin/diagnostics
+type HealthConfig struct {
+	// CheckTimeout = batas waktu tiap pengecekan dependency; yang lewat batas dianggap gagal
+	CheckTimeout time.Duration
+}
+
+func LoadHealth() HealthConfig {
+	return HealthConfig{
+		CheckTimeout: time.Duration(envInt("HEALTH_CHECK_TIMEOUT_SECONDS", 2)) * time.Second,
+	}
+}
@@ -4,22 +4,38 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 )
 
 type JWTConfig struct {
-	Secret []byte
-	TTLHours int
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// KeysFile menunjuk manifest key asimetris (RS256/EdDSA). Kosong = pakai HS256 + Secret.
+	KeysFile string
 }
 
 func LoadJWT() JWTConfig {
+	keysFile := os.Getenv("JWT_KEYS_FILE")
 	secret := os.Getenv("JWT_SECRET")
-	if len(secret) < 32 {
+	if keysFile == "" && len(secret) < 32 {
 		log.Fatal("JWT_SECRET minimal 32 karakter")
 	}
-	ttlStr := os.Getenv("JWT_TTL_HOURS")
-	ttl, err := strconv.Atoi(ttlStr)
-	if err != nil || ttl <= 0 {
-		ttl = 24
+
+	// access token sengaja pendek, sesi panjang ditangani refresh token
+	accessMin, err := strconv.Atoi(os.Getenv("JWT_ACCESS_TTL_MINUTES"))
+	if err != nil || accessMin <= 0 {
+		accessMin = 15
+	}
+	refreshHours, err := strconv.Atoi(os.Getenv("JWT_REFRESH_TTL_HOURS"))
+	if err != nil || refreshHours <= 0 {
+		refreshHours = 24 * 7
+	}
+
+	return JWTConfig{
+		Secret:     []byte(secret),
+		AccessTTL:  time.Duration(accessMin) * time.Minute,
+		RefreshTTL: time.Duration(refreshHours) * time.Hour,
+		KeysFile:   keysFile,
 	}
-	return JWTConfig{Secret: []byte(secret), TTLHours: ttl}
 }
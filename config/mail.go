@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type MailConfig struct {
+	// Driver "smtp" untuk kirim beneran, "outbox" menulis email ke folder (dev/test)
+	Driver    string
+	From      string
+	OutboxDir string
+	SMTPHost  string
+	SMTPPort  int
+	SMTPUser  string
+	SMTPPass  string
+	// PublicURL dipakai untuk membentuk link di email (reset password, verifikasi, dsb)
+	PublicURL string
+}
+
+func LoadMail() MailConfig {
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = 587
+	}
+	cfg := MailConfig{
+		Driver:    strings.ToLower(os.Getenv("MAIL_DRIVER")),
+		From:      os.Getenv("MAIL_FROM"),
+		OutboxDir: os.Getenv("MAIL_OUTBOX_DIR"),
+		SMTPHost:  os.Getenv("SMTP_HOST"),
+		SMTPPort:  port,
+		SMTPUser:  os.Getenv("SMTP_USERNAME"),
+		SMTPPass:  os.Getenv("SMTP_PASSWORD"),
+		PublicURL: strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = "outbox"
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@localhost"
+	}
+	if cfg.OutboxDir == "" {
+		cfg.OutboxDir = "./outbox"
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = "http://localhost:" + os.Getenv("APP_PORT")
+	}
+	return cfg
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type MigrationConfig struct {
+	// AutoMigrate menjalankan migration yang belum dijalankan saat server start.
+	// Matikan kalau migration dijalankan terpisah lewat "admin migrate up".
+	AutoMigrate bool
+	// MongoBootstrap membuat koleksi, index dan validator MongoDB saat server start ("admin mongo-bootstrap")
+	MongoBootstrap bool
+	// MongoValidationAction: "error" menolak dokumen yang tidak sesuai skema, "warn" hanya mencatat di log MongoDB
+	MongoValidationAction string
+}
+
+func LoadMigration() MigrationConfig {
+	auto, err := strconv.ParseBool(os.Getenv("DB_AUTO_MIGRATE"))
+	if err != nil {
+		auto = true
+	}
+	mongoBootstrap, err := strconv.ParseBool(os.Getenv("MONGO_AUTO_BOOTSTRAP"))
+	if err != nil {
+		mongoBootstrap = true
+	}
+	action := strings.ToLower(strings.TrimSpace(os.Getenv("MONGO_VALIDATION_ACTION")))
+	switch action {
+	case "":
+		action = "error"
+	case "error", "warn":
+	default:
+		log.Fatalf("MONGO_VALIDATION_ACTION tidak dikenal: %q (error/warn)", action)
+	}
+	return MigrationConfig{AutoMigrate: auto, MongoBootstrap: mongoBootstrap, MongoValidationAction: action}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OIDCConfig struct {
+	// Issuer kosong = SSO nonaktif
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// AutoProvision membuat akun lokal untuk user IdP yang belum punya akun
+	AutoProvision bool
+	DefaultRole   string
+	// StateTTL = batas waktu user menyelesaikan login di IdP
+	StateTTL time.Duration
+}
+
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+func LoadOIDC() OIDCConfig {
+	autoProvision, _ := strconv.ParseBool(os.Getenv("OIDC_AUTO_PROVISION"))
+	cfg := OIDCConfig{
+		Issuer:        strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        strings.Fields(os.Getenv("OIDC_SCOPES")),
+		AutoProvision: autoProvision,
+		DefaultRole:   strings.ToLower(strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE"))),
+		StateTTL:      time.Duration(envInt("OIDC_STATE_TTL_MINUTES", 10)) * time.Minute,
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = LoadMail().PublicURL + "/api/oidc/callback"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = "alumni"
+	}
+	return cfg
+}
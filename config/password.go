@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PasswordConfig mengatur hash password baru dan aturan kekuatan password
+type PasswordConfig struct {
+	// Algorithm untuk hash baru: "argon2id" (default) atau "bcrypt".
+	// Hash lama dengan algoritma / parameter lain tetap bisa login dan di-hash ulang otomatis.
+	Algorithm  string
+	BcryptCost int
+	// parameter argon2id, Argon2Memory dalam KiB
+	Argon2Memory  uint32
+	Argon2Time    uint32
+	Argon2Threads uint8
+
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPassword = nilai kalau env PASSWORD_* tidak diset
+func DefaultPassword() PasswordConfig {
+	return PasswordConfig{
+		Algorithm:  "argon2id",
+		BcryptCost: 10,
+		// default rekomendasi OWASP: m=19 MiB, t=2, p=1
+		Argon2Memory:  19 * 1024,
+		Argon2Time:    2,
+		Argon2Threads: 1,
+		MinLength:     8,
+	}
+}
+
+// LoadPassword dipanggil sekali saat startup; nilai yang salah ditolak di sini supaya server
+// tidak baru gagal saat login / registrasi pertama
+func LoadPassword() (PasswordConfig, error) {
+	cfg := DefaultPassword()
+	algo := strings.ToLower(strings.TrimSpace(os.Getenv("PASSWORD_HASH_ALGORITHM")))
+	switch algo {
+	case "":
+	case "argon2id", "bcrypt":
+		cfg.Algorithm = algo
+	default:
+		return cfg, fmt.Errorf("PASSWORD_HASH_ALGORITHM tidak dikenal: %q (argon2id/bcrypt)", algo)
+	}
+
+	cost, err := envRange("PASSWORD_BCRYPT_COST", cfg.BcryptCost, 4, 31)
+	if err != nil {
+		return cfg, err
+	}
+	memory, err := envRange("PASSWORD_ARGON2_MEMORY_KB", int(cfg.Argon2Memory), 8, 4*1024*1024)
+	if err != nil {
+		return cfg, err
+	}
+	iterations, err := envRange("PASSWORD_ARGON2_TIME", int(cfg.Argon2Time), 1, 100)
+	if err != nil {
+		return cfg, err
+	}
+	threads, err := envRange("PASSWORD_ARGON2_THREADS", int(cfg.Argon2Threads), 1, 255)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.BcryptCost, cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Threads = cost, uint32(memory), uint32(iterations), uint8(threads)
+
+	cfg.MinLength = envInt("PASSWORD_MIN_LENGTH", cfg.MinLength)
+	cfg.RequireUpper, _ = strconv.ParseBool(os.Getenv("PASSWORD_REQUIRE_UPPER"))
+	cfg.RequireLower, _ = strconv.ParseBool(os.Getenv("PASSWORD_REQUIRE_LOWER"))
+	cfg.RequireDigit, _ = strconv.ParseBool(os.Getenv("PASSWORD_REQUIRE_DIGIT"))
+	cfg.RequireSymbol, _ = strconv.ParseBool(os.Getenv("PASSWORD_REQUIRE_SYMBOL"))
+	return cfg, nil
+}
+
+// envRange seperti envInt, tapi nilai yang diset dan tidak valid dianggap error, bukan diganti default
+func envRange(key string, def, min, max int) (int, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s harus angka %d..%d, dapat %q", key, min, max, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+// PostgresPoolConfig mengatur pool koneksi database/sql ke Postgres
+type PostgresPoolConfig struct {
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime: koneksi ditutup & dibuka ulang setelah umur ini (mis. supaya ikut failover / pgbouncer)
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// MongoPoolConfig mengatur pool koneksi mongo-driver
+type MongoPoolConfig struct {
+	MaxPoolSize     uint64
+	MinPoolSize     uint64
+	MaxConnIdleTime time.Duration
+	ConnectTimeout  time.Duration
+	// ServerSelectionTimeout = batas tunggu operasi kalau MongoDB tidak bisa dihubungi
+	ServerSelectionTimeout time.Duration
+}
+
+func LoadPostgresPool() PostgresPoolConfig {
+	return PostgresPoolConfig{
+		MaxOpenConns:    envInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    envInt("DB_MAX_IDLE_CONNS", 5),
+		ConnMaxLifetime: time.Duration(envInt("DB_CONN_MAX_LIFETIME_MINUTES", 30)) * time.Minute,
+		ConnMaxIdleTime: time.Duration(envInt("DB_CONN_MAX_IDLE_MINUTES", 5)) * time.Minute,
+	}
+}
+
+func LoadMongoPool() MongoPoolConfig {
+	return MongoPoolConfig{
+		MaxPoolSize:            uint64(envInt("MONGO_MAX_POOL_SIZE", 100)),
+		MinPoolSize:            uint64(envInt("MONGO_MIN_POOL_SIZE", 0)),
+		MaxConnIdleTime:        time.Duration(envInt("MONGO_MAX_CONN_IDLE_MINUTES", 10)) * time.Minute,
+		ConnectTimeout:         time.Duration(envInt("MONGO_CONNECT_TIMEOUT_SECONDS", 10)) * time.Second,
+		ServerSelectionTimeout: time.Duration(envInt("MONGO_SERVER_SELECTION_TIMEOUT_SECONDS", 5)) * time.Second,
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// ReplicationConfig mengatur relay outbox yang menyalin perubahan alumni & pekerjaan dari Postgres ke MongoDB
+type ReplicationConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts = batas percobaan sebelum event dipindah ke dead-letter
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Retention = lama event yang sudah tersalin disimpan sebelum dihapus
+	Retention time.Duration
+}
+
+func LoadReplication() ReplicationConfig {
+	enabled, err := strconv.ParseBool(os.Getenv("OUTBOX_RELAY_ENABLED"))
+	if err != nil {
+		enabled = true
+	}
+	return ReplicationConfig{
+		Enabled:     enabled,
+		Interval:    time.Duration(envInt("OUTBOX_RELAY_INTERVAL_SECONDS", 2)) * time.Second,
+		BatchSize:   envInt("OUTBOX_RELAY_BATCH_SIZE", 100),
+		MaxAttempts: envInt("OUTBOX_MAX_ATTEMPTS", 10),
+		BaseBackoff: time.Duration(envInt("OUTBOX_BACKOFF_BASE_SECONDS", 5)) * time.Second,
+		MaxBackoff:  time.Duration(envInt("OUTBOX_BACKOFF_MAX_MINUTES", 30)) * time.Minute,
+		Retention:   time.Duration(envInt("OUTBOX_RETENTION_HOURS", 168)) * time.Hour,
+	}
+}
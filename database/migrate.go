@@ -0,0 +1,367 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Migration SQL ikut di-embed ke binary: database/migrations/NNNN_nama.up.sql + NNNN_nama.down.sql.
+// File yang sudah pernah dijalankan tidak boleh diubah (checksum dicek); perubahan skema = migration baru.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey = kunci pg_advisory_lock supaya beberapa instance yang start bersamaan tidak balapan
+const migrationLockKey int64 = 0x616c756d6e69 // "alumni"
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var ErrChecksumMismatch = errors.New("checksum migration berbeda dengan yang sudah dijalankan")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// Checksum = sha256 file up, disimpan di schema_migrations saat dijalankan
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified: file up berubah setelah dijalankan
+	Modified bool
+	// Unknown: tercatat di database tapi tidak ada di binary ini (binary lebih lama)
+	Unknown bool
+}
+
+type appliedMigration struct {
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// LoadMigrations membaca pasangan file up/down dari dir, berurutan versi
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := migrationFileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nama file migration tidak valid: %s (format NNNN_nama.up.sql / .down.sql)", e.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("%s: versi harus lebih dari 0", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("versi %d dipakai dua migration: %s dan %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			sum := sha256.Sum256(body)
+			m.Up, m.Checksum = string(body), hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("migration %04d_%s tidak punya file .up.sql", m.Version, m.Name)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s tidak punya file .down.sql", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Migrator menjalankan migration ke Postgres. Semua operasi memegang advisory lock yang sama,
+// dan tiap migration dijalankan dalam satu transaksi bersama pencatatannya di schema_migrations.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// NewMigrator memakai migration yang di-embed di binary
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	list, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: list}, nil
+}
+
+// MigrateUp dipanggil saat server start (DB_AUTO_MIGRATE)
+func MigrateUp(ctx context.Context, db *sql.DB) error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	applied, err := m.Up(ctx)
+	for _, mg := range applied {
+		log.Printf("migration %04d_%s dijalankan", mg.Version, mg.Name)
+	}
+	return err
+}
+
+// withLock menjalankan fn di satu koneksi yang memegang pg_advisory_lock.
+// Lock level sesi: ikut lepas kalau koneksi putus, jadi instance yang crash tidak meninggalkan lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied map[int]appliedMigration) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("gagal mengambil lock migration: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INT PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			checksum   CHAR(64)    NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`); err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var v int
+		var a appliedMigration
+		if err := rows.Scan(&v, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = a
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+// verify menolak jalan kalau ada migration yang sudah dijalankan tapi isinya berubah
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	for _, mg := range m.Migrations {
+		if a, ok := applied[mg.Version]; ok && a.Checksum != mg.Checksum {
+			return fmt.Errorf("%04d_%s: %w", mg.Version, mg.Name, ErrChecksumMismatch)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) known() map[int]bool {
+	known := make(map[int]bool, len(m.Migrations))
+	for _, mg := range m.Migrations {
+		known[mg.Version] = true
+	}
+	return known
+}
+
+// Up menjalankan semua migration yang belum tercatat, berurutan versi
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[int]appliedMigration) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		known := m.known()
+		for v, a := range applied {
+			if !known[v] {
+				log.Printf("⚠️  migration %04d_%s tercatat di database tapi tidak ada di binary ini", v, a.Name)
+			}
+		}
+		for _, mg := range m.Migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mg.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mg.Version, mg.Name, mg.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", mg.Version, mg.Name, err)
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down membatalkan steps migration terakhir yang sudah dijalankan, dari versi tertinggi
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps harus lebih dari 0")
+	}
+	byVersion := make(map[int]Migration, len(m.Migrations))
+	for _, mg := range m.Migrations {
+		byVersion[mg.Version] = mg
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[int]appliedMigration) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+		if len(versions) > steps {
+			versions = versions[:steps]
+		}
+
+		for _, v := range versions {
+			mg, ok := byVersion[v]
+			if !ok {
+				return fmt.Errorf("migration %04d_%s tidak ada di binary ini, tidak bisa di-rollback", v, applied[v].Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mg.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, v)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %04d_%s: %w", mg.Version, mg.Name, err)
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status menggabungkan migration di binary dengan yang tercatat di database
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var list []MigrationStatus
+	err := m.withLock(ctx, func(_ *sql.Conn, applied map[int]appliedMigration) error {
+		for _, mg := range m.Migrations {
+			s := MigrationStatus{Version: mg.Version, Name: mg.Name}
+			if a, ok := applied[mg.Version]; ok {
+				at := a.AppliedAt
+				s.Applied, s.AppliedAt, s.Modified = true, &at, a.Checksum != mg.Checksum
+			}
+			list = append(list, s)
+		}
+		known := m.known()
+		for v, a := range applied {
+			if !known[v] {
+				at := a.AppliedAt
+				list = append(list, MigrationStatus{Version: v, Name: a.Name, Applied: true, AppliedAt: &at, Unknown: true})
+			}
+		}
+		return nil
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, err
+}
+
+// Pending = migration di binary yang belum tercatat di schema_migrations. Tidak memegang advisory lock
+// supaya bisa dipanggil sering (readiness probe) tanpa ikut menunggu migration yang sedang jalan.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	var exists bool
+	if err := m.DB.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int]bool{}
+	if exists {
+		rows, err := m.DB.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var v int
+			if err := rows.Scan(&v); err != nil {
+				return nil, err
+			}
+			applied[v] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	var pending []Migration
+	for _, mg := range m.Migrations {
+		if !applied[mg.Version] {
+			pending = append(pending, mg)
+		}
+	}
+	return pending, nil
+}
+
+// CheckApplied dipakai /readyz: error kalau masih ada migration yang belum dijalankan
+func (m *Migrator) CheckApplied(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migration belum dijalankan (mulai %04d_%s)", len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	list, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 || list[0].Version != 1 || list[0].Name != "init" {
+		t.Fatalf("migration pertama harus 0001_init, dapat %+v", list)
+	}
+	for _, table := range []string{"alumni", "users", "pekerjaan_alumni", "roles", "audit_logs"} {
+		if !strings.Contains(list[0].Up, "CREATE TABLE IF NOT EXISTS "+table+" ") {
+			t.Errorf("0001_init tidak membuat tabel %s", table)
+		}
+	}
+}
+
+func TestLoadMigrationsOrderAndChecksum(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0010_b.up.sql":   {Data: []byte("SELECT 10")},
+		"m/0010_b.down.sql": {Data: []byte("SELECT -10")},
+		"m/0002_a.up.sql":   {Data: []byte("SELECT 2")},
+		"m/0002_a.down.sql": {Data: []byte("SELECT -2")},
+	}
+	list, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Version != 2 || list[1].Version != 10 {
+		t.Fatalf("urutan salah: %+v", list)
+	}
+	before := list[0].Checksum
+	fsys["m/0002_a.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 3")}
+	list, _ = LoadMigrations(fsys, "m")
+	if list[0].Checksum == before {
+		t.Error("checksum harus berubah kalau isi file up berubah")
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidSet(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"tanpa down": {"m/0001_a.up.sql": {Data: []byte("SELECT 1")}},
+		"nama salah": {"m/init.sql": {Data: []byte("SELECT 1")}},
+		"versi ganda": {
+			"m/0001_a.up.sql":   {Data: []byte("SELECT 1")},
+			"m/0001_a.down.sql": {Data: []byte("SELECT 1")},
+			"m/0001_b.up.sql":   {Data: []byte("SELECT 1")},
+			"m/0001_b.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := LoadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: harus error", name)
+		}
+	}
+}
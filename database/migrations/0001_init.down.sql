@@ -0,0 +1,16 @@
+-- Menghapus seluruh skema awal beserta datanya. Hanya untuk development.
+DROP TABLE IF EXISTS audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+DROP TABLE IF EXISTS oidc_identities;
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS pekerjaan_alumni;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS alumni;
@@ -0,0 +1,253 @@
+-- 0001: skema awal. Semua statement idempotent (IF NOT EXISTS / ON CONFLICT) supaya database
+-- yang dulu dibuat manual bisa di-baseline: tabel yang ada dibiarkan, kolom yang kurang ditambahkan.
+
+CREATE TABLE IF NOT EXISTS alumni (
+    id          SERIAL PRIMARY KEY,
+    nim         TEXT        NOT NULL UNIQUE,
+    nama        TEXT        NOT NULL,
+    jurusan     TEXT        NOT NULL DEFAULT '',
+    angkatan    INT         NOT NULL DEFAULT 0,
+    tahun_lulus INT         NOT NULL DEFAULT 0,
+    email       TEXT        NOT NULL,
+    no_telepon  TEXT,
+    alamat      TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alumni_angkatan ON alumni (angkatan);
+
+-- Akun login backend Postgres. alumni_id diisi kalau akun milik alumni tertentu.
+CREATE TABLE IF NOT EXISTS users (
+    id             SERIAL PRIMARY KEY,
+    username       TEXT        NOT NULL UNIQUE,
+    email          TEXT        NOT NULL UNIQUE,
+    password_hash  TEXT        NOT NULL,
+    role           TEXT        NOT NULL DEFAULT 'alumni',
+    alumni_id      INT         REFERENCES alumni (id) ON DELETE SET NULL,
+    -- akun lama & buatan admin dianggap terverifikasi; RegisterUser menyimpan FALSE secara eksplisit
+    email_verified BOOLEAN     NOT NULL DEFAULT TRUE,
+    -- akun yang dinonaktifkan admin tidak bisa login / refresh token sampai diaktifkan lagi
+    disabled_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Riwayat pekerjaan alumni. is_delete / deleted_at / deleted_by = soft delete (trash).
+CREATE TABLE IF NOT EXISTS pekerjaan_alumni (
+    id                    SERIAL PRIMARY KEY,
+    alumni_id             INT         NOT NULL REFERENCES alumni (id) ON DELETE CASCADE,
+    nama_perusahaan       TEXT        NOT NULL,
+    posisi_jabatan        TEXT        NOT NULL,
+    bidang_industri       TEXT        NOT NULL DEFAULT '',
+    lokasi_kerja          TEXT        NOT NULL DEFAULT '',
+    gaji_range            TEXT,
+    tanggal_mulai_kerja   DATE        NOT NULL,
+    tanggal_selesai_kerja DATE,
+    status_pekerjaan      TEXT        NOT NULL DEFAULT '',
+    deskripsi_pekerjaan   TEXT,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    is_delete             BOOLEAN     NOT NULL DEFAULT FALSE,
+    deleted_at            TIMESTAMPTZ,
+    deleted_by            TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_pekerjaan_alumni_alumni ON pekerjaan_alumni (alumni_id);
+
+-- kolom yang sering tertinggal di database buatan tangan
+ALTER TABLE users ADD COLUMN IF NOT EXISTS alumni_id INT REFERENCES alumni (id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+ALTER TABLE pekerjaan_alumni ADD COLUMN IF NOT EXISTS is_delete BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pekerjaan_alumni ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE pekerjaan_alumni ADD COLUMN IF NOT EXISTS deleted_by TEXT NOT NULL DEFAULT '';
+
+-- RBAC: role → permission. Daftar permission yang valid ada di app/models/postgresql/role.go.
+CREATE TABLE IF NOT EXISTS roles (
+    name        TEXT PRIMARY KEY,
+    description TEXT        NOT NULL DEFAULT '',
+    is_system   BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role       TEXT NOT NULL REFERENCES roles (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('admin',          'Administrator, semua akses',              TRUE),
+    ('operator_prodi', 'Operator program studi',                  TRUE),
+    ('alumni',         'Alumni, kelola data pekerjaan sendiri',   TRUE),
+    ('viewer',         'Hanya baca data alumni & pekerjaan',      TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin',          '*'),
+    ('operator_prodi', 'alumni:read'),
+    ('operator_prodi', 'alumni:write'),
+    ('operator_prodi', 'pekerjaan:read'),
+    ('operator_prodi', 'pekerjaan:read_all'),
+    ('operator_prodi', 'pekerjaan:write'),
+    ('operator_prodi', 'files:upload'),
+    ('operator_prodi', 'files:read_all'),
+    ('alumni',         'alumni:read'),
+    ('alumni',         'pekerjaan:read'),
+    ('alumni',         'pekerjaan:write_own'),
+    ('alumni',         'files:upload'),
+    ('viewer',         'alumni:read'),
+    ('viewer',         'pekerjaan:read')
+ON CONFLICT DO NOTHING;
+
+-- role lama "user" menjadi "alumni"
+UPDATE users SET role = 'alumni' WHERE role = 'user';
+
+-- Refresh token (rotasi + reuse detection). user_id TEXT karena bisa id Postgres atau ObjectID Mongo.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          SERIAL PRIMARY KEY,
+    backend     TEXT        NOT NULL,
+    user_id     TEXT        NOT NULL,
+    username    TEXT        NOT NULL,
+    role        TEXT        NOT NULL,
+    email_verified BOOLEAN  NOT NULL DEFAULT TRUE,
+    family_id   UUID        NOT NULL,
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+
+-- Sesi login. id = family_id refresh token (klaim "fid" di access token), jadi satu login = satu sesi.
+CREATE TABLE IF NOT EXISTS sessions (
+    id           UUID        PRIMARY KEY,
+    backend      TEXT        NOT NULL,
+    user_id      TEXT        NOT NULL,
+    username     TEXT        NOT NULL,
+    user_agent   TEXT        NOT NULL DEFAULT '',
+    ip           TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (backend, user_id);
+
+-- family yang sudah ada sebelum tabel ini dibuat
+INSERT INTO sessions (id, backend, user_id, username, created_at, last_seen_at, expires_at, revoked_at)
+SELECT family_id, MIN(backend), MIN(user_id), MIN(username), MIN(created_at), MAX(created_at), MAX(expires_at),
+       CASE WHEN BOOL_OR(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id
+ON CONFLICT (id) DO NOTHING;
+
+-- Token reset password sekali pakai. Berlaku untuk user Postgres maupun Mongo (backend + user_id).
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id          SERIAL PRIMARY KEY,
+    backend     TEXT        NOT NULL,
+    user_id     TEXT        NOT NULL,
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_user ON password_reset_tokens (backend, user_id);
+
+-- TOTP 2FA (RFC 6238). Kunci (backend, user_id) seperti refresh_tokens supaya berlaku untuk akun Postgres & Mongo.
+-- secret disimpan terenkripsi AES-GCM; last_used_step mencegah kode yang sama dipakai dua kali.
+CREATE TABLE IF NOT EXISTS user_mfa (
+    backend        TEXT        NOT NULL,
+    user_id        TEXT        NOT NULL,
+    secret         TEXT        NOT NULL,
+    enabled        BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT      NOT NULL DEFAULT 0,
+    confirmed_at   TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (backend, user_id)
+);
+
+-- Kode cadangan sekali pakai, hanya hash-nya yang disimpan
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id         SERIAL PRIMARY KEY,
+    backend    TEXT        NOT NULL,
+    user_id    TEXT        NOT NULL,
+    code_hash  CHAR(64)    NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes (backend, user_id);
+
+-- API key untuk service-to-service (header X-API-Key). Key utuh tidak disimpan, hanya sha256-nya.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           SERIAL PRIMARY KEY,
+    name         TEXT        NOT NULL,
+    prefix       CHAR(8)     NOT NULL UNIQUE,
+    key_hash     CHAR(64)    NOT NULL,
+    scopes       TEXT[]      NOT NULL DEFAULT '{}',
+    created_by   TEXT        NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Login SSO (OpenID Connect). State dihapus saat callback; baris kedaluwarsa boleh dibersihkan berkala.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash CHAR(64)    PRIMARY KEY,
+    nonce      TEXT        NOT NULL,
+    verifier   TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- akun IdP → akun lokal (Postgres atau Mongo)
+CREATE TABLE IF NOT EXISTS oidc_identities (
+    issuer        TEXT        NOT NULL,
+    subject       TEXT        NOT NULL,
+    backend       TEXT        NOT NULL,
+    user_id       TEXT        NOT NULL,
+    email         TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    PRIMARY KEY (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_identities_user ON oidc_identities (backend, user_id);
+
+-- Audit log semua perubahan data (Postgres maupun Mongo). Append-only: UPDATE / DELETE ditolak trigger.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id             BIGSERIAL   PRIMARY KEY,
+    actor          TEXT        NOT NULL DEFAULT '',
+    actor_username TEXT        NOT NULL DEFAULT '',
+    role           TEXT        NOT NULL DEFAULT '',
+    ip             TEXT        NOT NULL DEFAULT '',
+    action         TEXT        NOT NULL,
+    entity_type    TEXT        NOT NULL,
+    entity_id      TEXT        NOT NULL,
+    before_data    JSONB,
+    after_data     JSONB,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON audit_logs (created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs bersifat append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,19 @@
+-- Transactional outbox: setiap perubahan alumni / pekerjaan_alumni menulis event di transaksi yang sama,
+-- lalu relay di server menyalinnya ke MongoDB (lihat OutboxRelay di app/service/postgresql/replication_service.go).
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGSERIAL   PRIMARY KEY,
+    aggregate       TEXT        NOT NULL,
+    aggregate_id    INT         NOT NULL,
+    op              TEXT        NOT NULL,
+    -- payload = isi baris setelah perubahan (NULL untuk delete)
+    payload         JSONB,
+    status          TEXT        NOT NULL DEFAULT 'pending',
+    attempts        INT         NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status ON outbox_events (status, created_at);
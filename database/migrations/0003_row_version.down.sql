@@ -0,0 +1,2 @@
+ALTER TABLE pekerjaan_alumni DROP COLUMN IF EXISTS version;
+ALTER TABLE alumni DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Versi baris untuk optimistic concurrency: dinaikkan setiap UPDATE, dikirim ke client sebagai ETag
+-- dan dicocokkan dengan header If-Match pada PUT / DELETE.
+ALTER TABLE alumni ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE pekerjaan_alumni ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
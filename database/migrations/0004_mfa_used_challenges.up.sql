@@ -0,0 +1,7 @@
+-- mfa_token (langkah kedua login) yang sudah berhasil ditukar dengan token. Hanya hash-nya yang disimpan;
+-- baris yang sudah lewat expires_at tidak dibutuhkan lagi karena token-nya sendiri sudah kedaluwarsa.
+CREATE TABLE IF NOT EXISTS mfa_used_challenges (
+    token_hash CHAR(64)    PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_pending_parent;
+DROP INDEX IF EXISTS idx_outbox_events_pending_key;
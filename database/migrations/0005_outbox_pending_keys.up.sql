@@ -0,0 +1,7 @@
+-- Index untuk filter OutboxRepository.Pending: event lebih awal di baris yang sama, dan upsert pekerjaan
+-- yang masih menahan event alumni pemiliknya.
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending_key
+    ON outbox_events (aggregate, aggregate_id, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending_parent
+    ON outbox_events (((payload->>'alumni_id')::int), id)
+    WHERE status = 'pending' AND aggregate = 'pekerjaan' AND op = 'upsert';
@@ -4,7 +4,8 @@ import (
 	"context"
 	"log"
 	"os"
-	"time"
+
+	"go_clean/config"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -12,7 +13,8 @@ import (
 
 var MongoDB *mongo.Database
 
-func ConnectMongoDB() {
+// ConnectMongoDB membuka koneksi MongoDB dengan pengaturan pool dari config.LoadMongoPool
+func ConnectMongoDB(pool config.MongoPoolConfig) {
 	uri := os.Getenv("MONGO_URI")
 	dbName := os.Getenv("MONGO_DB")
 
@@ -25,8 +27,14 @@ func ConnectMongoDB() {
 		log.Println("⚠️  MONGO_DB tidak diset, gunakan default:", dbName)
 	}
 
-	clientOpts := options.Client().ApplyURI(uri)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	clientOpts := options.Client().ApplyURI(uri).
+		SetMaxPoolSize(pool.MaxPoolSize).
+		SetMinPoolSize(pool.MinPoolSize).
+		SetMaxConnIdleTime(pool.MaxConnIdleTime).
+		SetConnectTimeout(pool.ConnectTimeout).
+		SetServerSelectionTimeout(pool.ServerSelectionTimeout).
+		SetPoolMonitor(mongoPoolMonitor(pool))
+	ctx, cancel := context.WithTimeout(context.Background(), pool.ConnectTimeout)
 	defer cancel()
 
 	client, err := mongo.Connect(ctx, clientOpts)
@@ -0,0 +1,406 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoIndex = index yang wajib ada. Index dikenali dari Name; kalau Keys / Unique / Partial berbeda dilaporkan sebagai drift,
+// tidak di-drop otomatis karena membangun ulang index unik di koleksi besar bisa lama dan bisa gagal karena duplikat.
+// Partial = partialFilterExpression, mis. supaya dokumen lama tanpa id tidak ikut dicek unik.
+type MongoIndex struct {
+	Name    string
+	Keys    bson.D
+	Unique  bool
+	Partial bson.M
+}
+
+// hasPositiveID = dokumen yang sudah punya id dari Postgres (dokumen lama tidak punya field ini)
+func hasPositiveID(field string) bson.M {
+	return bson.M{field: bson.M{"$gt": 0}}
+}
+
+// MongoCollection = deklarasi satu koleksi: index + validator $jsonSchema. Versioned = dokumen punya field
+// version untuk ETag; dokumen lama tanpa version diisi 1 saat bootstrap.
+type MongoCollection struct {
+	Name       string
+	Indexes    []MongoIndex
+	JSONSchema bson.M
+	Versioned  bool
+}
+
+// tipe angka: driver menyimpan int Go sebagai int32 kalau muat, selain itu int64
+var (
+	bsonInt        = bson.A{"int", "long"}
+	bsonString     = bson.M{"bsonType": "string"}
+	bsonDate       = bson.M{"bsonType": "date"}
+	bsonNullString = bson.M{"bsonType": bson.A{"string", "null"}}
+	bsonNullDate   = bson.M{"bsonType": bson.A{"date", "null"}}
+)
+
+// MongoCollections mengikuti struct di app/models/mongodb; ubah di sini kalau field model berubah
+var MongoCollections = []MongoCollection{
+	{
+		Name:      "alumni",
+		Versioned: true,
+		Indexes: []MongoIndex{
+			{Name: "ux_alumni_nim", Keys: bson.D{{Key: "nim", Value: 1}}, Unique: true},
+			{Name: "idx_alumni_email", Keys: bson.D{{Key: "email", Value: 1}}},
+			// alumni_id = primary key untuk DATA_BACKEND=mongo dan upsert replikasi outbox
+			{Name: "ux_alumni_alumni_id", Keys: bson.D{{Key: "alumni_id", Value: 1}}, Unique: true, Partial: hasPositiveID("alumni_id")},
+		},
+		JSONSchema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"nim", "nama", "email"},
+			"properties": bson.M{
+				"nim":          bson.M{"bsonType": "string", "minLength": 1},
+				"nama":         bson.M{"bsonType": "string", "minLength": 1},
+				"email":        bson.M{"bsonType": "string", "minLength": 1},
+				"alumni_id":    bson.M{"bsonType": bsonInt},
+				"jurusan":      bsonString,
+				"angkatan":     bson.M{"bsonType": bsonInt},
+				"tahun_lulus":  bson.M{"bsonType": bsonInt},
+				"no_telepon":   bsonString,
+				"alamat":       bsonString,
+				"tempat_kerja": bsonString,
+				"version":      bson.M{"bsonType": bsonInt},
+				"created_at":   bsonDate,
+				"updated_at":   bsonDate,
+			},
+		},
+	},
+	{
+		Name:      "pekerjaan",
+		Versioned: true,
+		Indexes: []MongoIndex{
+			{Name: "idx_pekerjaan_alumni", Keys: bson.D{{Key: "alumni_id", Value: 1}, {Key: "is_delete", Value: 1}}},
+			{Name: "idx_pekerjaan_trash", Keys: bson.D{{Key: "is_delete", Value: 1}, {Key: "deleted_at", Value: -1}}},
+			{Name: "ux_pekerjaan_pekerjaan_id", Keys: bson.D{{Key: "pekerjaan_id", Value: 1}}, Unique: true, Partial: hasPositiveID("pekerjaan_id")},
+		},
+		JSONSchema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"alumni_id", "nama_perusahaan", "posisi_jabatan", "is_delete"},
+			"properties": bson.M{
+				"pekerjaan_id":          bson.M{"bsonType": bsonInt},
+				"alumni_id":             bson.M{"bsonType": bsonInt},
+				"nama_perusahaan":       bson.M{"bsonType": "string", "minLength": 1},
+				"posisi_jabatan":        bson.M{"bsonType": "string", "minLength": 1},
+				"bidang_industri":       bsonString,
+				"lokasi_kerja":          bsonString,
+				"gaji_range":            bsonNullString,
+				"tanggal_mulai_kerja":   bsonNullDate,
+				"tanggal_selesai_kerja": bsonNullDate,
+				"status_pekerjaan":      bsonString,
+				"deskripsi_pekerjaan":   bsonNullString,
+				"is_delete":             bson.M{"bsonType": "bool"},
+				"deleted_at":            bsonNullDate,
+				"deleted_by":            bsonString,
+				"version":               bson.M{"bsonType": bsonInt},
+				"created_at":            bsonDate,
+				"updated_at":            bsonDate,
+			},
+		},
+	},
+	{
+		Name: "users",
+		Indexes: []MongoIndex{
+			{Name: "ux_users_username", Keys: bson.D{{Key: "username", Value: 1}}, Unique: true},
+			{Name: "ux_users_email", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+			{Name: "idx_users_alumni_id", Keys: bson.D{{Key: "alumni_id", Value: 1}}},
+		},
+		JSONSchema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"username", "email", "password_hash", "role"},
+			"properties": bson.M{
+				"username":      bson.M{"bsonType": "string", "minLength": 1},
+				"email":         bson.M{"bsonType": "string", "minLength": 1},
+				"password_hash": bson.M{"bsonType": "string", "minLength": 1},
+				"role":          bson.M{"bsonType": "string", "minLength": 1},
+				"alumni_id":     bson.M{"bsonType": bson.A{"int", "long", "null"}},
+				"disabled":      bson.M{"bsonType": "bool"},
+				"created_at":    bsonDate,
+			},
+		},
+	},
+	{
+		Name: "files",
+		Indexes: []MongoIndex{
+			{Name: "idx_files_uploaded_by", Keys: bson.D{{Key: "uploaded_by", Value: 1}, {Key: "uploaded_at", Value: -1}}},
+		},
+		JSONSchema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"file_name", "file_path", "uploaded_at"},
+			"properties": bson.M{
+				"file_name":     bson.M{"bsonType": "string", "minLength": 1},
+				"original_name": bsonString,
+				"file_path":     bson.M{"bsonType": "string", "minLength": 1},
+				"file_size":     bson.M{"bsonType": bsonInt},
+				"file_type":     bsonString,
+				"uploaded_at":   bsonDate,
+				"uploaded_by":   bsonString,
+			},
+		},
+	},
+}
+
+const (
+	MongoChangeCreated = "dibuat"
+	MongoChangeUpdated = "diperbarui"
+	// MongoChangeDrift = berbeda dari deklarasi dan tidak diperbaiki otomatis
+	MongoChangeDrift = "drift"
+	MongoChangeError = "gagal"
+)
+
+type MongoSchemaChange struct {
+	Collection string
+	Kind       string
+	Detail     string
+}
+
+func (c MongoSchemaChange) String() string {
+	return fmt.Sprintf("%s: %s %s", c.Collection, c.Kind, c.Detail)
+}
+
+type MongoSchemaOptions struct {
+	// DryRun hanya membandingkan; yang akan dibuat / diubah dilaporkan sebagai drift
+	DryRun bool
+	// ValidationAction "error" (default) menolak dokumen tidak valid, "warn" hanya mencatat di log MongoDB
+	ValidationAction string
+}
+
+// EnsureMongoSchema membuat koleksi, index dan validator yang belum ada / berubah. Idempotent.
+// Gagal membuat satu index (misal data lama duplikat) tidak menghentikan proses: dilaporkan sebagai MongoChangeError.
+func EnsureMongoSchema(ctx context.Context, db *mongo.Database, opts MongoSchemaOptions) ([]MongoSchemaChange, error) {
+	if opts.ValidationAction == "" {
+		opts.ValidationAction = "error"
+	}
+	existing, err := db.ListCollectionSpecifications(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	specs := map[string]*mongo.CollectionSpecification{}
+	for _, s := range existing {
+		specs[s.Name] = s
+	}
+
+	var changes []MongoSchemaChange
+	report := func(coll, kind, format string, args ...interface{}) {
+		changes = append(changes, MongoSchemaChange{Collection: coll, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	for _, c := range MongoCollections {
+		validator := bson.M{"$jsonSchema": c.JSONSchema}
+		spec, exists := specs[c.Name]
+		switch {
+		case !exists && opts.DryRun:
+			report(c.Name, MongoChangeDrift, "koleksi belum ada")
+		case !exists:
+			// moderate: dokumen lama yang tidak valid masih bisa di-update, dokumen baru wajib valid
+			createOpts := options.CreateCollection().SetValidator(validator).
+				SetValidationLevel("moderate").SetValidationAction(opts.ValidationAction)
+			if err := db.CreateCollection(ctx, c.Name, createOpts); err != nil {
+				return changes, fmt.Errorf("buat koleksi %s: %w", c.Name, err)
+			}
+			report(c.Name, MongoChangeCreated, "koleksi beserta validator")
+		default:
+			if validatorChanged(spec.Options, validator, opts.ValidationAction) {
+				if opts.DryRun {
+					report(c.Name, MongoChangeDrift, "validator berbeda dari deklarasi")
+				} else {
+					err := db.RunCommand(ctx, bson.D{
+						{Key: "collMod", Value: c.Name},
+						{Key: "validator", Value: validator},
+						{Key: "validationLevel", Value: "moderate"},
+						{Key: "validationAction", Value: opts.ValidationAction},
+					}).Err()
+					if err != nil {
+						return changes, fmt.Errorf("validator %s: %w", c.Name, err)
+					}
+					report(c.Name, MongoChangeUpdated, "validator")
+				}
+			}
+		}
+
+		if err := ensureIndexes(ctx, db.Collection(c.Name), c, !exists, opts.DryRun, report); err != nil {
+			return changes, err
+		}
+		if c.Versioned && exists {
+			if err := backfillVersion(ctx, db.Collection(c.Name), opts.DryRun, report); err != nil {
+				return changes, err
+			}
+		}
+	}
+	return changes, nil
+}
+
+// unversioned = dokumen yang ditulis sebelum ada optimistic concurrency
+var unversioned = bson.M{"version": bson.M{"$exists": false}}
+
+// backfillVersion mengisi version 1 di dokumen lama, supaya If-Match berlaku sama seperti dokumen baru
+func backfillVersion(ctx context.Context, coll *mongo.Collection, dryRun bool,
+	report func(coll, kind, format string, args ...interface{})) error {
+	if dryRun {
+		n, err := coll.CountDocuments(ctx, unversioned)
+		if err != nil {
+			return fmt.Errorf("hitung dokumen tanpa version %s: %w", coll.Name(), err)
+		}
+		if n > 0 {
+			report(coll.Name(), MongoChangeDrift, "%d dokumen belum punya version", n)
+		}
+		return nil
+	}
+	res, err := coll.UpdateMany(ctx, unversioned, bson.M{"$set": bson.M{"version": 1}})
+	if err != nil {
+		return fmt.Errorf("isi version %s: %w", coll.Name(), err)
+	}
+	if res.ModifiedCount > 0 {
+		report(coll.Name(), MongoChangeUpdated, "version 1 untuk %d dokumen lama", res.ModifiedCount)
+	}
+	return nil
+}
+
+func ensureIndexes(ctx context.Context, coll *mongo.Collection, c MongoCollection, fresh, dryRun bool,
+	report func(coll, kind, format string, args ...interface{})) error {
+	current := map[string]bson.M{}
+	if !fresh || !dryRun {
+		cur, err := coll.Indexes().List(ctx)
+		if err != nil {
+			return fmt.Errorf("daftar index %s: %w", c.Name, err)
+		}
+		var list []bson.M
+		if err := cur.All(ctx, &list); err != nil {
+			return err
+		}
+		for _, ix := range list {
+			if name, ok := ix["name"].(string); ok {
+				current[name] = ix
+			}
+		}
+	}
+
+	declared := map[string]bool{"_id_": true}
+	for _, want := range c.Indexes {
+		declared[want.Name] = true
+		got, ok := current[want.Name]
+		if ok {
+			unique, _ := got["unique"].(bool)
+			if !sameKeys(got["key"], want.Keys) || unique != want.Unique || !samePartial(got["partialFilterExpression"], want.Partial) {
+				report(c.Name, MongoChangeDrift, "index %s berbeda: di database %v unique=%v partial=%v, deklarasi %v unique=%v partial=%v",
+					want.Name, got["key"], unique, got["partialFilterExpression"], want.Keys, want.Unique, want.Partial)
+			}
+			continue
+		}
+		if dryRun {
+			report(c.Name, MongoChangeDrift, "index %s belum ada", want.Name)
+			continue
+		}
+		indexOpts := options.Index().SetName(want.Name).SetUnique(want.Unique)
+		if want.Partial != nil {
+			indexOpts.SetPartialFilterExpression(want.Partial)
+		}
+		model := mongo.IndexModel{Keys: want.Keys, Options: indexOpts}
+		if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
+			report(c.Name, MongoChangeError, "index %s: %v", want.Name, err)
+			continue
+		}
+		report(c.Name, MongoChangeCreated, "index %s", want.Name)
+	}
+	for name := range current {
+		if !declared[name] {
+			report(c.Name, MongoChangeDrift, "index %s tidak ada di deklarasi", name)
+		}
+	}
+	return nil
+}
+
+// validatorChanged membandingkan lewat round-trip BSON supaya tipe (int32, bson.A, dst) sama dengan hasil baca dari server
+func validatorChanged(collOptions bson.Raw, validator bson.M, action string) bool {
+	var got struct {
+		Validator        bson.M `bson:"validator"`
+		ValidationLevel  string `bson:"validationLevel"`
+		ValidationAction string `bson:"validationAction"`
+	}
+	if err := bson.Unmarshal(collOptions, &got); err != nil {
+		return true
+	}
+	raw, err := bson.Marshal(validator)
+	if err != nil {
+		return true
+	}
+	var want bson.M
+	if err := bson.Unmarshal(raw, &want); err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(got.Validator, want) || got.ValidationLevel != "moderate" || got.ValidationAction != action
+}
+
+// sameKeys: urutan field penting, nilai dibandingkan sebagai angka (shell bisa menyimpan 1 sebagai double)
+func sameKeys(got interface{}, want bson.D) bool {
+	raw, err := bson.Marshal(got)
+	if err != nil {
+		return false
+	}
+	var keys bson.D
+	if err := bson.Unmarshal(raw, &keys); err != nil || len(keys) != len(want) {
+		return false
+	}
+	for i, k := range keys {
+		if k.Key != want[i].Key || toFloat(k.Value) != toFloat(want[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePartial membandingkan partialFilterExpression lewat round-trip BSON, seperti validatorChanged
+func samePartial(got interface{}, want bson.M) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+	roundTrip := func(v interface{}) bson.M {
+		raw, err := bson.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var m bson.M
+		if err := bson.Unmarshal(raw, &m); err != nil {
+			return nil
+		}
+		return m
+	}
+	a, b := roundTrip(got), roundTrip(want)
+	return a != nil && reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// BootstrapMongo dipanggil saat server start (MONGO_AUTO_BOOTSTRAP); semua perubahan & drift dicatat ke log
+func BootstrapMongo(ctx context.Context, db *mongo.Database, validationAction string) error {
+	changes, err := EnsureMongoSchema(ctx, db, MongoSchemaOptions{ValidationAction: validationAction})
+	for _, c := range changes {
+		prefix := ""
+		if c.Kind == MongoChangeDrift || c.Kind == MongoChangeError {
+			prefix = "⚠️  "
+		}
+		log.Printf("%smongo %s", prefix, c)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	mongoModel "go_clean/app/models/mongodb"
+)
+
+// setiap field bson di model harus dideklarasikan di validator, supaya validator tidak tertinggal saat model berubah
+func TestMongoSchemaCoversModelFields(t *testing.T) {
+	models := map[string]interface{}{
+		"alumni":    mongoModel.AlumniMongo{},
+		"pekerjaan": mongoModel.PekerjaanMongo{},
+		"users":     mongoModel.LoginMongo{},
+		"files":     mongoModel.File{},
+	}
+	for _, c := range MongoCollections {
+		model, ok := models[c.Name]
+		if !ok {
+			t.Errorf("koleksi %s tanpa model", c.Name)
+			continue
+		}
+		props := c.JSONSchema["properties"].(bson.M)
+		typ := reflect.TypeOf(model)
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("bson"), ",")[0]
+			if name == "" || name == "_id" {
+				continue
+			}
+			if _, ok := props[name]; !ok {
+				t.Errorf("%s: field %s tidak ada di $jsonSchema", c.Name, name)
+			}
+		}
+	}
+}
+
+func TestSameKeysComparesOrderAndNumericValue(t *testing.T) {
+	want := bson.D{{Key: "alumni_id", Value: 1}, {Key: "is_delete", Value: 1}}
+	if !sameKeys(bson.M{"alumni_id": 1.0}, bson.D{{Key: "alumni_id", Value: 1}}) {
+		t.Error("1.0 dan 1 harus dianggap sama")
+	}
+	if !sameKeys(bson.D{{Key: "alumni_id", Value: int32(1)}, {Key: "is_delete", Value: int64(1)}}, want) {
+		t.Error("key sama harus cocok")
+	}
+	if sameKeys(bson.D{{Key: "is_delete", Value: 1}, {Key: "alumni_id", Value: 1}}, want) {
+		t.Error("urutan berbeda harus dianggap drift")
+	}
+	if sameKeys(bson.D{{Key: "alumni_id", Value: -1}, {Key: "is_delete", Value: 1}}, want) {
+		t.Error("arah index berbeda harus dianggap drift")
+	}
+}
+
+func TestValidatorChanged(t *testing.T) {
+	validator := bson.M{"$jsonSchema": MongoCollections[0].JSONSchema}
+	opts, err := bson.Marshal(bson.M{"validator": validator, "validationLevel": "moderate", "validationAction": "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validatorChanged(opts, validator, "error") {
+		t.Error("validator sama tidak boleh dianggap berubah")
+	}
+	if !validatorChanged(opts, validator, "warn") {
+		t.Error("validationAction berbeda harus dianggap berubah")
+	}
+	if !validatorChanged(opts, bson.M{"$jsonSchema": MongoCollections[1].JSONSchema}, "error") {
+		t.Error("skema berbeda harus dianggap berubah")
+	}
+}
+
+func TestBackfillVersionSetsOneOnUnversionedDocs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("backfill", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+		var changes []MongoSchemaChange
+		report := func(coll, kind, format string, args ...interface{}) {
+			changes = append(changes, MongoSchemaChange{Collection: coll, Kind: kind})
+		}
+		if err := backfillVersion(context.Background(), mt.Coll, false, report); err != nil {
+			t.Fatal(err)
+		}
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, err := update.LookupErr("q", "version", "$exists"); err != nil {
+			t.Fatalf("filter = %v", update.Lookup("q"))
+		}
+		if v, ok := update.LookupErr("u", "$set", "version"); ok != nil || v.AsInt64() != 1 {
+			t.Fatalf("update = %v", update.Lookup("u"))
+		}
+		if len(changes) != 1 || changes[0].Kind != MongoChangeUpdated {
+			t.Fatalf("changes = %+v", changes)
+		}
+	})
+}
+
+func TestSamePartial(t *testing.T) {
+	want := hasPositiveID("alumni_id")
+	if !samePartial(bson.D{{Key: "alumni_id", Value: bson.D{{Key: "$gt", Value: int32(0)}}}}, want) {
+		t.Error("partial yang sama dari server harus cocok")
+	}
+	if samePartial(nil, want) || samePartial(bson.M{"alumni_id": bson.M{"$gt": 1}}, want) {
+		t.Error("partial hilang / berbeda harus dianggap drift")
+	}
+	if !samePartial(nil, nil) {
+		t.Error("index tanpa partial harus cocok")
+	}
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"go_clean/config"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// PostgresPoolStats = ringkasan sql.DBStats untuk /api/admin/diagnostics
+type PostgresPoolStats struct {
+	MaxOpenConns int `json:"max_open_conns"`
+	Open         int `json:"open"`
+	InUse        int `json:"in_use"`
+	Idle         int `json:"idle"`
+	// WaitCount & WaitDurationMs naik terus kalau pool terlalu kecil untuk beban yang ada
+	WaitCount         int64   `json:"wait_count"`
+	WaitDurationMs    float64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64   `json:"max_lifetime_closed"`
+}
+
+// MongoPoolStats dihitung dari event pool mongo-driver (driver tidak menyediakan statistik pool langsung)
+type MongoPoolStats struct {
+	MaxPoolSize uint64 `json:"max_pool_size"`
+	MinPoolSize uint64 `json:"min_pool_size"`
+	Open        int64  `json:"open"`
+	InUse       int64  `json:"in_use"`
+	// CheckOutFailed = permintaan koneksi yang gagal (timeout menunggu pool, server tidak bisa dihubungi)
+	CheckOutFailed int64 `json:"check_out_failed"`
+	Cleared        int64 `json:"cleared"`
+}
+
+// ApplyPostgresPool memasang batas pool koneksi ke db
+func ApplyPostgresPool(db *sql.DB, pool config.PostgresPoolConfig) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
+
+func PostgresStats(db *sql.DB) PostgresPoolStats {
+	s := db.Stats()
+	return PostgresPoolStats{
+		MaxOpenConns:      s.MaxOpenConnections,
+		Open:              s.OpenConnections,
+		InUse:             s.InUse,
+		Idle:              s.Idle,
+		WaitCount:         s.WaitCount,
+		WaitDurationMs:    float64(s.WaitDuration.Microseconds()) / 1000,
+		MaxIdleClosed:     s.MaxIdleClosed,
+		MaxIdleTimeClosed: s.MaxIdleTimeClosed,
+		MaxLifetimeClosed: s.MaxLifetimeClosed,
+	}
+}
+
+// mongoPool = penghitung event pool client MongoDB milik proses ini (satu client, dibuat ConnectMongoDB)
+var mongoPool struct {
+	maxPoolSize, minPoolSize             uint64
+	open, inUse, checkOutFailed, cleared atomic.Int64
+}
+
+func mongoPoolMonitor(pool config.MongoPoolConfig) *event.PoolMonitor {
+	mongoPool.maxPoolSize, mongoPool.minPoolSize = pool.MaxPoolSize, pool.MinPoolSize
+	return &event.PoolMonitor{Event: countMongoPoolEvent}
+}
+
+func countMongoPoolEvent(e *event.PoolEvent) {
+	switch e.Type {
+	case event.ConnectionCreated:
+		mongoPool.open.Add(1)
+	case event.ConnectionClosed:
+		mongoPool.open.Add(-1)
+	case event.GetSucceeded:
+		mongoPool.inUse.Add(1)
+	case event.ConnectionReturned:
+		mongoPool.inUse.Add(-1)
+	case event.GetFailed:
+		mongoPool.checkOutFailed.Add(1)
+	case event.PoolCleared:
+		mongoPool.cleared.Add(1)
+	}
+}
+
+func MongoStats() MongoPoolStats {
+	return MongoPoolStats{
+		MaxPoolSize:    mongoPool.maxPoolSize,
+		MinPoolSize:    mongoPool.minPoolSize,
+		Open:           mongoPool.open.Load(),
+		InUse:          mongoPool.inUse.Load(),
+		CheckOutFailed: mongoPool.checkOutFailed.Load(),
+		Cleared:        mongoPool.cleared.Load(),
+	}
+}
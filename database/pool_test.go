@@ -0,0 +1,22 @@
+package database
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMongoPoolCounters(t *testing.T) {
+	before := MongoStats()
+	for _, typ := range []string{
+		event.ConnectionCreated, event.ConnectionCreated,
+		event.GetSucceeded, event.GetSucceeded, event.ConnectionReturned,
+		event.GetFailed, event.ConnectionClosed,
+	} {
+		countMongoPoolEvent(&event.PoolEvent{Type: typ})
+	}
+	got := MongoStats()
+	if got.Open-before.Open != 1 || got.InUse-before.InUse != 1 || got.CheckOutFailed-before.CheckOutFailed != 1 {
+		t.Fatalf("statistik pool salah: sebelum %+v, sesudah %+v", before, got)
+	}
+}
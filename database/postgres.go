@@ -5,13 +5,15 @@ import (
 	"log"
 	"os"
 
+	"go_clean/config"
+
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
 var DB *sql.DB
 
-// ConnectDB menginisialisasi koneksi ke database
-func ConnectDB() {
+// ConnectDB menginisialisasi koneksi ke database dengan batas pool dari config.LoadPostgresPool
+func ConnectDB(pool config.PostgresPoolConfig) {
 	dsn := os.Getenv("DB_DSN")
 	if dsn == "" {
 		log.Fatal("DB_DSN environment variable not set")
@@ -22,6 +24,7 @@ func ConnectDB() {
 	if err != nil {
 		log.Fatalf("Could not connect to the database: %v", err)
 	}
+	ApplyPostgresPool(DB, pool)
 
 	if err = DB.Ping(); err != nil {
 		log.Fatalf("Could not ping the database: %v", err)
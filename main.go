@@ -24,12 +24,12 @@ package main
 // @tag.description Upload, lihat dan hapus file
 // @tag.order 5
 
-// @tag.name Alumni-PostgresSQL
-// @tag.description Upload, lihat dan hapus file
+// @tag.name Alumni
+// @tag.description Endpoint untuk data alumni (PostgreSQL atau MongoDB sesuai DATA_BACKEND)
 // @tag.order 6
 
-// @tag.name Pekerjaan-PostgresSQL
-// @tag.description Endpoint untuk data pekerjaan alumni (PostgreSQL)
+// @tag.name Pekerjaan
+// @tag.description Endpoint untuk data pekerjaan alumni (PostgreSQL atau MongoDB sesuai DATA_BACKEND)
 // @tag.order 7	
 
 import (
@@ -40,14 +40,19 @@ import (
 	"syscall"
 	"time"
 	// "fmt"
-	// "go_clean/utils"
 
 	"go_clean/config"
+	"go_clean/middleware"
+	"go_clean/utils"
 	"go_clean/database"
 	routePostgre "go_clean/route/postgresql"
 	routeMongo "go_clean/route/mongodb"
+	routeIdentity "go_clean/route/identity"
+	serviceIdentity "go_clean/app/service/identity"
 	repoMongo "go_clean/app/repository/mongodb"
 	serviceMongo "go_clean/app/service/mongodb"
+	repoPostgre "go_clean/app/repository/postgresql"
+	servicePostgre "go_clean/app/service/postgresql"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -74,13 +79,30 @@ import (
 func main() {
 	// 1️ Load env
 	config.LoadEnv()
+	utils.MustLoadSigningKeys()
+	passwordCfg, err := config.LoadPassword()
+	if err != nil {
+		log.Fatalf("Konfigurasi password tidak valid: %v", err)
+	}
+	utils.UsePasswordConfig(passwordCfg)
 
 	// 2️ Connect ke PostgreSQL
-	database.ConnectDB()
+	migCfg := config.LoadMigration()
+	database.ConnectDB(config.LoadPostgresPool())
 	defer database.DB.Close()
+	if migCfg.AutoMigrate {
+		if err := database.MigrateUp(context.Background(), database.DB); err != nil {
+			log.Fatalf("Gagal menjalankan migration: %v", err)
+		}
+	}
 
 	// 3️ Connect ke MongoDB
-	database.ConnectMongoDB()
+	database.ConnectMongoDB(config.LoadMongoPool())
+	if migCfg.MongoBootstrap {
+		if err := database.BootstrapMongo(context.Background(), database.MongoDB, migCfg.MongoValidationAction); err != nil {
+			log.Fatalf("Gagal bootstrap koleksi MongoDB: %v", err)
+		}
+	}
 
 	// 4️ Setup Fiber app
 	app := fiber.New(fiber.Config{
@@ -114,19 +136,168 @@ func main() {
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Welcome to Alumni API 🚀")
 	})
-	routeMongo.SetupAuthMongoRoutes(app, database.MongoDB)
+
+	// autentikasi untuk semua route yang butuh login; dibuat sebelum route group mana pun didaftarkan
+	authn := &middleware.Auth{
+		Sessions:    &repoPostgre.SessionRepository{DB: database.DB},
+		Permissions: middleware.NewPermissionCache(&repoPostgre.RoleRepository{DB: database.DB}, 30*time.Second),
+		APIKeys:     &repoPostgre.APIKeyRepository{DB: database.DB},
+	}
+	// perubahan data dari route Postgres maupun Mongo dicatat ke tabel audit_logs
+	audit := &middleware.Auditor{Store: &repoPostgre.AuditRepository{DB: database.DB}}
+
+	// probe /healthz & /readyz + diagnosa admin; /readyz gagal (503) kalau salah satu dependency tidak siap
+	uploadDir := "./uploads"
+	migrator, err := database.NewMigrator(database.DB)
+	if err != nil {
+		log.Fatalf("Gagal membaca migration: %v", err)
+	}
+	routePostgre.SetupHealthRoutes(app, &servicePostgre.HealthService{
+		Checks: []servicePostgre.HealthCheck{
+			{Name: "postgres", Check: database.DB.PingContext},
+			{Name: "mongodb", Check: func(ctx context.Context) error { return database.MongoDB.Client().Ping(ctx, nil) }},
+			{Name: "uploads", Check: func(context.Context) error { return utils.CheckWritableDir(uploadDir) }},
+			{Name: "migrations", Check: migrator.CheckApplied},
+		},
+		Timeout: config.LoadHealth().CheckTimeout,
+		Pools: map[string]func() interface{}{
+			"postgres": func() interface{} { return database.PostgresStats(database.DB) },
+			"mongodb":  func() interface{} { return database.MongoStats() },
+		},
+		Started: time.Now(),
+	}, authn)
+
+	// public key untuk verifikasi JWT oleh service lain (tanpa berbagi secret)
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(utils.PublicJWKS())
+	})
+	// refresh token disimpan di Postgres, dipakai juga oleh login Mongo
+	// akun Postgres yang dinonaktifkan admin tidak bisa mendapat token baru
+	tokenService := &servicePostgre.TokenService{
+		Repo:     &repoPostgre.RefreshTokenRepository{DB: database.DB},
+		Accounts: &repoPostgre.UserRepository{DB: database.DB},
+	}
+	authCfg := config.LoadAuth()
+
+	// proteksi brute-force bersama untuk semua endpoint login (state in-memory per instance)
+	loginGuard := middleware.NewLoginGuard(config.LoadLoginGuard(), middleware.NewMemoryAttemptStore(24*time.Hour))
+	routeIdentity.SetupLockoutRoutes(app, loginGuard, authn)
+
+	// 2FA TOTP dipakai semua endpoint login (Postgres, Mongo, /api/login)
+	mfaService := &serviceIdentity.MFAService{
+		Repo:             &repoPostgre.MFARepository{DB: database.DB},
+		Tokens:           tokenService,
+		Secret:           authCfg.LinkSecret,
+		EncKey:           authCfg.MFAEncryptionKey,
+		Issuer:           authCfg.MFAIssuer,
+		TTL:              authCfg.MFAChallengeTTL,
+		RequiredForAdmin: authCfg.MFARequiredForAdmin,
+		Guard:            loginGuard,
+	}
+	routeMongo.SetupAuthMongoRoutes(app, database.MongoDB, tokenService, mfaService, loginGuard)
+
+	// login gabungan: satu endpoint /api/login, penyimpan akun dipilih lewat AUTH_BACKEND
+	pgUsers := &repoPostgre.AuthRepository{DB: database.DB}
+	mongoUsers := repoMongo.NewUserMongoRepository(database.MongoDB)
+	accountStores := []serviceIdentity.AccountStore{pgUsers, mongoUsers}
+	if authCfg.Backend == "mongo" {
+		accountStores = []serviceIdentity.AccountStore{mongoUsers, pgUsers}
+	}
+	routeIdentity.SetupIdentityRoutes(app, accountStores[0], tokenService, mfaService, loginGuard)
+	routeIdentity.SetupMFARoutes(app, mfaService, authn)
+
+	// SSO OpenID Connect (opsional), akun dipetakan ke penyimpan akun yang sama dengan /api/login
+	if oidcCfg := config.LoadOIDC(); oidcCfg.Enabled() {
+		accounts := serviceIdentity.OIDCAccountStore(pgUsers)
+		if authCfg.Backend == "mongo" {
+			accounts = mongoUsers
+		}
+		routeIdentity.SetupOIDCRoutes(app, &serviceIdentity.OIDCService{
+			Provider:      utils.NewOIDCClient(oidcCfg.Issuer, oidcCfg.ClientID, oidcCfg.ClientSecret, oidcCfg.RedirectURL, oidcCfg.Scopes),
+			Store:         &repoPostgre.OIDCRepository{DB: database.DB},
+			Accounts:      accounts,
+			Tokens:        tokenService,
+			MFA:           mfaService,
+			AutoProvision: oidcCfg.AutoProvision,
+			DefaultRole:   oidcCfg.DefaultRole,
+			StateTTL:      oidcCfg.StateTTL,
+		})
+	}
+
+	// reset password berlaku untuk akun Postgres maupun Mongo
+	mailCfg := config.LoadMail()
+	passwordService := &serviceIdentity.PasswordService{
+		Stores:    accountStores,
+		Tokens:    &repoPostgre.PasswordResetRepository{DB: database.DB},
+		Sessions:  tokenService.Repo,
+		Mailer:    utils.NewMailer(mailCfg),
+		PublicURL: mailCfg.PublicURL,
+		TTL:       authCfg.PasswordResetTTL,
+	}
+	routeIdentity.SetupPasswordRoutes(app, passwordService)
+
+	// data alumni & pekerjaan: satu API (/api/alumni, /api/pekerjaan), penyimpan dipilih lewat DATA_BACKEND
+	var alumniStore repoPostgre.AlumniRepositoryInterface = &repoPostgre.AlumniRepository{DB: database.DB}
+	var pekerjaanStore repoPostgre.PekerjaanRepositoryInterface = &repoPostgre.PekerjaanRepository{DB: database.DB}
+	if config.LoadData().Backend == "mongo" {
+		alumniStore = repoMongo.NewAlumniMongoDriver(database.MongoDB)
+		pekerjaanStore = repoMongo.NewPekerjaanMongoDriver(database.MongoDB)
+	}
+
+	// akun sendiri (/api/me), store dipilih dari klaim backend token
+	routeIdentity.SetupAccountRoutes(app, &serviceIdentity.AccountService{
+		Stores:         []serviceIdentity.ProfileStore{pgUsers, mongoUsers},
+		Alumni:         alumniStore,
+		Sessions:       tokenService.Repo,
+		Mailer:         utils.NewMailer(mailCfg),
+		PublicURL:      mailCfg.PublicURL,
+		Secret:         authCfg.LinkSecret,
+		EmailChangeTTL: authCfg.EmailVerifyTTL,
+		Guard:          loginGuard,
+		Audit:          audit,
+	}, authn)
 
 
 	// 7️ Register routes (Postgres + Mongo)
-	routeMongo.SetupPekerjaanMongoRoutes(app, database.MongoDB)
-	routeMongo.SetupAlumniMongoRoutes(app, database.MongoDB)
-	routePostgre.SetupRoutes(app, database.DB)
+	// /api/alumni-mongo & /api/pekerjaan-mongo lama tetap dipasang untuk klien yang belum pindah ke /api/alumni & /api/pekerjaan
+	// If-Match pada PUT / DELETE alumni & pekerjaan, sama untuk route baru dan -mongo
+	concurrencyCfg := config.LoadConcurrency()
+	routeMongo.SetupPekerjaanMongoRoutes(app, database.MongoDB, concurrencyCfg, authn, audit)
+	routeMongo.SetupAlumniMongoRoutes(app, database.MongoDB, concurrencyCfg, authn, audit)
+	routePostgre.SetupRoutes(app, database.DB, alumniStore, pekerjaanStore, tokenService, mfaService, loginGuard, passwordService, concurrencyCfg, authn, audit)
+
+	// replikasi alumni & pekerjaan Postgres → Mongo lewat outbox; relay bisa dimatikan per instance
+	outboxRepo := &repoPostgre.OutboxRepository{DB: database.DB}
+	replicationService := &servicePostgre.ReplicationService{Repo: outboxRepo, Audit: audit}
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	if replCfg := config.LoadReplication(); replCfg.Enabled {
+		replicationService.Relay = &servicePostgre.OutboxRelay{
+			Repo:        outboxRepo,
+			Replica:     repoMongo.NewReplicaMongoRepository(database.MongoDB),
+			BatchSize:   replCfg.BatchSize,
+			MaxAttempts: replCfg.MaxAttempts,
+			BaseBackoff: replCfg.BaseBackoff,
+			MaxBackoff:  replCfg.MaxBackoff,
+			Retention:   replCfg.Retention,
+		}
+		go replicationService.Relay.Run(relayCtx, replCfg.Interval)
+	}
+	routePostgre.SetupReplicationRoutes(app, replicationService, authn)
+	routePostgre.SetupConsistencyRoutes(app, &servicePostgre.ConsistencyService{
+		Checker: &servicePostgre.ConsistencyChecker{Stores: map[string]servicePostgre.ConsistencyStore{
+			"postgres": &repoPostgre.ReplicaRepository{DB: database.DB},
+			"mongo":    repoMongo.NewReplicaMongoRepository(database.MongoDB),
+		}},
+		Audit: audit,
+	}, authn)
 
 	// 8 Tambahkan fitur Upload File
-	app.Static("/uploads", "./uploads") // agar file bisa diakses langsung via URL
+	app.Static("/uploads", uploadDir) // agar file bisa diakses langsung via URL
 	uploadRepo := repoMongo.NewFileRepository(database.MongoDB)
-	uploadService := serviceMongo.NewFileService(uploadRepo, "./uploads")
-	routeMongo.SetupFileRoutes(app, uploadService)
+	uploadService := serviceMongo.NewFileService(uploadRepo, uploadDir, audit)
+	routeMongo.SetupFileRoutes(app, uploadService, authn)
 
 	// 9 Start server
 	port := os.Getenv("APP_PORT")
@@ -151,4 +322,5 @@ func main() {
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	stopRelay()
 }
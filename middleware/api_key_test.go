@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+type fakeAPIKeyStore struct {
+	keys    map[string]*models.APIKey
+	touched int
+}
+
+func (f *fakeAPIKeyStore) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	k, ok := f.keys[prefix]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return k, nil
+}
+
+func (f *fakeAPIKeyStore) TouchLastUsed(ctx context.Context, id int, ip string) error {
+	f.touched++
+	return nil
+}
+
+func TestAuthRequiredAcceptsAPIKey(t *testing.T) {
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeAPIKeyStore{keys: map[string]*models.APIKey{prefix: {
+		ID: 7, Name: "laporan", Prefix: prefix, KeyHash: utils.HashToken(key),
+		Scopes: []string{models.PermAlumniRead}, ExpiresAt: time.Now().Add(time.Hour),
+	}}}
+	auth := &Auth{APIKeys: store}
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendString(Claims(c).Subject) }
+	app.Get("/alumni", auth.Required(), Require(models.PermAlumniRead), ok)
+	app.Delete("/alumni", auth.Required(), Require(models.PermAlumniWrite), ok)
+
+	call := func(method, apiKey string) int {
+		req := httptest.NewRequest(method, "/alumni", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	if code := call("GET", key); code != 200 || store.touched != 1 {
+		t.Fatalf("expected 200 with last-used tracking, got %d (touched %d)", code, store.touched)
+	}
+	if code := call("DELETE", key); code != 403 {
+		t.Errorf("expected scope outside key to be forbidden, got %d", code)
+	}
+	if code := call("GET", key+"x"); code != 401 {
+		t.Errorf("expected tampered key to be rejected, got %d", code)
+	}
+
+	now := time.Now()
+	store.keys[prefix].RevokedAt = &now
+	if code := call("GET", key); code != 401 {
+		t.Errorf("expected revoked key to be rejected, got %d", code)
+	}
+}
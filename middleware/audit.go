@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+// AuditStore diimplementasikan repository.AuditRepository
+type AuditStore interface {
+	Insert(ctx context.Context, e *models.AuditEntry) error
+}
+
+// Auditor mencatat perubahan data ke audit_logs. Dibuat sekali di main lalu di-inject ke service / route
+// yang mengubah data, baik Postgres maupun Mongo. Auditor nil tidak mencatat apa pun (mis. di test).
+type Auditor struct {
+	Store AuditStore
+}
+
+// AuditJSON mengubah nilai before / after menjadi JSON untuk audit_logs; nil kalau kosong atau gagal di-marshal.
+// Dipakai juga CLI admin yang mencatat audit tanpa request HTTP.
+func AuditJSON(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+func auditEntry(c *fiber.Ctx, action, entityType, entityID string, before, after interface{}) *models.AuditEntry {
+	claims := Claims(c)
+	role := claims.Role
+	if claims.Backend == BackendAPIKey {
+		role = BackendAPIKey
+	}
+	return &models.AuditEntry{
+		Actor:         claims.Subject,
+		ActorUsername: claims.Username,
+		Role:          role,
+		IP:            c.IP(),
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityID,
+		Before:        AuditJSON(before),
+		After:         AuditJSON(after),
+	}
+}
+
+// Log mencatat perubahan data oleh user pemilik request. before / after di-marshal ke JSON
+// (field json:"-" seperti hash password tidak ikut); nil untuk create / delete.
+//
+// Best-effort: dipanggil setelah perubahan berhasil dan di luar transaksinya, jadi kalau insert gagal
+// (hanya di-log) atau proses mati di antaranya, perubahan tetap tersimpan tanpa entri audit. before
+// dibaca tanpa lock sebelum perubahan, jadi bisa berbeda dari baris yang benar-benar ditimpa kalau ada
+// penulis lain di antaranya (kecuali request memakai If-Match). Handler yang sudah memakai UnitOfWork
+// sebaiknya memakai LogInTx.
+func (a *Auditor) Log(c *fiber.Ctx, action, entityType, entityID string, before, after interface{}) {
+	if a == nil || a.Store == nil {
+		return
+	}
+	if err := a.Store.Insert(c.UserContext(), auditEntry(c, action, entityType, entityID, before, after)); err != nil {
+		log.Printf("gagal mencatat audit %s %s/%s: %v", action, entityType, entityID, err)
+	}
+}
+
+// LogInTx sama dengan Log tapi ditulis lewat ctx dari UnitOfWork.Do, jadi entri audit ikut commit /
+// rollback bersama perubahannya. Error dikembalikan supaya transaksi dibatalkan kalau audit gagal ditulis.
+func (a *Auditor) LogInTx(ctx context.Context, c *fiber.Ctx, action, entityType, entityID string, before, after interface{}) error {
+	if a == nil || a.Store == nil {
+		return nil
+	}
+	return a.Store.Insert(ctx, auditEntry(c, action, entityType, entityID, before, after))
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+type fakeAuditStore struct {
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (f *fakeAuditStore) Insert(ctx context.Context, e *models.AuditEntry) error {
+	f.entries = append(f.entries, e)
+	return f.err
+}
+
+type auditedThing struct {
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+}
+
+func TestAuditRecordsActorAndSnapshots(t *testing.T) {
+	store := &fakeAuditStore{}
+	audit := &Auditor{Store: store}
+
+	app := fiber.New()
+	app.Put("/thing", func(c *fiber.Ctx) error {
+		claims := &models.JWTClaims{UserID: "1", Username: "budi", Role: models.RoleOperatorProdi, Backend: models.BackendPostgres}
+		claims.Subject = "postgres:1"
+		c.Locals("claims", claims)
+		var missing *auditedThing
+		audit.Log(c, models.AuditUpdate, models.AuditEntityAlumni, "7",
+			auditedThing{Name: "lama", Secret: "hash"}, &auditedThing{Name: "baru"})
+		audit.Log(c, models.AuditDelete, models.AuditEntityAlumni, "8", missing, nil)
+		return c.SendStatus(204)
+	})
+	app.Test(httptest.NewRequest("PUT", "/thing", nil))
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(store.entries))
+	}
+	e := store.entries[0]
+	if e.Actor != "postgres:1" || e.ActorUsername != "budi" || e.Role != models.RoleOperatorProdi || e.IP == "" {
+		t.Errorf("unexpected actor info %+v", e)
+	}
+	if string(e.Before) != `{"name":"lama"}` || string(e.After) != `{"name":"baru"}` {
+		t.Errorf("unexpected snapshots before=%s after=%s", e.Before, e.After)
+	}
+	if strings.Contains(string(e.Before), "hash") {
+		t.Error("field json:\"-\" tidak boleh masuk audit log")
+	}
+	// pointer nil tidak dicatat sebagai "null"
+	if store.entries[1].Before != nil || store.entries[1].After != nil {
+		t.Errorf("expected empty snapshots, got %s / %s", store.entries[1].Before, store.entries[1].After)
+	}
+}
+
+func TestAuditFailureDoesNotBreakRequest(t *testing.T) {
+	audit := &Auditor{Store: &fakeAuditStore{err: errors.New("db down")}}
+
+	app := fiber.New()
+	app.Post("/thing", func(c *fiber.Ctx) error {
+		audit.Log(c, models.AuditCreate, models.AuditEntityRole, "x", nil, fiber.Map{"a": 1})
+		return c.SendStatus(201)
+	})
+	resp, _ := app.Test(httptest.NewRequest("POST", "/thing", nil))
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+}
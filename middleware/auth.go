@@ -1,16 +1,65 @@
 package middleware
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
 	"go_clean/utils"
 )
 
-func AuthRequired() fiber.Handler {
+// SessionStore dipakai Auth.Required untuk menolak access token yang sesinya
+// (family refresh token) sudah dicabut, dan mencatat aktivitas terakhir sesi.
+type SessionStore interface {
+	SessionActivity(ctx context.Context, id string) (revoked bool, lastSeen time.Time, err error)
+	TouchSession(ctx context.Context, id string, meta models.SessionMeta) error
+}
+
+// sessionTouchInterval membatasi update last_seen_at supaya tidak menulis ke DB tiap request
+const sessionTouchInterval = time.Minute
+
+// Auth memasang autentikasi request (Bearer JWT / X-API-Key). Dibuat sekali di main lalu diteruskan
+// ke setiap Setup*Routes, jadi semua route group memakai store yang sama sejak didaftarkan.
+type Auth struct {
+	// Sessions wajib diisi: token yang terikat sesi ditolak kalau status sesinya tidak bisa dicek
+	Sessions SessionStore
+	// Permissions memetakan role token ke permission yang dicek Require
+	Permissions *PermissionCache
+	// APIKeys mengaktifkan header X-API-Key; nil = semua API key ditolak
+	APIKeys APIKeyStore
+}
+
+// SessionMeta mengambil info perangkat dari request untuk dicatat di sesi
+func SessionMeta(c *fiber.Ctx) models.SessionMeta {
+	ua := c.Get(fiber.HeaderUserAgent)
+	if len(ua) > 255 {
+		ua = ua[:255]
+	}
+	return models.SessionMeta{UserAgent: ua, IP: c.IP()}
+}
+
+// Required menolak request tanpa token / API key yang valid
+func (a *Auth) Required() fiber.Handler {
+	return a.authenticate(false)
+}
+
+// AllowUnverified sama dengan Required tapi tetap menerima token akun
+// yang emailnya belum diverifikasi. Hanya untuk route seperti logout yang
+// harus bisa dipakai sebelum verifikasi.
+func (a *Auth) AllowUnverified() fiber.Handler {
+	return a.authenticate(true)
+}
+
+func (a *Auth) authenticate(allowUnverified bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		auth := c.Get("Authorization")
 		if auth == "" {
+			// integrasi / job terjadwal memakai API key, bukan akun manusia
+			if key := c.Get("X-API-Key"); key != "" {
+				return a.authenticateAPIKey(c, key)
+			}
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "butuh token"})
 		}
 		parts := strings.Split(auth, " ")
@@ -21,20 +70,44 @@ func AuthRequired() fiber.Handler {
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token invalid/expired"})
 		}
+		// token tanpa sesi (family refresh token) tidak bisa dicabut lewat logout, jadi ditolak
+		if claims.FamilyID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token invalid/expired"})
+		}
+		if a.Sessions == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal cek status token"})
+		}
+		revoked, lastSeen, err := a.Sessions.SessionActivity(c.UserContext(), claims.FamilyID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "gagal cek status token"})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "sesi sudah dicabut"})
+		}
+		if time.Since(lastSeen) > sessionTouchInterval {
+			_ = a.Sessions.TouchSession(c.UserContext(), claims.FamilyID, SessionMeta(c))
+		}
+		if claims.Unverified && !allowUnverified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "email belum diverifikasi"})
+		}
+		c.Locals("claims", claims)
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("backend", claims.Backend)
+		c.Locals("subject", claims.Subject)
+		c.Locals("family_id", claims.FamilyID)
+		c.Locals("permissions", a.Permissions.Role(c.UserContext(), claims.Role))
 		return c.Next()
 	}
 }
 
-func AdminOnly() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		role, _ := c.Locals("role").(string)
-		if role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "admin only"})
-		}
-		return c.Next()
+// Claims mengambil klaim JWT yang dipasang Auth.Required. Pakai ini daripada
+// menebak tipe c.Locals("user_id"): cek Backend, lalu mis. claims.PostgresUserID().
+func Claims(c *fiber.Ctx) *models.JWTClaims {
+	claims, _ := c.Locals("claims").(*models.JWTClaims)
+	if claims == nil {
+		return &models.JWTClaims{}
 	}
+	return claims
 }
-
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/config"
+)
+
+var (
+	ErrIfMatchRequired = errors.New("header If-Match wajib diisi dengan ETag dari GET")
+	ErrIfMatchInvalid  = errors.New("header If-Match tidak valid")
+)
+
+// ETag = version data sebagai strong ETag, mis. "3"
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetETag dipanggil handler GET by id dan respons 412 supaya client tahu versi terbaru
+func SetETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, ETag(version))
+}
+
+// IfMatch membaca version yang diharapkan client dari header If-Match. models.AnyVersion = tanpa syarat:
+// If-Match "*", atau header kosong kalau cfg tidak mewajibkan If-Match. "0" tetap dikembalikan sebagai 0. ETag lemah (W/"3") diterima karena proxy yang
+// mengompres respons sering mengubah ETag menjadi lemah. Daftar beberapa ETag tidak didukung.
+func IfMatch(c *fiber.Ctx, cfg config.ConcurrencyConfig) (int, error) {
+	h := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	switch {
+	case h == "" && cfg.RequireIfMatch:
+		return models.AnyVersion, ErrIfMatchRequired
+	case h == "" || h == "*":
+		return models.AnyVersion, nil
+	}
+	h = strings.TrimPrefix(h, "W/")
+	if len(h) < 2 || h[0] != '"' || h[len(h)-1] != '"' {
+		return models.AnyVersion, ErrIfMatchInvalid
+	}
+	v, err := strconv.Atoi(h[1 : len(h)-1])
+	if err != nil || v < 0 {
+		return models.AnyVersion, ErrIfMatchInvalid
+	}
+	return v, nil
+}
+
+// IfMatchStatus memetakan error IfMatch ke status HTTP: 428 kalau header wajib tapi tidak dikirim, selain itu 400
+func IfMatchStatus(err error) int {
+	if err == ErrIfMatchRequired {
+		return fiber.StatusPreconditionRequired
+	}
+	return fiber.StatusBadRequest
+}
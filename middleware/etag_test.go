@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/config"
+)
+
+func TestIfMatch(t *testing.T) {
+	var cfg config.ConcurrencyConfig
+	app := fiber.New()
+	app.Put("/", func(c *fiber.Ctx) error {
+		v, err := IfMatch(c, cfg)
+		if err != nil {
+			return c.SendStatus(IfMatchStatus(err))
+		}
+		SetETag(c, v)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	cases := []struct {
+		required bool
+		header   string
+		status   int
+		etag     string
+	}{
+		{false, "", 204, ETag(models.AnyVersion)},
+		{true, "", 428, ""},
+		{true, "*", 204, ETag(models.AnyVersion)},
+		// "0" = ETag dokumen Mongo lama tanpa version, tetap syarat (bukan tanpa syarat)
+		{true, `"0"`, 204, `"0"`},
+		{true, `"3"`, 204, `"3"`},
+		{true, `W/"4"`, 204, `"4"`},
+		{true, "3", 400, ""},
+		{true, `"abc"`, 400, ""},
+		{false, `"-1"`, 400, ""},
+	}
+	for _, tc := range cases {
+		cfg.RequireIfMatch = tc.required
+		req := httptest.NewRequest("PUT", "/", nil)
+		if tc.header != "" {
+			req.Header.Set("If-Match", tc.header)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status || resp.Header.Get("ETag") != tc.etag {
+			t.Errorf("required=%v If-Match %q: status %d etag %q, want %d %q",
+				tc.required, tc.header, resp.StatusCode, resp.Header.Get("ETag"), tc.status, tc.etag)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/config"
+)
+
+// AttemptState adalah catatan percobaan login gagal untuk satu kunci ("user:<identifier>|<ip>", "account:<identifier>",
+// "ip:<ip>", "mfa:<subject>")
+type AttemptState struct {
+	Failures    int       `json:"failures"`
+	Lockouts    int       `json:"lockouts"`
+	WindowStart time.Time `json:"window_start"`
+	LastFailure time.Time `json:"last_failure"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// AttemptStore menyimpan state percobaan login. Update harus atomik per kunci supaya
+// nanti bisa diganti store bersama (mis. Redis) tanpa mengubah LoginGuard.
+type AttemptStore interface {
+	Get(key string) (AttemptState, bool)
+	Update(key string, fn func(s *AttemptState)) AttemptState
+	Delete(key string) bool
+	All() map[string]AttemptState
+}
+
+// MemoryAttemptStore cukup untuk satu instance; state hilang saat restart
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	entries   map[string]*AttemptState
+	idleTTL   time.Duration
+	lastPrune time.Time
+}
+
+func NewMemoryAttemptStore(idleTTL time.Duration) *MemoryAttemptStore {
+	return &MemoryAttemptStore{entries: map[string]*AttemptState{}, idleTTL: idleTTL}
+}
+
+func (m *MemoryAttemptStore) Get(key string) (AttemptState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.entries[key]
+	if !ok {
+		return AttemptState{}, false
+	}
+	return *s, true
+}
+
+func (m *MemoryAttemptStore) Update(key string, fn func(s *AttemptState)) AttemptState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(time.Now())
+	s, ok := m.entries[key]
+	if !ok {
+		s = &AttemptState{}
+		m.entries[key] = s
+	}
+	fn(s)
+	return *s
+}
+
+func (m *MemoryAttemptStore) Delete(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.entries[key]
+	delete(m.entries, key)
+	return ok
+}
+
+func (m *MemoryAttemptStore) All() map[string]AttemptState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(time.Now())
+	out := make(map[string]AttemptState, len(m.entries))
+	for k, s := range m.entries {
+		out[k] = *s
+	}
+	return out
+}
+
+// prune membuang entri yang sudah lama tidak gagal dan tidak sedang dikunci (maks sekali per menit)
+func (m *MemoryAttemptStore) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for k, s := range m.entries {
+		if now.After(s.LockedUntil) && now.Sub(s.LastFailure) > m.idleTTL {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// LoginGuard membatasi tebak password:
+//   - per identifier + IP: jeda eksponensial setelah tiap gagal, lalu dikunci setelah MaxFailures
+//     (durasi kunci berlipat tiap kali terkunci lagi, maks MaxLockout). Kunci ini tidak berlaku
+//     untuk IP lain, jadi satu penyerang tidak bisa lama-lama mengunci pemilik akun.
+//   - per identifier dari semua IP: dikunci AccountLockout setelah AccountMaxFailures, supaya
+//     penyerang dengan banyak IP tidak mendapat jatah MaxFailures penuh dari tiap IP
+//   - per IP: maksimal IPMaxFailures gagal dalam IPWindow, lintas identifier
+//
+// Semua method aman dipanggil pada *LoginGuard nil (fitur nonaktif).
+type LoginGuard struct {
+	Store AttemptStore
+	Cfg   config.LoginGuardConfig
+	// now bisa diganti di test
+	now func() time.Time
+}
+
+func NewLoginGuard(cfg config.LoginGuardConfig, store AttemptStore) *LoginGuard {
+	return &LoginGuard{Store: store, Cfg: cfg, now: time.Now}
+}
+
+// LoginBlock menjelaskan kenapa percobaan ditolak. Now = jam guard saat dicek, dasar Retry-After.
+type LoginBlock struct {
+	Key   string
+	Until time.Time
+	Now   time.Time
+}
+
+// UserAttemptKey dipakai untuk password, per identifier dari satu IP
+func UserAttemptKey(identifier, ip string) string {
+	return UserAttemptPrefix(identifier) + ip
+}
+
+// UserAttemptPrefix mencakup semua UserAttemptKey milik identifier (dari IP mana pun)
+func UserAttemptPrefix(identifier string) string {
+	return "user:" + normalizeIdentifier(identifier) + "|"
+}
+
+// AccountAttemptKey menghitung gagal password untuk identifier dari semua IP
+func AccountAttemptKey(identifier string) string {
+	return "account:" + normalizeIdentifier(identifier)
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func IPAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// MFAAttemptKey dipakai untuk kode 2FA, per akun (subject "<backend>:<id>")
+func MFAAttemptKey(subject string) string {
+	return "mfa:" + subject
+}
+
+func (g *LoginGuard) check(key string) *LoginBlock {
+	s, ok := g.Store.Get(key)
+	if !ok {
+		return nil
+	}
+	now := g.now()
+	until := s.NextAttempt
+	if s.LockedUntil.After(until) {
+		until = s.LockedUntil
+	}
+	if now.Before(until) {
+		return &LoginBlock{Key: key, Until: until, Now: now}
+	}
+	return nil
+}
+
+// Check dipanggil sebelum kode dicek, mis. dengan MFAAttemptKey. Untuk password pakai CheckLogin.
+func (g *LoginGuard) Check(keys ...string) *LoginBlock {
+	if g == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if b := g.check(key); b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// Fail mencatat percobaan gagal untuk key dan IP client
+func (g *LoginGuard) Fail(key, ip string) {
+	if g == nil {
+		return
+	}
+	now := g.now()
+	g.Store.Update(key, func(s *AttemptState) {
+		s.Failures++
+		s.LastFailure = now
+		if s.Failures >= g.Cfg.MaxFailures {
+			s.Lockouts++
+			s.LockedUntil = now.Add(backoff(g.Cfg.Lockout, s.Lockouts, g.Cfg.MaxLockout))
+			return
+		}
+		s.NextAttempt = now.Add(backoff(g.Cfg.BaseDelay, s.Failures, g.Cfg.MaxDelay))
+	})
+	if ip == "" {
+		return
+	}
+	g.Store.Update(IPAttemptKey(ip), func(s *AttemptState) {
+		if now.Sub(s.WindowStart) > g.Cfg.IPWindow {
+			s.WindowStart, s.Failures = now, 0
+		}
+		s.Failures++
+		s.LastFailure = now
+		if s.Failures >= g.Cfg.IPMaxFailures {
+			s.LockedUntil = s.WindowStart.Add(g.Cfg.IPWindow)
+		}
+	})
+}
+
+// CheckLogin dipanggil sebelum password dicek: kunci identifier dari IP ini dan kunci akun dari semua IP
+func (g *LoginGuard) CheckLogin(identifier, ip string) *LoginBlock {
+	return g.Check(UserAttemptKey(identifier, ip), AccountAttemptKey(identifier))
+}
+
+// FailLogin mencatat password salah untuk identifier dari ip, untuk akun itu dan untuk IP client
+func (g *LoginGuard) FailLogin(identifier, ip string) {
+	if g == nil {
+		return
+	}
+	g.Fail(UserAttemptKey(identifier, ip), ip)
+	if g.Cfg.AccountMaxFailures <= 0 {
+		return
+	}
+	now := g.now()
+	g.Store.Update(AccountAttemptKey(identifier), func(s *AttemptState) {
+		s.Failures++
+		s.LastFailure = now
+		if s.Failures >= g.Cfg.AccountMaxFailures {
+			s.Lockouts++
+			s.Failures = 0
+			s.LockedUntil = now.Add(backoff(g.Cfg.AccountLockout, s.Lockouts, g.Cfg.MaxLockout))
+		}
+	})
+}
+
+// SucceedLogin menghapus hitungan gagal identifier, dari IP ini maupun total akun
+func (g *LoginGuard) SucceedLogin(identifier, ip string) {
+	if g == nil {
+		return
+	}
+	g.Store.Delete(UserAttemptKey(identifier, ip))
+	g.Store.Delete(AccountAttemptKey(identifier))
+}
+
+// Succeed menghapus hitungan gagal untuk key (IP tidak direset, supaya satu akun valid
+// tidak bisa dipakai untuk "mencuci" percobaan ke akun lain)
+func (g *LoginGuard) Succeed(key string) {
+	if g == nil {
+		return
+	}
+	g.Store.Delete(key)
+}
+
+// backoff = base * 2^(n-1), dibatasi max
+func backoff(base time.Duration, n int, max time.Duration) time.Duration {
+	d := time.Duration(float64(base) * math.Pow(2, float64(n-1)))
+	if d > max || d <= 0 {
+		return max
+	}
+	return d
+}
+
+// Respond menulis 429 + Retry-After
+func (b *LoginBlock) Respond(c *fiber.Ctx) error {
+	secs := int(b.Until.Sub(b.Now).Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, fmt.Sprint(secs))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": fmt.Sprintf("terlalu banyak percobaan login, coba lagi dalam %d detik", secs),
+	})
+}
+
+// LoginThrottle menolak request login dari IP yang sudah melewati batas gagal
+func LoginThrottle(g *LoginGuard) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if g == nil {
+			return c.Next()
+		}
+		if b := g.check(IPAttemptKey(c.IP())); b != nil {
+			return b.Respond(c)
+		}
+		return c.Next()
+	}
+}
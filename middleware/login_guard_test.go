@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/config"
+)
+
+func TestLoginGuardBackoffAndLockout(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	g := NewLoginGuard(config.LoginGuardConfig{
+		MaxFailures:   3,
+		BaseDelay:     time.Second,
+		MaxDelay:      30 * time.Second,
+		Lockout:       15 * time.Minute,
+		MaxLockout:    24 * time.Hour,
+		IPMaxFailures: 4,
+		IPWindow:      time.Minute,
+	}, NewMemoryAttemptStore(time.Hour))
+	g.now = func() time.Time { return now }
+	key := UserAttemptKey(" Admin ", "10.0.0.1")
+
+	g.Fail(key, "10.0.0.1")
+	if b := g.Check(key); b == nil || !b.Until.Equal(now.Add(time.Second)) {
+		t.Fatalf("expected 1s backoff, got %+v", b)
+	}
+	now = now.Add(time.Second)
+	g.Fail(key, "10.0.0.1")
+	if b := g.Check(UserAttemptKey("admin", "10.0.0.1")); b == nil || !b.Until.Equal(now.Add(2*time.Second)) {
+		t.Fatalf("expected 2s backoff, got %+v", b)
+	}
+
+	now = now.Add(2 * time.Second)
+	g.Fail(key, "10.0.0.1")
+	if b := g.Check(key); b == nil || !b.Until.Equal(now.Add(15*time.Minute)) {
+		t.Fatalf("expected lockout after 3 failures, got %+v", b)
+	}
+
+	// gagal lagi setelah kunci habis → dikunci dua kali lebih lama
+	now = now.Add(16 * time.Minute)
+	if b := g.Check(key); b != nil {
+		t.Fatalf("expected lockout to expire, got %+v", b)
+	}
+	g.Fail(key, "10.0.0.1")
+	if b := g.Check(key); b == nil || !b.Until.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("expected escalated lockout, got %+v", b)
+	}
+
+	g.Succeed(key)
+	if b := g.Check(key); b != nil {
+		t.Fatalf("expected success to clear, got %+v", b)
+	}
+}
+
+func TestLoginGuardIPWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	g := NewLoginGuard(config.LoginGuardConfig{
+		MaxFailures: 100, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond,
+		Lockout: time.Minute, MaxLockout: time.Hour,
+		IPMaxFailures: 3, IPWindow: time.Minute,
+	}, NewMemoryAttemptStore(time.Hour))
+	g.now = func() time.Time { return now }
+
+	for i, user := range []string{"a", "b", "c"} {
+		if b := g.Check(IPAttemptKey("10.0.0.9")); b != nil {
+			t.Fatalf("attempt %d: unexpected block %+v", i, b)
+		}
+		g.Fail(UserAttemptKey(user, "10.0.0.9"), "10.0.0.9")
+	}
+	if b := g.Check(IPAttemptKey("10.0.0.9")); b == nil {
+		t.Fatal("expected IP to be throttled across usernames")
+	}
+	now = now.Add(61 * time.Second)
+	if b := g.Check(IPAttemptKey("10.0.0.9")); b != nil {
+		t.Fatalf("expected window to reset, got %+v", b)
+	}
+}
+
+func TestLoginGuardLockoutIsPerIP(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	g := NewLoginGuard(config.LoginGuardConfig{
+		MaxFailures: 2, BaseDelay: time.Second, MaxDelay: time.Second,
+		Lockout: time.Hour, MaxLockout: time.Hour,
+		IPMaxFailures: 100, IPWindow: time.Minute,
+	}, NewMemoryAttemptStore(time.Hour))
+	g.now = func() time.Time { return now }
+
+	attacker := UserAttemptKey("admin", "203.0.113.7")
+	for i := 0; i < 2; i++ {
+		g.Fail(attacker, "203.0.113.7")
+		now = now.Add(2 * time.Second)
+	}
+	if b := g.Check(attacker); b == nil {
+		t.Fatal("expected attacker IP to be locked out")
+	}
+	// pemilik akun dari IP lain tetap bisa login
+	if b := g.Check(UserAttemptKey("admin", "10.0.0.1")); b != nil {
+		t.Fatalf("expected other IP to be unaffected, got %+v", b)
+	}
+}
+
+func TestLoginGuardAccountLockoutAcrossIPs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	g := NewLoginGuard(config.LoginGuardConfig{
+		MaxFailures: 3, BaseDelay: time.Second, MaxDelay: time.Second,
+		Lockout: time.Hour, MaxLockout: time.Hour,
+		IPMaxFailures: 100, IPWindow: time.Minute,
+		AccountMaxFailures: 4, AccountLockout: 5 * time.Minute,
+	}, NewMemoryAttemptStore(time.Hour))
+	g.now = func() time.Time { return now }
+
+	// tiap IP hanya mencoba sekali, jauh di bawah MaxFailures per IP
+	for i, ip := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+		if b := g.CheckLogin("admin", ip); b != nil {
+			t.Fatalf("attempt %d: unexpected block %+v", i, b)
+		}
+		g.FailLogin("admin", ip)
+	}
+	if b := g.CheckLogin("admin", "203.0.113.9"); b != nil {
+		t.Fatalf("expected account to stay open below AccountMaxFailures, got %+v", b)
+	}
+	g.FailLogin("admin", "203.0.113.4")
+	b := g.CheckLogin("admin", "10.0.0.1")
+	if b == nil || b.Key != AccountAttemptKey("admin") || !b.Until.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("expected account lockout from every IP, got %+v", b)
+	}
+
+	// Retry-After dihitung dari jam guard, bukan jam dinding
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return b.Respond(c) })
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusTooManyRequests || resp.Header.Get(fiber.HeaderRetryAfter) != "301" {
+		t.Fatalf("status %d Retry-After %q, want 429 301", resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+
+	// kunci akun berikutnya berlipat
+	now = now.Add(6 * time.Minute)
+	for i := 0; i < 4; i++ {
+		g.FailLogin("admin", "198.51.100.1")
+		now = now.Add(2 * time.Second)
+	}
+	if b := g.CheckLogin("admin", "10.0.0.1"); b == nil || !b.Until.Equal(now.Add(-2*time.Second).Add(10*time.Minute)) {
+		t.Fatalf("expected escalated account lockout, got %+v", b)
+	}
+
+	g.SucceedLogin("admin", "10.0.0.1")
+	if b := g.Check(AccountAttemptKey("admin")); b != nil {
+		t.Fatalf("expected success to clear account counter, got %+v", b)
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+// PermissionStore memetakan role ke daftar permission (tabel role_permissions)
+type PermissionStore interface {
+	RolePermissions(ctx context.Context, role string) ([]string, error)
+}
+
+type cachedPermissions struct {
+	perms   map[string]bool
+	expires time.Time
+}
+
+// PermissionCache supaya tiap request tidak query DB; perubahan role lewat admin API
+// langsung menghapus cache, instance lain menyusul paling lama setelah TTL.
+// Cache nil memakai models.DefaultRolePermissions.
+type PermissionCache struct {
+	store PermissionStore
+	ttl   time.Duration
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+}
+
+func NewPermissionCache(store PermissionStore, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{store: store, ttl: ttl, cache: map[string]cachedPermissions{}}
+}
+
+// Invalidate dipanggil setelah role / permission diubah
+func (p *PermissionCache) Invalidate() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache = map[string]cachedPermissions{}
+}
+
+func toSet(perms []string) map[string]bool {
+	set := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+// Role mengembalikan permission milik role; dipanggil Auth.Required untuk setiap request
+func (p *PermissionCache) Role(ctx context.Context, role string) map[string]bool {
+	role = models.NormalizeRole(role)
+	if p == nil || p.store == nil {
+		return toSet(models.DefaultRolePermissions[role])
+	}
+
+	p.mu.RLock()
+	cached, ok := p.cache[role]
+	p.mu.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.perms
+	}
+
+	perms, err := p.store.RolePermissions(ctx, role)
+	if err != nil {
+		// DB bermasalah: pakai cache lama kalau ada, selain itu tolak (fail closed)
+		log.Printf("gagal baca permission role %s: %v", role, err)
+		if ok {
+			return cached.perms
+		}
+		return map[string]bool{}
+	}
+	set := toSet(perms)
+	p.mu.Lock()
+	p.cache[role] = cachedPermissions{perms: set, expires: time.Now().Add(p.ttl)}
+	p.mu.Unlock()
+	return set
+}
+
+// Permissions mengembalikan permission milik user yang login (dipasang Auth.Required di Locals);
+// request yang tidak lewat Auth.Required tidak punya permission apa pun
+func Permissions(c *fiber.Ctx) map[string]bool {
+	perms, _ := c.Locals("permissions").(map[string]bool)
+	if perms == nil {
+		return map[string]bool{}
+	}
+	return perms
+}
+
+// HasPermission untuk cek di dalam handler (mis. aturan kepemilikan data)
+func HasPermission(c *fiber.Ctx, perm string) bool {
+	perms := Permissions(c)
+	return perms[models.PermAll] || perms[perm]
+}
+
+// Require: user harus punya semua permission yang disebut. Pasang setelah Auth.Required.
+func Require(perms ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, p := range perms {
+			if !HasPermission(c, p) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "tidak punya izin: " + p})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RequireAny: cukup salah satu permission (mis. pekerjaan:write atau pekerjaan:write_own)
+func RequireAny(perms ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, p := range perms {
+			if HasPermission(c, p) {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "tidak punya izin"})
+	}
+}
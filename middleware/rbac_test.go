@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+)
+
+type fakePermissionStore struct {
+	roles map[string][]string
+	calls int
+}
+
+func (f *fakePermissionStore) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	f.calls++
+	return f.roles[role], nil
+}
+
+func requireStatus(t *testing.T, cache *PermissionCache, role string, perms ...string) int {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Locals("permissions", cache.Role(c.UserContext(), role))
+		return c.Next()
+	}, Require(perms...), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestRequireUsesRolePermissions(t *testing.T) {
+	store := &fakePermissionStore{roles: map[string][]string{
+		models.RoleAdmin:  {models.PermAll},
+		models.RoleAlumni: {models.PermPekerjaanRead, models.PermPekerjaanWriteOwn},
+	}}
+	cache := NewPermissionCache(store, time.Minute)
+
+	if code := requireStatus(t, cache, models.RoleAdmin, models.PermPekerjaanHardDelete); code != 200 {
+		t.Errorf("expected admin wildcard to pass, got %d", code)
+	}
+	// role lama "user" dipetakan ke alumni
+	if code := requireStatus(t, cache, models.RoleLegacyUser, models.PermPekerjaanRead); code != 200 {
+		t.Errorf("expected legacy user role to read, got %d", code)
+	}
+	if code := requireStatus(t, cache, models.RoleAlumni, models.PermPekerjaanHardDelete); code != 403 {
+		t.Errorf("expected alumni hard delete to be forbidden, got %d", code)
+	}
+
+	// perubahan role lewat admin API langsung berlaku setelah cache dihapus
+	calls := store.calls
+	store.roles[models.RoleAlumni] = append(store.roles[models.RoleAlumni], models.PermPekerjaanHardDelete)
+	if code := requireStatus(t, cache, models.RoleAlumni, models.PermPekerjaanHardDelete); code != 403 || store.calls != calls {
+		t.Errorf("expected cached permissions, got %d (calls %d)", code, store.calls)
+	}
+	cache.Invalidate()
+	if code := requireStatus(t, cache, models.RoleAlumni, models.PermPekerjaanHardDelete); code != 200 {
+		t.Errorf("expected updated permissions after invalidate, got %d", code)
+	}
+}
+
+func TestRequireWithoutAuthIsForbidden(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", Require(models.PermAlumniRead), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 403 {
+		t.Fatalf("expected request without Auth.Required to be forbidden, got %d", resp.StatusCode)
+	}
+}
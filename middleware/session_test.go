@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go_clean/app/models/postgresql"
+	"go_clean/utils"
+)
+
+type fakeSessionStore struct {
+	revoked  map[string]bool
+	lastSeen time.Time
+	touched  []models.SessionMeta
+}
+
+func (f *fakeSessionStore) SessionActivity(ctx context.Context, id string) (bool, time.Time, error) {
+	return f.revoked[id], f.lastSeen, nil
+}
+
+func (f *fakeSessionStore) TouchSession(ctx context.Context, id string, meta models.SessionMeta) error {
+	f.touched = append(f.touched, meta)
+	f.lastSeen = time.Now()
+	return nil
+}
+
+func TestAuthRequiredRejectsRevokedSession(t *testing.T) {
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	store := &fakeSessionStore{revoked: map[string]bool{"fam-revoked": true}}
+	auth := &Auth{Sessions: store}
+
+	app := fiber.New()
+	app.Get("/me", auth.Required(), func(c *fiber.Ctx) error { return c.SendString(Claims(c).FamilyID) })
+
+	call := func(familyID string) int {
+		id := models.Identity{Backend: models.BackendPostgres, ID: "1", Username: "budi", Role: models.RoleAlumni, EmailVerified: true}
+		token, err := utils.SignClaims(utils.NewClaims(id, familyID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", "curl/8.0")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	if code := call(""); code != 401 {
+		t.Fatalf("expected token without session to be rejected, got %d", code)
+	}
+	if code := call("fam-revoked"); code != 401 {
+		t.Fatalf("expected revoked session to be rejected, got %d", code)
+	}
+	if code := call("fam-active"); code != 200 {
+		t.Fatalf("expected active session to pass, got %d", code)
+	}
+	if len(store.touched) != 1 || store.touched[0].UserAgent != "curl/8.0" {
+		t.Fatalf("expected last activity to be recorded once, got %+v", store.touched)
+	}
+	// aktivitas kurang dari sessionTouchInterval tidak ditulis ulang
+	call("fam-active")
+	if len(store.touched) != 1 {
+		t.Errorf("expected touch to be throttled, got %d writes", len(store.touched))
+	}
+}
+
+func TestAuthRequiredWithoutSessionStoreFailsClosed(t *testing.T) {
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	app := fiber.New()
+	app.Get("/me", (&Auth{}).Required(), func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	id := models.Identity{Backend: models.BackendPostgres, ID: "1", Username: "budi", Role: models.RoleAlumni, EmailVerified: true}
+	token, err := utils.SignClaims(utils.NewClaims(id, "fam-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	if resp.StatusCode != 500 {
+		t.Fatalf("expected token with session to be rejected without a session store, got %d", resp.StatusCode)
+	}
+}
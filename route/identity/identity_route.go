@@ -0,0 +1,65 @@
+package route
+
+import (
+	"go_clean/app/models/postgresql"
+	"go_clean/app/service/identity"
+	"go_clean/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupIdentityRoutes(app *fiber.App, store service.Store, tokens service.TokenIssuer, mfa *service.MFAService, guard *middleware.LoginGuard) {
+	identityService := &service.IdentityService{Store: store, Tokens: tokens, MFA: mfa, Guard: guard}
+
+	api := app.Group("/api")
+	// POST /api/login → login gabungan, backend dipilih lewat AUTH_BACKEND
+	api.Post("/login", middleware.LoginThrottle(guard), identityService.Login)
+}
+
+func SetupPasswordRoutes(app *fiber.App, passwordService *service.PasswordService) {
+	pw := app.Group("/api/password")
+	pw.Post("/forgot", passwordService.ForgotPassword)
+	pw.Post("/reset", passwordService.ResetPassword)
+}
+
+// SetupAccountRoutes: profil, ganti email & password akun sendiri (Postgres maupun Mongo)
+func SetupAccountRoutes(app *fiber.App, accountService *service.AccountService, authn *middleware.Auth) {
+	// link dari email, bisa dibuka tanpa login
+	app.Get("/api/verify-email-change", accountService.ConfirmEmailChange)
+
+	me := app.Group("/api/me", authn.Required(), middleware.RejectAPIKey())
+	me.Get("/", accountService.GetMe)
+	me.Patch("/", accountService.UpdateMe)
+	me.Post("/password", accountService.ChangePassword)
+	me.Get("/alumni", accountService.GetMyAlumni)
+}
+
+// SetupMFARoutes: langkah kedua login (pakai mfa_token) + kelola 2FA akun sendiri (pakai access token)
+func SetupMFARoutes(app *fiber.App, mfaService *service.MFAService, authn *middleware.Auth) {
+	api := app.Group("/api")
+	api.Post("/login/mfa", middleware.LoginThrottle(mfaService.Guard), mfaService.LoginMFA)
+	api.Post("/login/mfa/enroll", mfaService.LoginMFAEnroll)
+
+	mfa := api.Group("/mfa", authn.Required(), middleware.RejectAPIKey())
+	mfa.Get("/", mfaService.Status)
+	mfa.Post("/enroll", mfaService.Enroll)
+	mfa.Post("/confirm", mfaService.Confirm)
+	mfa.Post("/recovery-codes", mfaService.RegenerateRecoveryCodes)
+	mfa.Post("/disable", mfaService.Disable)
+}
+
+// SetupOIDCRoutes: login SSO lewat IdP kampus (hanya dipasang kalau OIDC_ISSUER diisi)
+func SetupOIDCRoutes(app *fiber.App, oidcService *service.OIDCService) {
+	oidc := app.Group("/api/oidc")
+	oidc.Get("/login", oidcService.OIDCLogin)
+	oidc.Get("/callback", oidcService.OIDCCallback)
+}
+
+// SetupLockoutRoutes: admin melihat & membuka lockout brute-force login
+func SetupLockoutRoutes(app *fiber.App, guard *middleware.LoginGuard, authn *middleware.Auth) {
+	lockoutService := &service.LockoutService{Guard: guard}
+
+	admin := app.Group("/api/admin/lockouts", authn.Required(), middleware.Require(models.PermSecurityManage))
+	admin.Get("/", lockoutService.ListLockouts)
+	admin.Delete("/:kind/:value", lockoutService.ClearLockout)
+}
@@ -2,11 +2,14 @@ package route
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go_clean/app/repository/mongodb"
 	"go_clean/app/service/mongodb"
+	"go_clean/config"
 	"go_clean/middleware"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,18 +17,19 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
+func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database, concurrency config.ConcurrencyConfig, authn *middleware.Auth, audit *middleware.Auditor) {
 	// 🔧 Inisialisasi repository & service
 	repo := repository.NewAlumniMongoRepository(mongoDB)
 	svc := service.NewAlumniMongoService(repo)
 
-	// 🧩 Semua endpoint butuh login (AuthRequired)
-	api := app.Group("/api/alumni-mongo", middleware.AuthRequired())
+	// 🧩 Semua endpoint butuh login (Auth.Required)
+	api := app.Group("/api/alumni-mongo", authn.Required())
 
-	// ========== READ (bisa diakses semua user login) ==========
+	// ========== READ (izin alumni:read) ==========
+	read := middleware.Require(pgModel.PermAlumniRead)
 
 	// GET /api/alumni-mongo → Ambil semua data alumni
-	api.Get("/", func(c *fiber.Ctx) error {
+	api.Get("/", read, func(c *fiber.Ctx) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -37,7 +41,7 @@ func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 	})
 
 	// GET /api/alumni-mongo/:id → Ambil 1 data alumni by ID
-	api.Get("/:id", func(c *fiber.Ctx) error {
+	api.Get("/:id", read, func(c *fiber.Ctx) error {
 		id := c.Params("id")
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -47,14 +51,15 @@ func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
+		middleware.SetETag(c, data.Version)
 		return c.JSON(data)
 	})
 
-	// ========== ADMIN ONLY (CREATE, UPDATE, DELETE) ==========
+	// ========== WRITE (izin alumni:write) ==========
 
-	admin := api.Group("", middleware.AdminOnly())
+	admin := api.Group("", middleware.Require(pgModel.PermAlumniWrite))
 
-	// POST /api/alumni-mongo → Tambah data (hanya admin)
+	// POST /api/alumni-mongo → Tambah data (izin alumni:write)
 	admin.Post("/", func(c *fiber.Ctx) error {
 		var input models.AlumniMongo
 		if err := c.BodyParser(&input); err != nil {
@@ -72,13 +77,18 @@ func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditCreate, pgModel.AuditEntityAlumniMongo, data.ID.Hex(), nil, data)
 
 		return c.Status(201).JSON(data)
 	})
 
-	// PUT /api/alumni-mongo/:id → Update data (hanya admin)
+	// PUT /api/alumni-mongo/:id → Update data (izin alumni:write)
 	admin.Put("/:id", func(c *fiber.Ctx) error {
 		id := c.Params("id")
+		version, err := middleware.IfMatch(c, concurrency)
+		if err != nil {
+			return c.Status(middleware.IfMatchStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
 		var input models.AlumniMongo
 
 		if err := c.BodyParser(&input); err != nil {
@@ -88,25 +98,49 @@ func SetupAlumniMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		data, err := svc.Update(ctx, id, &input)
+		// nilai lama untuk audit log
+		before, _ := svc.GetByID(ctx, id)
+		data, err := svc.Update(ctx, id, &input, version)
+		if errors.Is(err, repository.ErrNotMatched) {
+			cur, getErr := svc.GetByID(ctx, id)
+			if getErr != nil {
+				return notMatched(c, version, nil, 0, false)
+			}
+			return notMatched(c, version, cur, cur.Version, true)
+		}
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditUpdate, pgModel.AuditEntityAlumniMongo, id, before, data)
 
+		middleware.SetETag(c, data.Version)
 		return c.JSON(data)
 	})
 
-	// DELETE /api/alumni-mongo/:id → Hapus data (hanya admin)
+	// DELETE /api/alumni-mongo/:id → Hapus data (izin alumni:write)
 	admin.Delete("/:id", func(c *fiber.Ctx) error {
 		id := c.Params("id")
+		version, err := middleware.IfMatch(c, concurrency)
+		if err != nil {
+			return c.Status(middleware.IfMatchStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		err := svc.Delete(ctx, id)
+		before, _ := svc.GetByID(ctx, id)
+		err = svc.Delete(ctx, id, version)
+		if errors.Is(err, repository.ErrNotMatched) {
+			cur, getErr := svc.GetByID(ctx, id)
+			if getErr != nil {
+				return notMatched(c, version, nil, 0, false)
+			}
+			return notMatched(c, version, cur, cur.Version, true)
+		}
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditDelete, pgModel.AuditEntityAlumniMongo, id, before, nil)
 
 		return c.JSON(fiber.Map{"message": "Data alumni berhasil dihapus"})
 	})
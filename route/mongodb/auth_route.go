@@ -3,21 +3,25 @@ package route
 import (
 	"go_clean/app/repository/mongodb"
 	"go_clean/app/service/mongodb"
+	"go_clean/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupAuthMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
+func SetupAuthMongoRoutes(app *fiber.App, mongoDB *mongo.Database, tokens service.TokenIssuer, mfa service.MFAGate, guard *middleware.LoginGuard) {
 	// 🔧 inisialisasi repository & service
 	userRepo := repository.NewUserMongoRepository(mongoDB)
 	authService := &service.AuthMongoService{
-		Repo: userRepo,
+		Repo:   userRepo,
+		Tokens: tokens,
+		MFA:    mfa,
+		Guard:  guard,
 	}
 
 	// API Group tanpa middleware (login = public)
 	api := app.Group("/api")
 
 	// POST /api/login-mongo → login pakai MongoDB
-	api.Post("/login-mongo", authService.Login)
+	api.Post("/login-mongo", middleware.LoginThrottle(guard), authService.Login)
 }
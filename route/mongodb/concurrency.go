@@ -0,0 +1,23 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	pgModel "go_clean/app/models/postgresql"
+	"go_clean/middleware"
+)
+
+// notMatched = respons ketika Update / Delete tidak mengenai dokumen. current dibaca ulang setelah
+// operasi gagal: tidak ada = 404, versinya beda dari If-Match = 412 berisi data terbaru beserta ETag-nya.
+func notMatched(c *fiber.Ctx, want int, current interface{}, currentVersion int, found bool) error {
+	if !found {
+		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
+	}
+	if want != pgModel.AnyVersion && want != currentVersion {
+		middleware.SetETag(c, currentVersion)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "Data sudah diubah pengguna lain, muat ulang lalu coba lagi",
+			"data":  current,
+		})
+	}
+	return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
+}
@@ -1,15 +1,18 @@
 package route
 
 import (
+    pgModel "go_clean/app/models/postgresql"
     "go_clean/app/service/mongodb"
+    "go_clean/middleware"
     "github.com/gofiber/fiber/v2"
 )
 
-func SetupFileRoutes(app *fiber.App, service service.FileService) {
+func SetupFileRoutes(app *fiber.App, service service.FileService, authn *middleware.Auth) {
     api := app.Group("/api")
 
-    files := api.Group("/files")
-    files.Post("/upload", service.UploadFile)
+    // kepemilikan file dicek di service (files:read_all / files:delete_all untuk file orang lain)
+    files := api.Group("/files", authn.Required())
+    files.Post("/upload", middleware.Require(pgModel.PermFilesUpload), service.UploadFile)
     files.Get("/", service.GetAllFiles)
     files.Get("/:id", service.GetFileByID)
     files.Delete("/:id", service.DeleteFile)
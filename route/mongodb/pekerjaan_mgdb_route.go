@@ -2,27 +2,31 @@ package route
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
 	"go_clean/app/models/mongodb"
+	pgModel "go_clean/app/models/postgresql"
 	"go_clean/app/repository/mongodb"
 	"go_clean/app/service/mongodb"
+	"go_clean/config"
 	"go_clean/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
+func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database, concurrency config.ConcurrencyConfig, authn *middleware.Auth, audit *middleware.Auditor) {
 	repo := repository.NewPekerjaanMongoRepository(mongoDB)
 	svc := service.NewPekerjaanMongoService(repo)
 
 	// Semua endpoint butuh login
-	api := app.Group("/api/pekerjaan-mongo", middleware.AuthRequired())
+	api := app.Group("/api/pekerjaan-mongo", authn.Required())
 
-	// ========== READ (semua user login bisa) ==========
-	api.Get("/", func(c *fiber.Ctx) error {
+	// ========== READ (izin pekerjaan:read) ==========
+	read := middleware.Require(pgModel.PermPekerjaanRead)
+	api.Get("/", read, func(c *fiber.Ctx) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -33,7 +37,7 @@ func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		return c.JSON(data)
 	})
 
-	api.Get("/:id", func(c *fiber.Ctx) error {
+	api.Get("/:id", read, func(c *fiber.Ctx) error {
 		id := c.Params("id")
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -42,14 +46,13 @@ func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
+		middleware.SetETag(c, data.Version)
 		return c.JSON(data)
 	})
 
-	// ========== ADMIN ONLY (READ + WRITE) ==========
-	admin := api.Group("", middleware.AdminOnly())
-
-	// GET /api/pekerjaan-mongo/alumni/:alumni_id → Admin only
-	admin.Get("/alumni/:alumni_id", func(c *fiber.Ctx) error {
+	// ========== WRITE (izin pekerjaan:write) + baca per alumni (pekerjaan:read_all) ==========
+	// GET /api/pekerjaan-mongo/alumni/:alumni_id
+	api.Get("/alumni/:alumni_id", middleware.Require(pgModel.PermPekerjaanReadAll), func(c *fiber.Ctx) error {
 		id, _ := strconv.Atoi(c.Params("alumni_id"))
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -61,7 +64,9 @@ func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		return c.JSON(data)
 	})
 
-	// POST → Tambah data (hanya admin)
+	admin := api.Group("", middleware.Require(pgModel.PermPekerjaanWrite))
+
+	// POST → Tambah data
 	admin.Post("/", func(c *fiber.Ctx) error {
 		var input models.PekerjaanMongo
 		if err := c.BodyParser(&input); err != nil {
@@ -75,12 +80,17 @@ func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditCreate, pgModel.AuditEntityPekerjaanMongo, result.ID.Hex(), nil, result)
 		return c.Status(201).JSON(result)
 	})
 
-	// PUT → Update data (hanya admin)
+	// PUT → Update data
 	admin.Put("/:id", func(c *fiber.Ctx) error {
 		id := c.Params("id")
+		version, err := middleware.IfMatch(c, concurrency)
+		if err != nil {
+			return c.Status(middleware.IfMatchStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
 		var input models.PekerjaanMongo
 
 		if err := c.BodyParser(&input); err != nil {
@@ -90,22 +100,47 @@ func SetupPekerjaanMongoRoutes(app *fiber.App, mongoDB *mongo.Database) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		result, err := svc.Update(ctx, id, &input)
+		// nilai lama untuk audit log
+		before, _ := svc.GetByID(ctx, id)
+		result, err := svc.Update(ctx, id, &input, version)
+		if errors.Is(err, repository.ErrNotMatched) {
+			cur, getErr := svc.GetByID(ctx, id)
+			if getErr != nil {
+				return notMatched(c, version, nil, 0, false)
+			}
+			return notMatched(c, version, cur, cur.Version, true)
+		}
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditUpdate, pgModel.AuditEntityPekerjaanMongo, id, before, result)
+		middleware.SetETag(c, result.Version)
 		return c.JSON(result)
 	})
 
-	// DELETE → Hapus data (hanya admin)
+	// DELETE → Hapus data
 	admin.Delete("/:id", func(c *fiber.Ctx) error {
 		id := c.Params("id")
+		version, err := middleware.IfMatch(c, concurrency)
+		if err != nil {
+			return c.Status(middleware.IfMatchStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := svc.Delete(ctx, id); err != nil {
+		before, _ := svc.GetByID(ctx, id)
+		err = svc.Delete(ctx, id, version)
+		if errors.Is(err, repository.ErrNotMatched) {
+			cur, getErr := svc.GetByID(ctx, id)
+			if getErr != nil {
+				return notMatched(c, version, nil, 0, false)
+			}
+			return notMatched(c, version, cur, cur.Version, true)
+		}
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
+		audit.Log(c, pgModel.AuditDelete, pgModel.AuditEntityPekerjaanMongo, id, before, nil)
 		return c.JSON(fiber.Map{"message": "Data berhasil dihapus"})
 	})
 }
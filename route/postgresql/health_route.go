@@ -0,0 +1,16 @@
+package route
+
+import (
+	"go_clean/app/models/postgresql"
+	"go_clean/app/service/postgresql"
+	"go_clean/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupHealthRoutes memasang probe orchestrator (/healthz, /readyz, tanpa login) dan diagnosa admin
+func SetupHealthRoutes(app *fiber.App, healthService *service.HealthService, authn *middleware.Auth) {
+	app.Get("/healthz", healthService.Liveness)
+	app.Get("/readyz", healthService.Readiness)
+	app.Get("/api/admin/diagnostics", authn.Required(), middleware.Require(models.PermDiagnosticsRead), healthService.GetDiagnostics)
+}
@@ -2,30 +2,68 @@ package route
 
 import (
 	"database/sql"
+
+	"go_clean/app/models/postgresql"
 	"go_clean/app/repository/postgresql"
 	"go_clean/app/service/postgresql"
+	"go_clean/config"
 	"go_clean/middleware"
+	"go_clean/utils"
 
 	"github.com/gofiber/fiber/v2"
 	// "go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRoutes(app *fiber.App, db *sql.DB) {
+// SetupRoutes memasang route akun, admin dan data. alumniRepo & pekerjaanRepo adalah driver penyimpan data
+// alumni/pekerjaan (Postgres atau Mongo, dipilih lewat DATA_BACKEND); sisanya selalu di Postgres.
+// tokenService, authn & audit dibuat di main dan dipakai bersama route identity / Mongo.
+func SetupRoutes(app *fiber.App, db *sql.DB, alumniRepo repository.AlumniRepositoryInterface, pekerjaanRepo repository.PekerjaanRepositoryInterface,
+	tokenService *service.TokenService, mfa service.MFAGate, guard *middleware.LoginGuard, resets service.PasswordResetSender,
+	concurrency config.ConcurrencyConfig, authn *middleware.Auth, audit *middleware.Auditor) {
 	// =======================
 	// REPOSITORIES (Postgres)
 	// =======================
-	alumniRepo := &repository.AlumniRepository{DB: db}
-	pekerjaanRepo := &repository.PekerjaanRepository{DB: db}
-
 	authRepo := &repository.AuthRepository{DB: db}
+	userRepo := &repository.UserRepository{DB: db}
+	refreshRepo := &repository.RefreshTokenRepository{DB: db}
+	sessionRepo := &repository.SessionRepository{DB: db}
+	roleRepo := &repository.RoleRepository{DB: db}
+	apiKeyRepo := &repository.APIKeyRepository{DB: db}
+	auditRepo := &repository.AuditRepository{DB: db}
 
 	// =======================
 	// SERVICES
 	// =======================
-	alumniService := &service.AlumniService{Repo: alumniRepo}
-	pekerjaanService := &service.PekerjaanService{Repo: pekerjaanRepo}
-	authService := &service.AuthService{Repo: authRepo}
-	// userService := &service.UserService{Repo: userRepo}
+	// If-Match pada PUT / DELETE alumni & pekerjaan, sama dengan route -mongo
+	alumniService := &service.AlumniService{Repo: alumniRepo, Concurrency: concurrency, Audit: audit}
+	pekerjaanService := &service.PekerjaanService{Repo: pekerjaanRepo, Users: userRepo, Concurrency: concurrency, Audit: audit}
+	authCfg := config.LoadAuth()
+	mailCfg := config.LoadMail()
+	sessionService := &service.SessionService{Repo: sessionRepo, Revoker: refreshRepo}
+	verificationService := &service.VerificationService{
+		Auth:      authRepo,
+		Users:     userRepo,
+		Sessions:  refreshRepo,
+		Mailer:    utils.NewMailer(mailCfg),
+		PublicURL: mailCfg.PublicURL,
+		Secret:    authCfg.LinkSecret,
+		TTL:       authCfg.EmailVerifyTTL,
+		Audit:     audit,
+	}
+	authService := &service.AuthService{Repo: authRepo, Tokens: tokenService, Verification: verificationService, MFA: mfa, Guard: guard, Audit: audit}
+	roleService := &service.RoleService{Repo: roleRepo, Permissions: authn.Permissions, Audit: audit}
+	apiKeyService := &service.APIKeyService{Repo: apiKeyRepo, Audit: audit}
+	auditService := &service.AuditService{Repo: auditRepo}
+	userService := &service.UserService{Repo: userRepo, Auth: authRepo, Sessions: refreshRepo, Resets: resets, Audit: audit}
+	// onboarding selalu ke Postgres (satu transaksi), tidak memakai alumniRepo / pekerjaanRepo DATA_BACKEND
+	onboardingService := &service.OnboardingService{
+		UoW:       &repository.UnitOfWork{DB: db},
+		Alumni:    &repository.AlumniRepository{DB: db},
+		Pekerjaan: &repository.PekerjaanRepository{DB: db},
+		Accounts:  authRepo,
+		Users:     userRepo,
+		Audit:     audit,
+	}
 
 	// =======================
 	// ROOT
@@ -36,45 +74,90 @@ func SetupRoutes(app *fiber.App, db *sql.DB) {
 	// PUBLIC
 	// =======================
 	api := app.Group("/api")
-	api.Post("/login-postgre", authService.LoginUser)
+	api.Post("/login-postgre", middleware.LoginThrottle(guard), authService.LoginUser)
 	api.Post("/register-postgre", authService.RegisterUser)
+	api.Post("/token/refresh", tokenService.Refresh)
+	api.Get("/verify-email", verificationService.VerifyEmail)
+	api.Post("/verify-email/resend", verificationService.ResendVerification)
 
 	// =======================
 	// PROTECTED
 	// =======================
-	auth := api.Group("", middleware.AuthRequired())
-	auth.Post("/register-admin", middleware.AdminOnly(), authService.AdminCreateUser)
-	auth.Get("/pekerjaan-pag", pekerjaanService.GetPekerjaanList)
-	auth.Get("/alumni-pag", alumniService.GetAlumniList)
+	// logout didaftarkan sebelum group auth: akun yang belum verifikasi email tetap boleh logout
+	api.Post("/logout", authn.AllowUnverified(), tokenService.Logout)
+	auth := api.Group("", authn.Required())
+	auth.Post("/register-admin", middleware.Require(models.PermUsersManage), authService.AdminCreateUser)
+
+	adminUsers := auth.Group("/admin/users", middleware.Require(models.PermUsersManage))
+	adminUsers.Get("/", userService.GetUsersService)
+	adminUsers.Get("/:id", userService.GetUserDetail)
+	adminUsers.Delete("/:id", userService.DeleteUser)
+	adminUsers.Put("/:id/role", userService.UpdateUserRole)
+	adminUsers.Post("/:id/disable", userService.DisableUser)
+	adminUsers.Post("/:id/enable", userService.EnableUser)
+	adminUsers.Put("/:id/alumni", userService.LinkUserAlumni)
+	adminUsers.Delete("/:id/alumni", userService.UnlinkUserAlumni)
+	adminUsers.Post("/:id/reset-password", userService.AdminResetPassword)
+	adminUsers.Post("/:id/resend-verification", verificationService.AdminResendVerification)
+	adminUsers.Post("/:id/verify", verificationService.AdminVerifyUser)
+	adminUsers.Get("/:id/sessions", sessionService.AdminListSessions)
+	adminUsers.Delete("/:id/sessions", sessionService.AdminRevokeSessions)
+
+	// sesi login milik sendiri (API key tidak punya sesi)
+	sessions := auth.Group("/sessions", middleware.RejectAPIKey())
+	sessions.Get("/", sessionService.ListSessions)
+	sessions.Delete("/", sessionService.RevokeAllSessions)
+	sessions.Delete("/:id", sessionService.RevokeSession)
+
+	// role & permission dikelola lewat API, tanpa redeploy
+	auth.Get("/admin/permissions", middleware.Require(models.PermRolesManage), roleService.ListPermissions)
+	roles := auth.Group("/admin/roles", middleware.Require(models.PermRolesManage))
+	roles.Get("/", roleService.ListRoles)
+	roles.Post("/", roleService.CreateRole)
+	roles.Get("/:name", roleService.GetRole)
+	roles.Put("/:name", roleService.UpdateRole)
+	roles.Delete("/:name", roleService.DeleteRole)
+
+	// API key untuk job / integrasi (header X-API-Key)
+	apiKeys := auth.Group("/admin/api-keys", middleware.RejectAPIKey(), middleware.Require(models.PermAPIKeysManage))
+	apiKeys.Get("/", apiKeyService.ListAPIKeys)
+	apiKeys.Post("/", apiKeyService.CreateAPIKey)
+	apiKeys.Delete("/:id", apiKeyService.RevokeAPIKey)
+
+	auth.Get("/admin/audit-logs", middleware.Require(models.PermAuditRead), auditService.ListAuditLogs)
+	auth.Post("/admin/onboarding", middleware.Require(models.PermUsersManage, models.PermAlumniWrite), onboardingService.Onboard)
+
+	auth.Get("/pekerjaan-pag", middleware.Require(models.PermPekerjaanRead), pekerjaanService.GetPekerjaanList)
+	auth.Get("/alumni-pag", middleware.Require(models.PermAlumniRead), alumniService.GetAlumniList)
 
 	// =======================
-	// ALUMNI ROUTES (Postgres)
+	// ALUMNI ROUTES (DATA_BACKEND)
 	// =======================
+	alumniRead := middleware.Require(models.PermAlumniRead)
+	alumniWrite := middleware.Require(models.PermAlumniWrite)
 	alumni := auth.Group("/alumni")
-	alumni.Get("/", alumniService.GetAllAlumni)
-	alumni.Get("/:id", alumniService.GetAlumniByID)
-	alumni.Get("/angkatan/:angkatan", alumniService.GetAlumniByAngkatan)
-	
-	alumni.Get("/with-pekerjaan/:nim", alumniService.GetAlumniAndPekerjaan)
-
-	alumniAdmin := alumni.Group("", middleware.AdminOnly())
-	alumniAdmin.Post("/", alumniService.CreateAlumni)
-	alumniAdmin.Put("/:id", alumniService.UpdateAlumni)
-	alumniAdmin.Delete("/:id", alumniService.DeleteAlumni)
+	alumni.Get("/", alumniRead, alumniService.GetAllAlumni)
+	alumni.Get("/:id", alumniRead, alumniService.GetAlumniByID)
+	alumni.Get("/angkatan/:angkatan", alumniRead, alumniService.GetAlumniByAngkatan)
+	alumni.Get("/with-pekerjaan/:nim", alumniRead, middleware.Require(models.PermPekerjaanRead), alumniService.GetAlumniAndPekerjaan)
+	alumni.Post("/", alumniWrite, alumniService.CreateAlumni)
+	alumni.Put("/:id", alumniWrite, alumniService.UpdateAlumni)
+	alumni.Delete("/:id", alumniWrite, alumniService.DeleteAlumni)
 
 	// =======================
-	// PEKERJAAN ROUTES (Postgres)
+	// PEKERJAAN ROUTES (DATA_BACKEND)
 	// =======================
+	pkjRead := middleware.Require(models.PermPekerjaanRead)
+	// kepemilikan data (write_own) dicek di service
+	pkjModify := middleware.RequireAny(models.PermPekerjaanWrite, models.PermPekerjaanWriteOwn)
 	pkj := auth.Group("/pekerjaan")
-	pkj.Get("/trash", pekerjaanService.TrashAllPekerjaan)
-	pkj.Get("/", pekerjaanService.GetAllPekerjaan)
-	pkj.Get("/:id", pekerjaanService.GetPekerjaanByID)
-	pkj.Get("/alumni/:alumni_id", pekerjaanService.GetPekerjaanByAlumniID)
-	pkj.Put("/:id", pekerjaanService.UpdatePekerjaan)
-	pkj.Put("/restore/:id", pekerjaanService.RestorePekerjaan)
-	pkj.Delete("/:id", pekerjaanService.DeletePekerjaan)
-	pkj.Delete("/hard-delete/:id", pekerjaanService.HardDeletePekerjaan)
-	pkjAdmin := pkj.Group("", middleware.AdminOnly())
-	pkjAdmin.Post("/", pekerjaanService.CreatePekerjaan)
-	
+	pkj.Get("/trash", pkjRead, pekerjaanService.TrashAllPekerjaan)
+	pkj.Get("/", pkjRead, pekerjaanService.GetAllPekerjaan)
+	pkj.Get("/:id", pkjRead, pekerjaanService.GetPekerjaanByID)
+	pkj.Get("/alumni/:alumni_id", pkjRead, pekerjaanService.GetPekerjaanByAlumniID)
+	pkj.Post("/", middleware.Require(models.PermPekerjaanWrite), pekerjaanService.CreatePekerjaan)
+	pkj.Put("/:id", pkjModify, pekerjaanService.UpdatePekerjaan)
+	pkj.Put("/restore/:id", pkjModify, pekerjaanService.RestorePekerjaan)
+	pkj.Delete("/:id", pkjModify, pekerjaanService.DeletePekerjaan)
+	pkj.Delete("/hard-delete/:id", middleware.Require(models.PermPekerjaanHardDelete), pekerjaanService.HardDeletePekerjaan)
 }
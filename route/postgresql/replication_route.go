@@ -0,0 +1,24 @@
+package route
+
+import (
+	"go_clean/app/models/postgresql"
+	"go_clean/app/service/postgresql"
+	"go_clean/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupReplicationRoutes memasang endpoint admin replikasi Postgres → Mongo (outbox)
+func SetupReplicationRoutes(app *fiber.App, replicationService *service.ReplicationService, authn *middleware.Auth) {
+	replication := app.Group("/api/admin/replication", authn.Required(), middleware.Require(models.PermReplicationManage))
+	replication.Get("/", replicationService.GetReplicationStatus)
+	replication.Get("/dead", replicationService.ListDeadEvents)
+	replication.Post("/dead/:id/retry", replicationService.RetryDeadEvent)
+}
+
+// SetupConsistencyRoutes memasang pengecekan & perbaikan konsistensi data Postgres ↔ MongoDB
+func SetupConsistencyRoutes(app *fiber.App, consistencyService *service.ConsistencyService, authn *middleware.Auth) {
+	consistency := app.Group("/api/admin/consistency", authn.Required(), middleware.Require(models.PermReplicationManage))
+	consistency.Get("/", consistencyService.CheckConsistency)
+	consistency.Post("/repair", consistencyService.RepairConsistency)
+}
@@ -0,0 +1,18 @@
+package utils
+
+import "os"
+
+// CheckWritableDir memastikan dir ada (dibuat kalau belum, sama seperti upload) dan bisa ditulisi
+// dengan membuat lalu menghapus file sementara
+func CheckWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
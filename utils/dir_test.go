@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritableDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	if err := CheckWritableDir(dir); err != nil {
+		t.Fatalf("dir baru: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("file sementara tertinggal: %d", len(entries))
+	}
+
+	file := filepath.Join(t.TempDir(), "bukan-dir")
+	os.WriteFile(file, []byte("x"), 0o644)
+	if err := CheckWritableDir(file); err == nil {
+		t.Fatal("path berupa file harus error")
+	}
+}
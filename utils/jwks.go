@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SigningKey adalah satu key di manifest JWT_KEYS_FILE.
+// Key dipakai untuk sign selama NotBefore <= now dan now + umur access token < NotAfter (belum ada key aktif
+// yang lebih baru), jadi setiap token yang ditandatanganinya sudah kedaluwarsa sebelum key berhenti dipakai
+// verifikasi (dan hilang dari JWKS) di NotAfter.
+type SigningKey struct {
+	KID       string
+	Alg       string
+	Private   crypto.Signer
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// Public mengembalikan public key untuk verifikasi
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+type keyManifestEntry struct {
+	KID        string    `json:"kid"`
+	Alg        string    `json:"alg"`
+	PrivateKey string    `json:"private_key"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+}
+
+type KeySet struct {
+	keys     []SigningKey
+	tokenTTL time.Duration
+}
+
+// LoadKeySet membaca manifest JSON berisi daftar key. Path private_key relatif terhadap folder manifest.
+// tokenTTL = umur access token; key berhenti dipakai sign tokenTTL sebelum NotAfter.
+func LoadKeySet(path string, tokenTTL time.Duration) (*KeySet, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []keyManifestEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("manifest key tidak valid: %w", err)
+	}
+
+	ks := &KeySet{tokenTTL: tokenTTL}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.KID == "" || seen[e.KID] {
+			return nil, fmt.Errorf("kid kosong atau duplikat: %q", e.KID)
+		}
+		seen[e.KID] = true
+		if e.NotAfter.Sub(e.NotBefore) <= tokenTTL {
+			return nil, fmt.Errorf("key %s: not_after harus lebih dari %s setelah not_before", e.KID, tokenTTL)
+		}
+
+		keyPath := e.PrivateKey
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+		signer, err := readPrivateKey(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", e.KID, err)
+		}
+		if err := checkAlg(e.Alg, signer); err != nil {
+			return nil, fmt.Errorf("key %s: %w", e.KID, err)
+		}
+		ks.keys = append(ks.keys, SigningKey{
+			KID: e.KID, Alg: e.Alg, Private: signer, NotBefore: e.NotBefore, NotAfter: e.NotAfter,
+		})
+	}
+	if len(ks.keys) == 0 {
+		return nil, errors.New("manifest key kosong")
+	}
+
+	// urutkan terbaru dulu supaya Active cukup ambil yang pertama cocok
+	sort.Slice(ks.keys, func(i, j int) bool { return ks.keys[i].NotBefore.After(ks.keys[j].NotBefore) })
+	return ks, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("file bukan PEM")
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tipe private key tidak didukung")
+	}
+	return signer, nil
+}
+
+func checkAlg(alg string, signer crypto.Signer) error {
+	switch signer.(type) {
+	case *rsa.PrivateKey:
+		if alg != "RS256" {
+			return fmt.Errorf("key RSA harus alg RS256, bukan %q", alg)
+		}
+	case ed25519.PrivateKey:
+		if alg != "EdDSA" {
+			return fmt.Errorf("key Ed25519 harus alg EdDSA, bukan %q", alg)
+		}
+	default:
+		return errors.New("hanya RSA dan Ed25519 yang didukung")
+	}
+	return nil
+}
+
+// Active mengembalikan key untuk sign token baru pada waktu now; token yang dibuat harus masih bisa
+// diverifikasi sampai kedaluwarsa, jadi key yang tinggal kurang dari tokenTTL tidak dipilih lagi
+func (ks *KeySet) Active(now time.Time) (*SigningKey, error) {
+	for i := range ks.keys {
+		k := &ks.keys[i]
+		if !now.Before(k.NotBefore) && now.Add(ks.tokenTTL).Before(k.NotAfter) {
+			return k, nil
+		}
+	}
+	return nil, errors.New("tidak ada signing key aktif")
+}
+
+// Lookup mencari key verifikasi berdasarkan kid. Key yang belum aktif ikut diterima
+// supaya instance dengan jam sedikit terlambat tetap bisa verifikasi token dari key baru.
+func (ks *KeySet) Lookup(kid string, now time.Time) (*SigningKey, bool) {
+	for i := range ks.keys {
+		k := &ks.keys[i]
+		if k.KID == kid && now.Before(k.NotAfter) {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// JWK mengikuti RFC 7517 (hanya field public)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS berisi semua key yang belum kedaluwarsa, termasuk yang dijadwalkan aktif nanti
+func (ks *KeySet) JWKS(now time.Time) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		if !now.Before(k.NotAfter) {
+			continue
+		}
+		jwk := JWK{Kid: k.KID, Use: "sig", Alg: k.Alg}
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// PublicKey mengubah JWK (RSA / Ed25519) kembali menjadi public key, dipakai verifikasi id_token IdP
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("key OKP tidak valid")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("kty %q tidak didukung", j.Kty)
+}
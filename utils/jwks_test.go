@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeTestKeySet(t *testing.T, base time.Time) string {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	for name, k := range map[string]interface{}{"old.pem": rsaKey, "new.pem": edKey} {
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(filepath.Join(dir, name), pemBytes, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest, _ := json.Marshal([]map[string]interface{}{
+		{"kid": "old", "alg": "RS256", "private_key": "old.pem", "not_before": base, "not_after": base.Add(48 * time.Hour)},
+		{"kid": "new", "alg": "EdDSA", "private_key": "new.pem", "not_before": base.Add(24 * time.Hour), "not_after": base.Add(96 * time.Hour)},
+	})
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, manifest, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeySetRotation(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ks, err := LoadKeySet(writeTestKeySet(t, base), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	k, _ := ks.Active(base.Add(time.Hour))
+	if k.KID != "old" {
+		t.Errorf("expected old key active before rotation, got %v", k.KID)
+	}
+	// key baru sudah dipublish di JWKS sebelum mulai dipakai sign
+	if n := len(ks.JWKS(base.Add(time.Hour)).Keys); n != 2 {
+		t.Errorf("expected 2 published keys, got %v", n)
+	}
+
+	afterRotation := base.Add(30 * time.Hour)
+	k, _ = ks.Active(afterRotation)
+	if k.KID != "new" {
+		t.Errorf("expected new key active after rotation, got %v", k.KID)
+	}
+	if _, ok := ks.Lookup("old", afterRotation); !ok {
+		t.Errorf("old key should still verify until not_after")
+	}
+
+	afterExpiry := base.Add(50 * time.Hour)
+	if _, ok := ks.Lookup("old", afterExpiry); ok {
+		t.Errorf("old key should not verify after not_after")
+	}
+	if n := len(ks.JWKS(afterExpiry).Keys); n != 1 {
+		t.Errorf("expected expired key removed from JWKS, got %v keys", n)
+	}
+}
+
+// token yang ditandatangani tepat sebelum key berhenti sign harus tetap bisa diverifikasi sampai kedaluwarsa
+func TestKeySetStopsSigningBeforeNotAfter(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ks, err := LoadKeySet(writeTestKeySet(t, base), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	notAfter := base.Add(96 * time.Hour)
+	last := notAfter.Add(-time.Hour - time.Second)
+	k, err := ks.Active(last)
+	if err != nil || k.KID != "new" {
+		t.Fatalf("expected new key still signing at %v, got %v, %v", last, k, err)
+	}
+	if _, ok := ks.Lookup(k.KID, last.Add(time.Hour)); !ok {
+		t.Errorf("token signed at %v must verify until it expires", last)
+	}
+	if _, err := ks.Active(notAfter.Add(-30 * time.Minute)); err == nil {
+		t.Errorf("expected no signing key within the last access TTL before not_after")
+	}
+}
+
+func TestLoadKeySetRejectsWindowShorterThanTTL(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := LoadKeySet(writeTestKeySet(t, base), 48*time.Hour); err == nil {
+		t.Errorf("expected error for key window not longer than access TTL")
+	}
+}
+
+func TestKeySetSignAndVerify(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	ks, err := LoadKeySet(writeTestKeySet(t, base), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, _ := ks.Active(time.Now())
+	tok := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), jwt.MapClaims{"sub": "1"})
+	tok.Header["kid"] = key.KID
+	signed, err := tok.SignedString(key.Private)
+	if err != nil {
+		t.Fatalf("sign error: %v", err)
+	}
+
+	parsed, err := jwt.Parse(signed, func(t *jwt.Token) (interface{}, error) {
+		k, _ := ks.Lookup(t.Header["kid"].(string), time.Now())
+		return k.Public(), nil
+	})
+	if err != nil || !parsed.Valid {
+		t.Errorf("expected valid token, got %v", err)
+	}
+}
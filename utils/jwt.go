@@ -1,76 +1,148 @@
 package utils
 
 import (
+	"fmt"
+	"log"
+	"sync"
 	"time"
 	"strconv"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	pgModel "go_clean/app/models/postgresql"
-    mongoModel "go_clean/app/models/mongodb"
 
 	"go_clean/config"
 )
 
-var MockGenerateToken func(u pgModel.User) (string, error)
-
-func GenerateToken(u pgModel.User) (string, error) {
-
-    if MockGenerateToken != nil {
-        return MockGenerateToken(u)
-    }
-
-    jwtCfg := config.LoadJWT()
-    claims := pgModel.JWTClaims{
-        UserID:   strconv.Itoa(u.ID),   // Convert int → string
-        Username: u.Username,
-        Role:     u.Role,
-        RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtCfg.TTLHours) * time.Hour)),
-            IssuedAt:  jwt.NewNumericDate(time.Now()),
-        },
-    }
-
-    tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return tok.SignedString(jwtCfg.Secret)
+// NewClaims menyusun klaim access token dari Identity. familyID mengikat token ke rantai
+// refresh token supaya bisa dicabut lewat logout / reuse detection.
+func NewClaims(id pgModel.Identity, familyID string) pgModel.JWTClaims {
+	jwtCfg := config.LoadJWT()
+	now := time.Now()
+	return pgModel.JWTClaims{
+		UserID:   id.ID,
+		Username: id.Username,
+		Role:     id.Role,
+		Backend:  id.Backend,
+		FamilyID: familyID,
+
+		Unverified: !id.EmailVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   id.Subject(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(jwtCfg.AccessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
 }
 
-var MockGenerateTokenMongo func(u mongoModel.LoginMongo) (string, error)
+var (
+	keySetOnce sync.Once
+	keySet     *KeySet
+	keySetErr  error
+)
 
-func GenerateTokenMongo(u mongoModel.LoginMongo) (string, error) {
+// loadedKeySet membaca JWT_KEYS_FILE sekali. nil berarti mode HS256 (shared secret).
+func loadedKeySet() (*KeySet, error) {
+	keySetOnce.Do(func() {
+		if cfg := config.LoadJWT(); cfg.KeysFile != "" {
+			keySet, keySetErr = LoadKeySet(cfg.KeysFile, cfg.AccessTTL)
+		}
+	})
+	return keySet, keySetErr
+}
 
-     if MockGenerateTokenMongo != nil {
-        return MockGenerateTokenMongo(u)
-    }
+// MustLoadSigningKeys dipanggil saat startup supaya manifest key yang rusak langsung ketahuan
+func MustLoadSigningKeys() {
+	ks, err := loadedKeySet()
+	if err != nil {
+		log.Fatalf("Gagal memuat JWT_KEYS_FILE: %v", err)
+	}
+	if ks == nil {
+		log.Println("⚠️  JWT_KEYS_FILE tidak diset, token ditandatangani HS256 (shared secret)")
+		return
+	}
+	if _, err := ks.Active(time.Now()); err != nil {
+		log.Fatalf("JWT_KEYS_FILE: %v", err)
+	}
+}
 
-    jwtCfg := config.LoadJWT()
+// PublicJWKS dipakai endpoint /.well-known/jwks.json. Kosong kalau masih mode HS256.
+func PublicJWKS() JWKSet {
+	ks, _ := loadedKeySet()
+	if ks == nil {
+		return JWKSet{Keys: []JWK{}}
+	}
+	return ks.JWKS(time.Now())
+}
 
-    claims := pgModel.JWTClaims{
-        UserID:   u.ID.Hex(),
-        Username: u.Username,
-        Role:     u.Role,
-        RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtCfg.TTLHours) * time.Hour)),
-            IssuedAt:  jwt.NewNumericDate(time.Now()),
-        },
-    }
+func SignClaims(claims pgModel.JWTClaims) (string, error) {
+	ks, err := loadedKeySet()
+	if err != nil {
+		return "", err
+	}
+	if ks == nil {
+		jwtCfg := config.LoadJWT()
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return tok.SignedString(jwtCfg.Secret)
+	}
 
-    tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return tok.SignedString(jwtCfg.Secret)
+	key, err := ks.Active(time.Now())
+	if err != nil {
+		return "", err
+	}
+	tok := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims)
+	tok.Header["kid"] = key.KID
+	return tok.SignedString(key.Private)
 }
 
 func ValidateToken(tokenStr string) (*pgModel.JWTClaims, error) {
-	jwtCfg := config.LoadJWT()
-	tok, err := jwt.ParseWithClaims(tokenStr, &pgModel.JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return jwtCfg.Secret, nil
-	})
+	ks, err := loadedKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	var tok *jwt.Token
+	if ks == nil {
+		jwtCfg := config.LoadJWT()
+		tok, err = jwt.ParseWithClaims(tokenStr, &pgModel.JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
+			return jwtCfg.Secret, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	} else {
+		tok, err = jwt.ParseWithClaims(tokenStr, &pgModel.JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			key, ok := ks.Lookup(kid, time.Now())
+			if !ok {
+				return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+			}
+			// cegah alg confusion: alg di header harus sama dengan alg key
+			if t.Method.Alg() != key.Alg {
+				return nil, fmt.Errorf("alg %q tidak cocok untuk kid %q", t.Method.Alg(), kid)
+			}
+			return key.Public(), nil
+		}, jwt.WithValidMethods([]string{"RS256", "EdDSA"}))
+	}
 	if err != nil {
 		return nil, err
 	}
 	if claims, ok := tok.Claims.(*pgModel.JWTClaims); ok && tok.Valid {
+		normalizeLegacyClaims(claims)
 		return claims, nil
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
 
-
-
-
+// normalizeLegacyClaims mengisi backend/sub untuk token lama yang terbit sebelum klaim backend ada.
+// Satu-satunya tempat yang masih "menebak": user_id numerik = Postgres, selain itu Mongo.
+func normalizeLegacyClaims(c *pgModel.JWTClaims) {
+	if c.Backend != "" {
+		return
+	}
+	if _, err := strconv.Atoi(c.UserID); err == nil {
+		c.Backend = pgModel.BackendPostgres
+	} else {
+		c.Backend = pgModel.BackendMongo
+	}
+	if c.Subject == "" {
+		c.Subject = c.Backend + ":" + c.UserID
+	}
+}
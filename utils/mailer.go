@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go_clean/config"
+)
+
+// Mailer mengirim email plain text
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer memilih implementasi sesuai MAIL_DRIVER
+func NewMailer(cfg config.MailConfig) Mailer {
+	if cfg.Driver == "smtp" {
+		return &SMTPMailer{
+			Host: cfg.SMTPHost, Port: cfg.SMTPPort,
+			Username: cfg.SMTPUser, Password: cfg.SMTPPass, From: cfg.From,
+		}
+	}
+	return &OutboxMailer{Dir: cfg.OutboxDir, From: cfg.From}
+}
+
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(addr, auth, m.From, []string{to}, buildMessage(m.From, to, subject, body))
+}
+
+// outboxKeep = jumlah email terakhir yang diingat OutboxMailer; semua email tetap ada sebagai file di Dir
+const outboxKeep = 100
+
+// OutboxMailer menulis setiap email sebagai file .eml di Dir, untuk development dan test.
+// Di memori hanya outboxKeep email terakhir yang disimpan supaya proses yang lama hidup tidak terus membesar.
+type OutboxMailer struct {
+	Dir  string
+	From string
+
+	mu   sync.Mutex
+	sent []OutboxMessage
+}
+
+type OutboxMessage struct {
+	To      string
+	Subject string
+	Body    string
+	Path    string
+}
+
+func (m *OutboxMailer) Send(to, subject, body string) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitizeFileName(to))
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, buildMessage(m.From, to, subject, body), 0o644); err != nil {
+		return err
+	}
+
+	msg := OutboxMessage{To: to, Subject: subject, Body: body, Path: path}
+	m.mu.Lock()
+	if len(m.sent) < outboxKeep {
+		m.sent = append(m.sent, msg)
+	} else {
+		copy(m.sent, m.sent[1:])
+		m.sent[len(m.sent)-1] = msg
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// Sent mengembalikan salinan email yang masih diingat, dari yang terlama
+func (m *OutboxMailer) Sent() []OutboxMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]OutboxMessage(nil), m.sent...)
+}
+
+// Last mengembalikan email terakhir yang terkirim (berguna di test)
+func (m *OutboxMailer) Last() (OutboxMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		return OutboxMessage{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestOutboxMailerKeepsLastMessages(t *testing.T) {
+	m := &OutboxMailer{Dir: t.TempDir()}
+	for i := 0; i < outboxKeep+5; i++ {
+		if err := m.Send(fmt.Sprintf("user%d@mail.com", i), "Halo", "isi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sent := m.Sent()
+	if len(sent) != outboxKeep {
+		t.Fatalf("len(Sent) = %d, want %d", len(sent), outboxKeep)
+	}
+	if sent[0].To != "user5@mail.com" {
+		t.Fatalf("email terlama = %s, want user5@mail.com", sent[0].To)
+	}
+	if last, _ := m.Last(); last.To != fmt.Sprintf("user%d@mail.com", outboxKeep+4) {
+		t.Fatalf("Last = %s", last.To)
+	}
+	// file .eml tetap ditulis semua
+	if files, _ := os.ReadDir(m.Dir); len(files) != outboxKeep+5 {
+		t.Fatalf("file di outbox = %d", len(files))
+	}
+}
@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCClaims adalah isi id_token yang dipakai untuk memetakan user IdP ke akun lokal
+type OIDCClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClient menjalankan authorization code flow + PKCE terhadap satu IdP.
+// Discovery dan JWKS diambil saat pertama dipakai, jadi server tetap bisa start walau IdP sedang down.
+type OIDCClient struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTP         *http.Client
+
+	mu          sync.Mutex
+	meta        *oidcDiscovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+func NewOIDCClient(issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCClient {
+	return &OIDCClient{
+		Issuer:       strings.TrimRight(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		HTTP:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewPKCE membuat code_verifier dan code_challenge (S256)
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (o *OIDCClient) getJSON(ctx context.Context, u string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+func (o *OIDCClient) discover(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.meta != nil {
+		return o.meta, nil
+	}
+	var meta oidcDiscovery
+	if err := o.getJSON(ctx, o.Issuer+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("discovery OIDC gagal: %w", err)
+	}
+	if meta.Issuer != o.Issuer {
+		return nil, fmt.Errorf("issuer discovery %q tidak sama dengan %q", meta.Issuer, o.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("dokumen discovery OIDC tidak lengkap")
+	}
+	o.meta = &meta
+	return o.meta, nil
+}
+
+// AuthCodeURL membentuk URL redirect ke halaman login IdP
+func (o *OIDCClient) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	meta, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.ClientID},
+		"redirect_uri":          {o.RedirectURL},
+		"scope":                 {strings.Join(o.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token lalu memverifikasi id_token-nya
+func (o *OIDCClient) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCClaims, error) {
+	meta, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.RedirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {o.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client confidential memakai client_secret_basic; client publik cukup PKCE
+	if o.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	}
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("respons token endpoint tidak valid: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("token endpoint menolak: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("respons token tanpa id_token")
+	}
+	return o.VerifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// VerifyIDToken mengecek tanda tangan (JWKS IdP), iss, aud, exp dan nonce
+func (o *OIDCClient) VerifyIDToken(ctx context.Context, raw, nonce string) (*OIDCClaims, error) {
+	meta, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := &OIDCClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return o.publicKey(ctx, meta.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(o.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("id_token tidak valid: nonce tidak cocok")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token tidak valid: sub kosong")
+	}
+	return claims, nil
+}
+
+// publicKey mencari key berdasarkan kid; JWKS diambil ulang (maks sekali per menit) kalau kid belum dikenal,
+// supaya rotasi key di IdP langsung terbaca
+func (o *OIDCClient) publicKey(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if k, ok := o.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(o.keysFetched) < time.Minute && o.keys != nil {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+
+	var set JWKSet
+	if err := o.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("gagal mengambil JWKS IdP: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		if pub, err := j.PublicKey(); err == nil {
+			keys[j.Kid] = pub
+		}
+	}
+	o.keys, o.keysFetched = keys, time.Now()
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+}
@@ -0,0 +1,230 @@
+// Package oidcmock adalah IdP OpenID Connect tiruan untuk development dan test.
+// Halaman /authorize langsung menyetujui login (user dipilih lewat login_hint), tanpa form.
+package oidcmock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go_clean/utils"
+)
+
+// User adalah akun di IdP tiruan
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+}
+
+type authCode struct {
+	user        User
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	expires     time.Time
+}
+
+type Provider struct {
+	// Issuer harus sama persis dengan URL tempat Handler dilayani
+	Issuer   string
+	ClientID string
+	// ClientSecret kosong = client publik (cukup PKCE)
+	ClientSecret string
+	Users        map[string]User
+	DefaultUser  string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+const keyID = "mock-1"
+
+func New(issuer, clientID string, users ...User) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{Issuer: issuer, ClientID: clientID, Users: map[string]User{}, key: key, codes: map[string]authCode{}}
+	for _, u := range users {
+		p.AddUser(u)
+	}
+	return p, nil
+}
+
+// AddUser mendaftarkan user; user pertama menjadi default kalau login_hint tidak diisi
+func (p *Provider) AddUser(u User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Users[u.PreferredUsername] = u
+	if p.DefaultUser == "" {
+		p.DefaultUser = u.PreferredUsername
+	}
+}
+
+func (p *Provider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func oauthError(w http.ResponseWriter, status int, code, desc string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": desc})
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.Public().(*rsa.PublicKey)
+	jwk := utils.JWK{Kty: "RSA", Kid: keyID, Use: "sig", Alg: "RS256"}
+	jwk.N = b64(pub.N.Bytes())
+	jwk.E = b64([]byte{1, 0, 1})
+	writeJSON(w, http.StatusOK, utils.JWKSet{Keys: []utils.JWK{jwk}})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != p.ClientID || redirectURI == "" {
+		http.Error(w, "client_id / redirect_uri tidak valid", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "wajib response_type=code dengan PKCE S256", http.StatusBadRequest)
+		return
+	}
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "redirect_uri tidak valid", http.StatusBadRequest)
+		return
+	}
+	ret := back.Query()
+	ret.Set("state", q.Get("state"))
+
+	p.mu.Lock()
+	username := q.Get("login_hint")
+	if username == "" {
+		username = p.DefaultUser
+	}
+	user, ok := p.Users[username]
+	if !ok {
+		p.mu.Unlock()
+		ret.Set("error", "access_denied")
+		back.RawQuery = ret.Encode()
+		http.Redirect(w, r, back.String(), http.StatusFound)
+		return
+	}
+	code, _ := utils.GenerateOpaqueToken()
+	p.codes[code] = authCode{
+		user: user, clientID: p.ClientID, redirectURI: redirectURI, nonce: q.Get("nonce"),
+		challenge: q.Get("code_challenge"), expires: time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	ret.Set("code", code)
+	back.RawQuery = ret.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		oauthError(w, http.StatusMethodNotAllowed, "invalid_request", "POST saja")
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		oauthError(w, http.StatusBadRequest, "unsupported_grant_type", "hanya authorization_code")
+		return
+	}
+	if p.ClientSecret != "" {
+		// client_secret_basic: id & secret di-URL-encode dulu (RFC 6749 2.3.1)
+		id, secret, ok := r.BasicAuth()
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		if !ok || id != p.ClientID || secret != p.ClientSecret {
+			oauthError(w, http.StatusUnauthorized, "invalid_client", "client secret salah")
+			return
+		}
+	}
+
+	p.mu.Lock()
+	code := r.PostForm.Get("code")
+	ac, ok := p.codes[code]
+	delete(p.codes, code) // code hanya sekali pakai
+	p.mu.Unlock()
+
+	switch {
+	case !ok || time.Now().After(ac.expires):
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "code tidak valid")
+		return
+	case r.PostForm.Get("client_id") != ac.clientID || r.PostForm.Get("redirect_uri") != ac.redirectURI:
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "client_id / redirect_uri tidak cocok")
+		return
+	case utils.PKCEChallenge(r.PostForm.Get("code_verifier")) != ac.challenge:
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "code_verifier salah")
+		return
+	}
+
+	now := time.Now()
+	claims := utils.OIDCClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.Issuer,
+			Subject:   ac.user.Subject,
+			Audience:  jwt.ClaimStrings{ac.clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce:             ac.nonce,
+		Email:             ac.user.Email,
+		EmailVerified:     ac.user.EmailVerified,
+		PreferredUsername: ac.user.PreferredUsername,
+		Name:              ac.user.Name,
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = keyID
+	idToken, err := tok.SignedString(p.key)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	accessToken, _ := utils.GenerateOpaqueToken()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
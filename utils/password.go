@@ -1,17 +1,218 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"go_clean/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
 
 var MockCheckPassword func(pw, hash string) bool
 
-func HashPassword(pw string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+// passwordCfg diganti UsePasswordConfig saat startup; test tanpa setup memakai nilai default
+var passwordCfg = config.DefaultPassword()
+
+// UsePasswordConfig dipanggil sekali saat startup dengan hasil config.LoadPassword yang sudah divalidasi
+func UsePasswordConfig(cfg config.PasswordConfig) {
+	passwordCfg = cfg
+}
+
+// PasswordHasher membuat hash untuk password baru. Verifikasi tidak bergantung hasher aktif:
+// CheckPassword mengenali argon2id (format PHC) maupun bcrypt dari prefix hash.
+type PasswordHasher interface {
+	Hash(pw string) (string, error)
+	// NeedsRehash true kalau hash dibuat dengan algoritma / parameter yang sudah tidak dipakai
+	NeedsRehash(hash string) bool
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(pw string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(pw), h.Cost)
 	return string(b), err
 }
 
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher menghasilkan hash format PHC: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+type Argon2idHasher struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var errInvalidArgon2Hash = errors.New("format hash argon2id tidak valid")
+
+type argon2Hash struct {
+	Argon2idHasher
+	salt, key []byte
+}
+
+func (h Argon2idHasher) Hash(pw string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(pw), salt, h.Time, h.Memory, h.Threads, argon2KeyLen)
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Time, h.Threads, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parseArgon2id(hash)
+	return err != nil || parsed.Argon2idHasher != h || len(parsed.key) != argon2KeyLen
+}
+
+func parseArgon2id(hash string) (*argon2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errInvalidArgon2Hash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errInvalidArgon2Hash
+	}
+	var p argon2Hash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return nil, errInvalidArgon2Hash
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, errInvalidArgon2Hash
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.key) == 0 {
+		return nil, errInvalidArgon2Hash
+	}
+	return &p, nil
+}
+
+func checkArgon2id(pw, hash string) bool {
+	p, err := parseArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(pw), p.salt, p.Time, p.Memory, p.Threads, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+// NewPasswordHasher memilih hasher sesuai PASSWORD_HASH_ALGORITHM
+func NewPasswordHasher(cfg config.PasswordConfig) PasswordHasher {
+	if cfg.Algorithm == "bcrypt" {
+		return BcryptHasher{Cost: cfg.BcryptCost}
+	}
+	return Argon2idHasher{Memory: cfg.Argon2Memory, Time: cfg.Argon2Time, Threads: cfg.Argon2Threads}
+}
+
+func HashPassword(pw string) (string, error) {
+	return NewPasswordHasher(passwordCfg).Hash(pw)
+}
+
 func CheckPassword(pw, hash string) bool {
 	if MockCheckPassword != nil {
 		return MockCheckPassword(pw, hash)
 	}
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return checkArgon2id(pw, hash)
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw)) == nil
 }
+
+// PasswordNeedsRehash: hash lama (bcrypt, cost / parameter argon2 lama) perlu diganti saat login berikutnya
+func PasswordNeedsRehash(hash string) bool {
+	return NewPasswordHasher(passwordCfg).NeedsRehash(hash)
+}
+
+// RehashIfNeeded dipanggil setelah login berhasil selagi password plain masih ada.
+// Gagal simpan hanya dicatat, login tetap jalan dan dicoba lagi di login berikutnya.
+func RehashIfNeeded(pw, hash string, save func(newHash string) error) {
+	if MockCheckPassword != nil || !PasswordNeedsRehash(hash) {
+		return
+	}
+	newHash, err := HashPassword(pw)
+	if err == nil {
+		err = save(newHash)
+	}
+	if err != nil {
+		log.Printf("gagal rehash password: %v", err)
+	}
+}
+
+// PasswordPolicy = aturan kekuatan password untuk akun baru / password baru
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+func NewPasswordPolicy(cfg config.PasswordConfig) PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+	}
+}
+
+// Validate mengembalikan error berisi pesan yang bisa langsung ditampilkan ke user
+func (p PasswordPolicy) Validate(pw string) error {
+	if len([]rune(pw)) < p.MinLength {
+		return fmt.Errorf("password minimal %d karakter", p.MinLength)
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "huruf besar")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "huruf kecil")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "angka")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "simbol")
+	}
+	if len(missing) > 0 {
+		return errors.New("password wajib mengandung " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ValidatePassword memakai aturan dari env PASSWORD_MIN_LENGTH / PASSWORD_REQUIRE_* (lewat UsePasswordConfig)
+func ValidatePassword(pw string) error {
+	return NewPasswordPolicy(passwordCfg).Validate(pw)
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestArgon2idHashPHCRoundTrip(t *testing.T) {
+	h := Argon2idHasher{Memory: 1024, Time: 1, Threads: 1}
+	hash, err := h.Hash("rahasia123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected PHC format %q", hash)
+	}
+	if !CheckPassword("rahasia123", hash) || CheckPassword("salah", hash) {
+		t.Error("verifikasi argon2id salah")
+	}
+	if h.NeedsRehash(hash) {
+		t.Error("hash dengan parameter sama tidak perlu rehash")
+	}
+	if !(Argon2idHasher{Memory: 2048, Time: 1, Threads: 1}).NeedsRehash(hash) {
+		t.Error("parameter berubah harus rehash")
+	}
+}
+
+func TestBcryptHashStillVerifiesAndNeedsRehash(t *testing.T) {
+	b, _ := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	legacy := string(b)
+	if !CheckPassword("rahasia123", legacy) {
+		t.Fatal("hash bcrypt lama harus tetap bisa login")
+	}
+	if !PasswordNeedsRehash(legacy) {
+		t.Error("default argon2id: hash bcrypt harus di-rehash")
+	}
+
+	if (BcryptHasher{Cost: bcrypt.MinCost}).NeedsRehash(legacy) {
+		t.Error("bcrypt dengan cost sama tidak perlu rehash")
+	}
+
+	var saved string
+	RehashIfNeeded("rahasia123", legacy, func(h string) error { saved = h; return nil })
+	if !strings.HasPrefix(saved, "$argon2id$") || !CheckPassword("rahasia123", saved) {
+		t.Errorf("rehash tidak menghasilkan argon2id valid: %q", saved)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	p := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+	cases := map[string]bool{
+		"pendek":        false,
+		"panjangsekali": false,
+		"Panjang12345":  false,
+		"Panjang-12345": true,
+	}
+	for pw, ok := range cases {
+		if err := p.Validate(pw); (err == nil) != ok {
+			t.Errorf("%q: expected ok=%v, got %v", pw, ok, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLinkInvalid = errors.New("link tidak valid")
+	ErrLinkExpired = errors.New("link sudah kedaluwarsa")
+)
+
+// SignLink membuat token stateless untuk link di email: payload (purpose, exp, fields)
+// ditandatangani HMAC-SHA256. purpose mencegah token satu fitur dipakai di fitur lain.
+func SignLink(secret []byte, purpose string, exp time.Time, fields ...string) string {
+	parts := append([]string{purpose, strconv.FormatInt(exp.Unix(), 10)}, fields...)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "\n")))
+	return payload + "." + linkSignature(secret, payload)
+}
+
+// VerifyLink mengecek tanda tangan, purpose dan kedaluwarsa lalu mengembalikan fields
+func VerifyLink(secret []byte, purpose, token string, now time.Time) ([]string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(linkSignature(secret, payload))) {
+		return nil, ErrLinkInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrLinkInvalid
+	}
+	parts := strings.Split(string(raw), "\n")
+	if len(parts) < 2 || parts[0] != purpose {
+		return nil, ErrLinkInvalid
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrLinkInvalid
+	}
+	if now.Unix() > exp {
+		return nil, ErrLinkExpired
+	}
+	return parts[2:], nil
+}
+
+func linkSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignedLink(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Now()
+	token := SignLink(secret, "email-verify", now.Add(time.Hour), "12", "budi@mail.com")
+
+	fields, err := VerifyLink(secret, "email-verify", token, now)
+	if err != nil || len(fields) != 2 || fields[0] != "12" || fields[1] != "budi@mail.com" {
+		t.Fatalf("unexpected result %v, %v", fields, err)
+	}
+
+	if _, err := VerifyLink(secret, "password-reset", token, now); err != ErrLinkInvalid {
+		t.Errorf("expected purpose mismatch to be invalid, got %v", err)
+	}
+	if _, err := VerifyLink(secret, "email-verify", token+"x", now); err != ErrLinkInvalid {
+		t.Errorf("expected tampered token to be invalid, got %v", err)
+	}
+	if _, err := VerifyLink(secret, "email-verify", token, now.Add(2*time.Hour)); err != ErrLinkExpired {
+		t.Errorf("expected expired, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// GenerateOpaqueToken membuat token acak (refresh token, dsb) yang aman
+// dikirim lewat URL. Yang disimpan di DB cukup HashToken-nya saja.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan sha256 hex dari token opaque
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyPrefix menandai string sebagai API key (mudah dikenali secret scanner)
+const apiKeyPrefix = "alk_"
+
+// GenerateAPIKey membuat API key berbentuk alk_<prefix>_<secret>. prefix (8 hex) disimpan apa adanya
+// untuk mencari & menampilkan key, yang disimpan dari key utuh hanya HashToken-nya.
+func GenerateAPIKey() (key, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+	return apiKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKey mengambil prefix dari API key; ok false kalau formatnya salah
+func ParseAPIKey(key string) (prefix string, ok bool) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", false
+	}
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(key, apiKeyPrefix), "_")
+	if !found || len(prefix) != 8 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestTOTPRFC6238Vector(t *testing.T) {
+	// secret SHA1 dari RFC 6238 appendix B, 8 digit terakhir "94287082" pada T=59
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	code, err := TOTPCode(secret, TOTPStep(time.Unix(59, 0)))
+	if err != nil || code != "287082" {
+		t.Fatalf("expected 287082, got %v (%v)", code, err)
+	}
+
+	if step, ok := ValidateTOTP(secret, "287082", time.Unix(59+30, 0)); !ok || step != 1 {
+		t.Errorf("expected previous step to be accepted, got %v %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, "287082", time.Unix(59+120, 0)); ok {
+		t.Error("expected old code to be rejected")
+	}
+}
+
+func TestEncryptStringRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	enc, err := EncryptString(key, "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := DecryptString(key, enc); err != nil || plain != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("unexpected %v, %v", plain, err)
+	}
+	key[0] = 1
+	if _, err := DecryptString(key, enc); err == nil {
+		t.Error("expected wrong key to fail")
+	}
+}